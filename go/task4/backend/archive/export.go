@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"archive/zip"
+	"blog/models"
+	"context"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// exportBatchSize 导出时每批读取的文章数
+const exportBatchSize = 100
+
+// Export 把所有文章（不含回收站中的文章）导出为 zip，写入 w
+// 每篇文章一个 posts/<文章ID>.md 文件，包含作者、时间、状态和评论；返回导出的文章数
+func Export(ctx context.Context, db *gorm.DB, w io.Writer) (int, error) {
+	zw := zip.NewWriter(w)
+	count := 0
+	var posts []models.Post
+	result := db.WithContext(ctx).
+		Preload("User").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Comments.User").
+		Order("id ASC").
+		FindInBatches(&posts, exportBatchSize, func(tx *gorm.DB, batch int) error {
+			for i := range posts {
+				if err := writePost(zw, &posts[i]); err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return count, result.Error
+	}
+	return count, zw.Close()
+}
+
+// writePost 把一篇文章写入 zip
+func writePost(zw *zip.Writer, post *models.Post) error {
+	doc := Document{
+		ID:        post.ID,
+		Title:     post.Title,
+		Author:    authorOf(post.User),
+		Status:    post.Status,
+		CreatedAt: post.CreatedAt,
+		UpdatedAt: post.UpdatedAt,
+		Content:   post.Content,
+	}
+	for _, comment := range post.Comments {
+		doc.Comments = append(doc.Comments, Comment{
+			Author:    authorOf(comment.User),
+			Status:    comment.Status,
+			CreatedAt: comment.CreatedAt,
+			Content:   comment.Content,
+		})
+	}
+	data, err := doc.Marshal()
+	if err != nil {
+		return fmt.Errorf("post %d: %w", post.ID, err)
+	}
+	file, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     fmt.Sprintf("posts/%d.md", post.ID),
+		Method:   zip.Deflate,
+		Modified: post.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
+
+// authorOf 返回用户对应的作者信息
+func authorOf(user *models.User) Author {
+	if user == nil {
+		return Author{}
+	}
+	return Author{Name: user.Name, Email: user.Email}
+}
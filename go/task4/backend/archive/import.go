@@ -0,0 +1,312 @@
+package archive
+
+import (
+	"archive/zip"
+	"blog/database"
+	"blog/models"
+	"blog/timeline"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxFileSize 单个 Markdown 文件解压后的最大大小，防止压缩炸弹
+const maxFileSize = 10 << 20
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Posts           int      `json:"posts"`            // 新导入的文章数
+	SkippedPosts    int      `json:"skipped_posts"`    // 已存在而跳过的文章数
+	Comments        int      `json:"comments"`         // 新导入的评论数
+	SkippedComments int      `json:"skipped_comments"` // 已存在而跳过的评论数
+	CreatedUsers    []string `json:"created_users"`    // 找不到对应用户而新建的作者
+}
+
+// Import 导入 Export 生成的 zip（或同样格式的 Markdown 文件）
+// 作者先按邮箱、再按用户名匹配已有用户，都匹配不到时新建用户（随机密码，无法用密码登录）。
+// 导入是幂等的：作者、标题和创建时间（精确到秒）相同的文章视为已存在，评论同理按作者、内容和创建时间判断，
+// 重复导入同一份归档不会产生重复内容；已存在文章下新增的评论仍会导入。所有修改在一个事务中完成，任何错误都会整体回滚
+func Import(ctx context.Context, db *gorm.DB, zr *zip.Reader) (*ImportResult, error) {
+	// 1. 先解析全部文件，格式错误时不修改数据库
+	docs, err := readDocuments(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 按创建时间顺序导入
+	result := &ImportResult{}
+	err = database.Transaction(db.WithContext(ctx), func(tx *gorm.DB) error {
+		authors := &authorResolver{tx: tx, users: make(map[Author]uint), result: result}
+		for _, doc := range docs {
+			if err := importDocument(tx, authors, doc, result); err != nil {
+				return fmt.Errorf("%s: %w", doc.file, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// namedDocument 带文件名的文档，用于错误信息
+type namedDocument struct {
+	*Document
+	file string
+}
+
+// readDocuments 读取并校验 zip 中所有 .md 文件，按创建时间排序
+func readDocuments(zr *zip.Reader) ([]namedDocument, error) {
+	var docs []namedDocument
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(file.Name), ".md") {
+			continue
+		}
+		doc, err := readDocument(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		docs = append(docs, namedDocument{Document: doc, file: file.Name})
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("archive contains no markdown files")
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].CreatedAt.Before(docs[j].CreatedAt)
+	})
+	return docs, nil
+}
+
+// readDocument 读取、解析并校验一个 Markdown 文件，缺省的状态和时间在此补全
+func readDocument(file *zip.File) (*Document, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFileSize {
+		return nil, fmt.Errorf("file larger than %d bytes", maxFileSize)
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	doc.Title = strings.TrimSpace(doc.Title)
+	if doc.Title == "" {
+		return nil, errors.New("title must not be empty")
+	}
+	if strings.TrimSpace(doc.Content) == "" {
+		return nil, errors.New("content must not be empty")
+	}
+	if err := normalize(&doc.Author, &doc.Status, &doc.CreatedAt, now); err != nil {
+		return nil, err
+	}
+	if doc.UpdatedAt.Before(doc.CreatedAt) {
+		doc.UpdatedAt = doc.CreatedAt
+	}
+	for i := range doc.Comments {
+		comment := &doc.Comments[i]
+		if strings.TrimSpace(comment.Content) == "" {
+			return nil, fmt.Errorf("comments[%d]: content must not be empty", i)
+		}
+		if err := normalize(&comment.Author, &comment.Status, &comment.CreatedAt, now); err != nil {
+			return nil, fmt.Errorf("comments[%d]: %w", i, err)
+		}
+	}
+	return doc, nil
+}
+
+// normalize 校验作者和状态，补全缺省的状态和创建时间
+func normalize(author *Author, status *string, createdAt *time.Time, now time.Time) error {
+	author.Name = strings.TrimSpace(author.Name)
+	author.Email = strings.TrimSpace(author.Email)
+	if author.Name == "" && author.Email == "" {
+		return errors.New("author name or email is required")
+	}
+	switch *status {
+	case "":
+		*status = models.StatusPublished
+	case models.StatusPublished, models.StatusHidden, models.StatusPending:
+	default:
+		return fmt.Errorf("unsupported status %q", *status)
+	}
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	return nil
+}
+
+// importDocument 导入一篇文章及其评论，已存在的文章和评论跳过
+func importDocument(tx *gorm.DB, authors *authorResolver, doc namedDocument, result *ImportResult) error {
+	userID, err := authors.resolve(doc.Author)
+	if err != nil {
+		return err
+	}
+	post, err := findPost(tx, userID, doc)
+	if err != nil {
+		return err
+	}
+	if post != nil {
+		result.SkippedPosts++
+	} else {
+		post = &models.Post{
+			Title:   doc.Title,
+			Content: doc.Content,
+			UserID:  userID,
+			Status:  doc.Status,
+		}
+		post.CreatedAt, post.UpdatedAt = doc.CreatedAt, doc.UpdatedAt
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		// 与发布文章相同，写扩散时把导入的文章分发到关注者的收件箱
+		if err := timeline.Current().Published(tx, post); err != nil {
+			return err
+		}
+		result.Posts++
+	}
+
+	for i, item := range doc.Comments {
+		userID, err := authors.resolve(item.Author)
+		if err != nil {
+			return fmt.Errorf("comments[%d]: %w", i, err)
+		}
+		exists, err := commentExists(tx, post.ID, userID, item)
+		if err != nil {
+			return err
+		}
+		if exists {
+			result.SkippedComments++
+			continue
+		}
+		comment := models.Comment{
+			Content: item.Content,
+			UserID:  userID,
+			PostID:  post.ID,
+			Status:  item.Status,
+		}
+		comment.CreatedAt, comment.UpdatedAt = item.CreatedAt, item.CreatedAt
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		result.Comments++
+	}
+	return nil
+}
+
+// findPost 查找已导入的文章（包括回收站中的），不存在时返回 nil
+func findPost(tx *gorm.DB, userID uint, doc namedDocument) (*models.Post, error) {
+	var posts []models.Post
+	if err := tx.Unscoped().Where("user_id = ? AND title = ?", userID, doc.Title).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	for i := range posts {
+		if sameSecond(posts[i].CreatedAt, doc.CreatedAt) {
+			return &posts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// commentExists 判断评论是否已导入（包括回收站中的）
+func commentExists(tx *gorm.DB, postID, userID uint, comment Comment) (bool, error) {
+	var times []time.Time
+	err := tx.Unscoped().Model(&models.Comment{}).
+		Where("post_id = ? AND user_id = ? AND content = ?", postID, userID, comment.Content).
+		Pluck("created_at", &times).Error
+	if err != nil {
+		return false, err
+	}
+	for _, t := range times {
+		if sameSecond(t, comment.CreatedAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sameSecond 判断两个时间是否在同一秒内
+// 在 Go 中比较而不是交给数据库，兼容不同数据库的时间精度和时区（SQLite 按文本比较时间）
+func sameSecond(a, b time.Time) bool {
+	return a.Truncate(time.Second).Equal(b.Truncate(time.Second))
+}
+
+// authorResolver 把归档中的作者映射为用户ID，结果按作者缓存
+type authorResolver struct {
+	tx     *gorm.DB
+	users  map[Author]uint
+	result *ImportResult
+}
+
+// resolve 先按邮箱、再按用户名查找用户，都找不到时新建用户
+func (r *authorResolver) resolve(author Author) (uint, error) {
+	if id, ok := r.users[author]; ok {
+		return id, nil
+	}
+	var user models.User
+	err := gorm.ErrRecordNotFound
+	if author.Email != "" {
+		err = r.tx.Where("email = ?", author.Email).First(&user).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) && author.Name != "" {
+		err = r.tx.Where("name = ?", author.Name).First(&user).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = r.create(author, &user)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("author %s: %w", describe(author), err)
+	}
+	r.users[author] = user.ID
+	return user.ID, nil
+}
+
+// create 新建作者，密码随机生成且不会告知任何人，即无法使用密码登录
+func (r *authorResolver) create(author Author, user *models.User) error {
+	if author.Name == "" || author.Email == "" {
+		return errors.New("no matching user, and both name and email are required to create one")
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	*user = models.User{
+		Name:     author.Name,
+		Email:    author.Email,
+		Password: hex.EncodeToString(secret),
+		Role:     models.RoleUser,
+	}
+	if err := r.tx.Create(user).Error; err != nil {
+		return err
+	}
+	r.result.CreatedUsers = append(r.result.CreatedUsers, describe(author))
+	return nil
+}
+
+// describe 返回作者的可读描述，如 "alice <alice@example.com>"
+func describe(author Author) string {
+	switch {
+	case author.Email == "":
+		return author.Name
+	case author.Name == "":
+		return "<" + author.Email + ">"
+	default:
+		return author.Name + " <" + author.Email + ">"
+	}
+}
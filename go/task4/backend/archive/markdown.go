@@ -0,0 +1,86 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelimiter Front Matter 的起止分隔行
+const frontMatterDelimiter = "---"
+
+// Author 文章或评论的作者，导入时先按邮箱、再按用户名匹配已有用户
+type Author struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email,omitempty"`
+}
+
+// Comment 文章下的一条评论
+type Comment struct {
+	Author    Author    `yaml:"author"`
+	Status    string    `yaml:"status,omitempty"` // published、hidden、pending，为空时视为 published
+	CreatedAt time.Time `yaml:"created_at"`
+	Content   string    `yaml:"content"`
+}
+
+// Document 一篇文章对应的 Markdown 文件：YAML Front Matter 保存元数据和评论，正文为文章内容
+type Document struct {
+	ID        uint      `yaml:"id,omitempty"` // 导出时的文章ID，仅供参考，导入时不使用
+	Title     string    `yaml:"title"`
+	Author    Author    `yaml:"author"`
+	Status    string    `yaml:"status,omitempty"` // published、hidden、pending，为空时视为 published
+	CreatedAt time.Time `yaml:"created_at"`
+	UpdatedAt time.Time `yaml:"updated_at"`
+	Tags      []string  `yaml:"tags,omitempty"` // 博客暂不支持标签，导出时为空，导入时忽略
+	Comments  []Comment `yaml:"comments,omitempty"`
+
+	Content string `yaml:"-"` // 正文
+}
+
+// Marshal 生成 Markdown 文件内容
+func (d *Document) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(d.Content)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// Parse 解析 Markdown 文件，文件必须以 YAML Front Matter 开头
+// 正文开头的空行和末尾的一个换行（Marshal 添加的）会被去除
+func Parse(data []byte) (*Document, error) {
+	text := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+	rest, ok := strings.CutPrefix(text, frontMatterDelimiter+"\n")
+	if !ok {
+		return nil, errors.New("missing front matter")
+	}
+	var frontMatter, content string
+	if after, ok := strings.CutPrefix(rest, frontMatterDelimiter+"\n"); ok {
+		content = after
+	} else if end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n"); end >= 0 {
+		frontMatter, content = rest[:end+1], rest[end+len(frontMatterDelimiter)+2:]
+	} else if strings.HasSuffix(rest, "\n"+frontMatterDelimiter) {
+		frontMatter = strings.TrimSuffix(rest, frontMatterDelimiter)
+	} else {
+		return nil, errors.New("unterminated front matter")
+	}
+
+	var doc Document
+	if err := yaml.Unmarshal([]byte(frontMatter), &doc); err != nil {
+		return nil, err
+	}
+	doc.Content = strings.TrimSuffix(strings.TrimLeft(content, "\n"), "\n")
+	return &doc, nil
+}
@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"blog/database"
+	"blog/models"
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 审计操作类型
+const (
+	ActionRegister         = "auth.register"        // 注册
+	ActionLogin            = "auth.login"           // 登录成功
+	ActionLoginFailed      = "auth.login_failed"    // 登录失败
+	ActionTwoFactorFailed  = "auth.2fa_failed"      // 两步验证的验证码错误
+	ActionTwoFactorEnable  = "auth.2fa_enable"      // 启用两步验证
+	ActionTwoFactorDisable = "auth.2fa_disable"     // 关闭两步验证（用户关闭或命令行重置）
+	ActionRecoveryCodes    = "auth.recovery_codes"  // 重新生成恢复码
+	ActionOIDCLink         = "auth.oidc_link"       // 首次使用 OIDC 登录时关联到已有用户
+	ActionSessionRevoke    = "auth.session_revoke"  // 退出登录会话（远程退出）
+	ActionUnauthorized     = "auth.unauthorized"    // 认证失败（Token 缺失、格式错误或无效）
+	ActionForbidden        = "auth.forbidden"       // 鉴权失败（非作者、角色不足）
+	ActionRoleChange       = "user.role_change"     // 修改用户角色
+	ActionPostCreate       = "post.create"          // 创建文章
+	ActionPostUpdate       = "post.update"          // 更新文章
+	ActionPostDelete       = "post.delete"          // 删除文章（进入回收站）
+	ActionPostRestore      = "post.restore"         // 从回收站恢复文章
+	ActionPostPurge        = "post.purge"           // 彻底删除文章
+	ActionCommentCreate    = "comment.create"       // 创建评论
+	ActionCommentUpdate    = "comment.update"       // 编辑评论
+	ActionUpload           = "attachment.upload"    // 上传附件
+	ActionReportCreate     = "report.create"        // 举报内容
+	ActionModerateHide     = "moderation.hide"      // 版主隐藏内容
+	ActionModerateDelete   = "moderation.delete"    // 版主删除内容
+	ActionModerateDismiss  = "moderation.dismiss"   // 版主驳回举报
+	ActionModerateBan      = "moderation.ban"       // 版主封禁作者
+	ActionAutoHide         = "moderation.auto_hide" // 举报数达到阈值自动隐藏
+	ActionUnban            = "moderation.unban"     // 解除封禁
+	ActionContentExport    = "content.export"       // 导出全部文章
+	ActionContentImport    = "content.import"       // 导入文章
+	ActionWebhookCreate    = "webhook.create"       // 创建事件订阅
+	ActionWebhookDelete    = "webhook.delete"       // 删除事件订阅
+	ActionTokenCreate      = "token.create"         // 创建个人访问令牌
+	ActionTokenDelete      = "token.delete"         // 撤销个人访问令牌
+)
+
+// 审计对象类型
+const (
+	TargetUser       = "user"
+	TargetPost       = "post"
+	TargetComment    = "comment"
+	TargetAttachment = "attachment"
+	TargetWebhook    = "webhook"
+	TargetToken      = "token"
+	TargetSession    = "session"
+)
+
+// Entry 一条待写入的审计记录
+type Entry struct {
+	ActorID    uint        // 操作者ID，0 表示匿名
+	ActorName  string      // 操作者用户名
+	Action     string      // 操作类型
+	TargetType string      // 操作对象类型
+	TargetID   uint        // 操作对象ID，0 表示无
+	Detail     string      // 补充说明
+	Before     interface{} // 操作前的快照，序列化为 JSON
+	After      interface{} // 操作后的快照，序列化为 JSON
+}
+
+// Client 发起操作的客户端信息
+type Client struct {
+	IP        string // 客户端 IP
+	UserAgent string // 客户端 User-Agent
+	Resource  string // 请求的接口，如 "PUT /api/posts/1" 或 gRPC 方法名
+}
+
+// clientKey context 中客户端信息的键
+type clientKey struct{}
+
+// WithClient 在 ctx 中记录客户端信息，供 RecordContext 填充审计记录
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFrom 返回 ctx 中的客户端信息，未设置时返回零值
+func ClientFrom(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// RequestContext 返回携带客户端信息的请求 context，用于调用 service 等与 HTTP 无关的业务逻辑
+func RequestContext(c *gin.Context) context.Context {
+	return WithClient(c.Request.Context(), Client{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Resource:  c.Request.Method + " " + c.Request.URL.Path,
+	})
+}
+
+// Record 记录一次请求中的操作
+// 自动填充客户端 IP 和 User-Agent；写入失败只打印日志，不影响请求本身
+func Record(c *gin.Context, entry Entry) {
+	RecordContext(RequestContext(c), entry)
+}
+
+// RecordContext 记录一次操作，客户端信息取自 WithClient
+// 写入失败只打印日志，不影响操作本身
+func RecordContext(ctx context.Context, entry Entry) {
+	// 请求结束后 context 会被取消，审计记录仍需写入
+	ctx = context.WithoutCancel(ctx)
+	client := ClientFrom(ctx)
+	if err := Write(ctx, entry, client.IP, client.UserAgent); err != nil {
+		log.Println("Blog audit error: ", err)
+	}
+}
+
+// Write 写入一条审计记录
+// 供命令行等没有 HTTP 请求的场景直接使用
+func Write(ctx context.Context, entry Entry, ip, userAgent string) error {
+	record := models.AuditLog{
+		ActorName:  entry.ActorName,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		IP:         ip,
+		UserAgent:  truncate(userAgent, 255),
+		Detail:     truncate(entry.Detail, 255),
+	}
+	if entry.ActorID != 0 {
+		record.ActorID = &entry.ActorID
+	}
+	if entry.TargetID != 0 {
+		record.TargetID = &entry.TargetID
+	}
+	var err error
+	if record.Before, err = snapshot(entry.Before); err != nil {
+		return err
+	}
+	if record.After, err = snapshot(entry.After); err != nil {
+		return err
+	}
+	return database.DB.WithContext(ctx).Create(&record).Error
+}
+
+// snapshot 将快照序列化为 JSON 文本，nil 返回空
+func snapshot(v interface{}) (models.JSONText, error) {
+	if v == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return models.JSONText(data), nil
+}
+
+// truncate 按字符截断字符串，避免超出列长度
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
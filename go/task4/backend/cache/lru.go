@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry 缓存条目
+type entry[V any] struct {
+	key       string
+	value     V
+	tags      []string  // 条目依赖的数据标签，任一标签失效时条目被删除
+	expiresAt time.Time // 过期时间
+}
+
+// LRU 带 TTL 和标签失效的 LRU 缓存，并发安全
+type LRU[V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	ll         *list.List               // 最近使用的在前
+	items      map[string]*list.Element // key -> 链表节点
+	tagIndex   map[string]map[string]struct{}
+	now        func() time.Time
+}
+
+// NewLRU 创建 LRU 缓存
+// maxEntries 为最大条目数，ttl 为条目存活时间
+func NewLRU[V any](maxEntries int, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		tagIndex:   make(map[string]map[string]struct{}),
+		now:        time.Now,
+	}
+}
+
+// Get 获取缓存值，过期的条目会被删除并视为未命中
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[V])
+	if c.now().After(e.expiresAt) {
+		c.removeElement(el)
+		return zero, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// Set 写入缓存，超过容量时淘汰最久未使用的条目
+func (c *LRU[V]) Set(key string, value V, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	e := &entry[V]{key: key, value: value, tags: tags, expiresAt: c.now().Add(c.ttl)}
+	c.items[key] = c.ll.PushFront(e)
+	for _, tag := range tags {
+		if c.tagIndex[tag] == nil {
+			c.tagIndex[tag] = make(map[string]struct{})
+		}
+		c.tagIndex[tag][key] = struct{}{}
+	}
+
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Delete 删除指定的条目
+func (c *LRU[V]) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+		}
+	}
+}
+
+// InvalidateTags 删除依赖任一标签的所有条目
+func (c *LRU[V]) InvalidateTags(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tagIndex[tag] {
+			if el, ok := c.items[key]; ok {
+				c.removeElement(el)
+			}
+		}
+		delete(c.tagIndex, tag)
+	}
+}
+
+// Len 当前条目数
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// removeElement 删除条目并维护标签索引，调用方需持有锁
+func (c *LRU[V]) removeElement(el *list.Element) {
+	e := el.Value.(*entry[V])
+	c.ll.Remove(el)
+	delete(c.items, e.key)
+	for _, tag := range e.tags {
+		if keys := c.tagIndex[tag]; keys != nil {
+			delete(keys, e.key)
+			if len(keys) == 0 {
+				delete(c.tagIndex, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"blog/config"
+	"blog/models"
+	"sync"
+)
+
+// 缓存标签：与数据表一一对应，表发生写操作时使依赖它的响应失效
+const (
+	TagPost    = "post"
+	TagComment = "comment"
+	TagUser    = "user"
+)
+
+// Response 缓存的 HTTP 响应
+type Response struct {
+	Status       int
+	ContentType  string
+	ETag         string
+	LastModified string // 处理函数设置的 Last-Modified 响应头，可为空
+	Body         []byte
+}
+
+// Responses 公开接口的响应缓存，未启用时为 nil
+var Responses *LRU[Response]
+
+var (
+	generationMu sync.RWMutex
+	generation   uint64 // 每次失效加一，用于丢弃与失效重叠的请求的写入
+)
+
+// Init 根据配置初始化响应缓存
+func Init(cfg *config.CacheConfig) {
+	if !cfg.Enabled {
+		Responses = nil
+		return
+	}
+	Responses = NewLRU[Response](cfg.MaxEntries, cfg.TTL)
+}
+
+// Invalidate 使依赖指定标签的缓存响应失效，并推进缓存代数
+func Invalidate(tags ...string) {
+	generationMu.Lock()
+	defer generationMu.Unlock()
+
+	generation++
+	if Responses != nil {
+		Responses.InvalidateTags(tags...)
+	}
+}
+
+// Generation 返回当前缓存代数，请求处理前获取，写入缓存时传给 Store
+func Generation() uint64 {
+	generationMu.RLock()
+	defer generationMu.RUnlock()
+	return generation
+}
+
+// Store 写入缓存响应
+// 处理请求期间发生过失效（代数已变化）时放弃写入：请求可能读到了失效前或落后的只读副本上的旧数据
+func Store(key string, resp Response, gen uint64, tags ...string) bool {
+	generationMu.RLock()
+	defer generationMu.RUnlock()
+
+	if Responses == nil || gen != generation {
+		return false
+	}
+	Responses.Set(key, resp, tags...)
+	return true
+}
+
+// TagForTable 返回数据表对应的缓存标签，不参与缓存的表返回空字符串
+func TagForTable(table string) string {
+	switch table {
+	case (&models.Post{}).TableName(), (&models.Attachment{}).TableName():
+		// 附件随文章详情返回
+		return TagPost
+	case (&models.Comment{}).TableName():
+		return TagComment
+	case (&models.User{}).TableName():
+		return TagUser
+	default:
+		return ""
+	}
+}
@@ -1,12 +1,9 @@
 package config
 
 import (
-	"os"
-	"strconv"
+	"log"
 	"sync"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
 var (
@@ -14,80 +11,411 @@ var (
 	globalConfig Config
 )
 
+// redactedValue 打印配置时替换敏感字段的占位符
+const redactedValue = "******"
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Type     string // 数据库类型: "mysql" 或 "sqlite"
-	Host     string // 数据库主机地址（MySQL）
-	Port     string // 数据库端口（MySQL）
-	User     string // 数据库用户名（MySQL）
-	Password string // 数据库密码（MySQL）
-	Name     string // 数据库名称（MySQL）或 SQLite 文件路径
+	Type            string        `yaml:"type"`               // 数据库类型: "mysql"、"postgres" 或 "sqlite"
+	Host            string        `yaml:"host"`               // 数据库主机地址（MySQL/PostgreSQL）
+	Port            string        `yaml:"port"`               // 数据库端口，为空时使用默认端口（MySQL 3306，PostgreSQL 5432）
+	User            string        `yaml:"user"`               // 数据库用户名（MySQL/PostgreSQL）
+	Password        string        `yaml:"password"`           // 数据库密码（MySQL/PostgreSQL）
+	Name            string        `yaml:"name"`               // 数据库名称（MySQL/PostgreSQL）或 SQLite 文件路径
+	SSLMode         string        `yaml:"ssl_mode"`           // SSL 模式: "disable"、"require"、"verify-ca"、"verify-full"
+	SSLRootCert     string        `yaml:"ssl_root_cert"`      // 校验服务端证书使用的 CA 证书路径
+	LogLevel        string        `yaml:"log_level"`          // SQL 日志级别: "silent"、"error"、"warn"、"info"
+	MaxOpenConns    int           `yaml:"max_open_conns"`     // 最大打开连接数，0 表示不限制
+	MaxIdleConns    int           `yaml:"max_idle_conns"`     // 最大空闲连接数
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`  // 连接最长存活时间，0 表示不限制
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"` // 连接最长空闲时间，0 表示不限制
+
+	Replicas             []ReplicaConfig `yaml:"replicas"`                // 只读副本，列表/详情等公开读接口从副本读取
+	ReadAfterWriteWindow time.Duration   `yaml:"read_after_write_window"` // 客户端写入后在该时间内的读请求仍走主库
+}
+
+// ReplicaConfig 只读副本配置
+// 用户名、密码、SSL 等其余连接参数与主库一致
+type ReplicaConfig struct {
+	Host string `yaml:"host"` // 副本主机地址（MySQL/PostgreSQL）
+	Port string `yaml:"port"` // 副本端口，为空时使用默认端口
+	Name string `yaml:"name"` // 数据库名称或 SQLite 文件路径，为空时与主库相同
 }
 
 // JWTConfig JWT 配置
 type JWTConfig struct {
-	Secret     string        // JWT 密钥
-	ExpireTime time.Duration // Token 过期时间
+	Secret     string        `yaml:"secret"`      // JWT 密钥
+	ExpireTime time.Duration `yaml:"expire_time"` // Token 过期时间
+}
+
+// SessionConfig 登录会话配置
+// 每次登录创建一个会话，用户可以查看并远程退出；会话状态和用户的认证状态缓存在进程内，避免每个请求都查询数据库
+type SessionConfig struct {
+	CacheTTL        time.Duration `yaml:"cache_ttl"`         // 会话和用户认证状态的缓存时间；多实例部署时，在其他实例上退出的会话、封禁的用户最多经过该时间后生效
+	CacheMaxEntries int           `yaml:"cache_max_entries"` // 会话和用户各自最多缓存的条目数
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer        string        `yaml:"issuer"`         // 身份验证器应用中显示的发行方名称
+	ChallengeTTL  time.Duration `yaml:"challenge_ttl"`  // 登录时密码验证通过后，提交验证码的有效时间
+	RequiredRoles []string      `yaml:"required_roles"` // 必须启用两步验证的角色（moderator、admin），未启用时不能使用该角色的权限
+}
+
+// OIDCConfig OpenID Connect 登录配置
+// 使用授权码流程和 PKCE，授权、令牌、JWKS 等端点从发现文档读取，兼容任何标准的 OIDC 提供方
+type OIDCConfig struct {
+	Enabled          bool          `yaml:"enabled"`           // 是否启用 OIDC 登录
+	Name             string        `yaml:"name"`              // 提供方名称，显示在登录按钮上
+	DiscoveryURL     string        `yaml:"discovery_url"`     // 发现文档地址，也可以只填 issuer（自动补上 /.well-known/openid-configuration）
+	ClientID         string        `yaml:"client_id"`         // 在提供方注册的客户端ID
+	ClientSecret     string        `yaml:"client_secret"`     // 客户端密钥，公开客户端（只使用 PKCE）留空
+	RedirectURL      string        `yaml:"redirect_url"`      // 回调地址，需在提供方登记，如 https://blog.example.com/api/auth/oidc/callback
+	Scopes           []string      `yaml:"scopes"`            // 申请的权限范围，必须包含 openid；需要 email 才能关联和创建用户
+	AllowSignup      bool          `yaml:"allow_signup"`      // 没有对应用户时是否自动创建（JIT）
+	FrontendRedirect string        `yaml:"frontend_redirect"` // 登录完成后跳转的前端页面，结果放在 URL 片段（#token=…）中
+	StateTTL         time.Duration `yaml:"state_ttl"`         // 跳转到提供方后完成登录的有效时间
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host string // 服务器监听地址
-	Port string // 服务器端口
+	Host string `yaml:"host"` // 服务器监听地址
+	Port string `yaml:"port"` // 服务器端口
+}
+
+// FrontendConfig 前端静态资源配置
+// 默认使用编译时嵌入的前端资源；开发时设置 dir 直接读取磁盘上的文件，修改后刷新即可生效
+type FrontendConfig struct {
+	Dir         string        `yaml:"dir"`           // 前端目录（如 ../frontend），为空时使用嵌入的资源
+	AssetMaxAge time.Duration `yaml:"asset_max_age"` // CSS、JS 等资源的浏览器缓存时间，HTML 页面始终重新校验
+}
+
+// GRPCConfig gRPC 服务配置
+// gRPC 服务与 HTTP 服务共用业务逻辑，监听单独的端口
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否启动 gRPC 服务
+	Host    string `yaml:"host"`    // 监听地址，为空时与 server.host 相同
+	Port    string `yaml:"port"`    // 监听端口，不能与 server.port 相同
+}
+
+// GraphQLConfig GraphQL 接口配置
+// 超过深度或复杂度限制的查询在执行前被拒绝，0 表示不限制
+type GraphQLConfig struct {
+	MaxDepth      int `yaml:"max_depth"`      // 查询的最大嵌套深度
+	MaxComplexity int `yaml:"max_complexity"` // 查询的最大复杂度（每个字段计 1，列表字段按返回条数放大）
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowedOrigins   []string          `yaml:"allowed_origins"`   // 允许的来源，支持精确匹配、"https://*.example.com" 子域名通配和 "*"
+	AllowedMethods   []string          `yaml:"allowed_methods"`   // 允许的请求方法
+	AllowedHeaders   []string          `yaml:"allowed_headers"`   // 允许的请求头
+	ExposedHeaders   []string          `yaml:"exposed_headers"`   // 允许前端读取的响应头
+	AllowCredentials bool              `yaml:"allow_credentials"` // 是否允许携带 Cookie 等凭证（不能与 "*" 同时使用）
+	MaxAge           time.Duration     `yaml:"max_age"`           // 预检结果缓存时间
+	Routes           []CORSRouteConfig `yaml:"routes"`            // 按路径前缀覆盖的策略，最长前缀优先
+}
+
+// CORSRouteConfig 单个路径前缀的跨域策略覆盖
+// 未设置的字段沿用全局策略
+type CORSRouteConfig struct {
+	PathPrefix       string   `yaml:"path_prefix"`       // 路径前缀，如 "/api/auth"
+	AllowedOrigins   []string `yaml:"allowed_origins"`   // 允许的来源
+	AllowedMethods   []string `yaml:"allowed_methods"`   // 允许的请求方法
+	AllowCredentials *bool    `yaml:"allow_credentials"` // 是否允许携带凭证
+}
+
+// CacheConfig 公开接口响应缓存配置
+type CacheConfig struct {
+	Enabled              bool          `yaml:"enabled"`                // 是否启用进程内响应缓存（ETag 与 304 始终启用）
+	MaxEntries           int           `yaml:"max_entries"`            // 最大缓存条目数
+	TTL                  time.Duration `yaml:"ttl"`                    // 缓存条目存活时间
+	MaxAge               time.Duration `yaml:"max_age"`                // Cache-Control 中的 max-age，供浏览器和 CDN 使用
+	StaleWhileRevalidate time.Duration `yaml:"stale_while_revalidate"` // Cache-Control 中的 stale-while-revalidate
+}
+
+// FeedConfig 订阅源（RSS、Atom、JSON Feed）和站点地图配置
+type FeedConfig struct {
+	Title       string `yaml:"title"`       // 站点标题
+	Description string `yaml:"description"` // 站点描述
+	BaseURL     string `yaml:"base_url"`    // 站点对外访问地址，用于生成绝对链接；为空时使用 http://server.host:server.port
+	MaxItems    int    `yaml:"max_items"`   // 订阅源包含的最新文章数
+}
+
+// TimelineConfig 首页动态（关注的作者发布的文章）配置
+// 读扩散在读取时查询关注的作者的文章，不需要额外存储；写扩散在发布时把文章写入每个粉丝的收件箱，读取只查收件箱，
+// 适合关注数多、读多写少的场景。从 read 切换到 write 前需执行 `blog timeline rebuild` 生成收件箱
+type TimelineConfig struct {
+	Strategy string `yaml:"strategy"` // 分发策略：read（读扩散）、write（写扩散）
+	Backfill int    `yaml:"backfill"` // 写扩散时，关注作者后（以及重建收件箱时）写入收件箱的该作者最近文章数
+}
+
+// UploadConfig 文件上传配置
+// 文件按内容的 SHA-256 命名（内容寻址），相同内容只存储一份
+type UploadConfig struct {
+	MaxSize        int      `yaml:"max_size"`         // 单个文件的最大字节数
+	AllowedTypes   []string `yaml:"allowed_types"`    // 允许的 MIME 类型（根据文件内容识别，不信任客户端声明的类型）
+	MaxImageWidth  int      `yaml:"max_image_width"`  // 图片的最大宽度（像素）
+	MaxImageHeight int      `yaml:"max_image_height"` // 图片的最大高度（像素）
+	ThumbnailSize  int      `yaml:"thumbnail_size"`   // 缩略图的最大边长（像素）
+	Storage        string   `yaml:"storage"`          // 存储后端: "local" 或 "s3"
+	LocalDir       string   `yaml:"local_dir"`        // 本地存储目录
+	S3             S3Config `yaml:"s3"`               // S3 兼容存储配置
+}
+
+// S3Config S3 兼容对象存储配置（AWS S3、MinIO 等），使用路径风格访问 endpoint/bucket/key
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`   // 服务地址，如 https://s3.us-east-1.amazonaws.com 或 http://localhost:9000
+	Region    string `yaml:"region"`     // 区域，用于签名
+	Bucket    string `yaml:"bucket"`     // 存储桶
+	AccessKey string `yaml:"access_key"` // 访问密钥ID
+	SecretKey string `yaml:"secret_key"` // 访问密钥
+}
+
+// WebhookConfig 事件通知（Webhook）配置
+// 投递失败（连接错误或非 2xx 响应）时按指数退避重试：initial_backoff、2×initial_backoff……最长 max_backoff
+type WebhookConfig struct {
+	Enabled        bool          `yaml:"enabled"`         // 是否启用事件通知，关闭时不产生投递记录
+	Timeout        time.Duration `yaml:"timeout"`         // 单次投递的请求超时时间
+	MaxAttempts    int           `yaml:"max_attempts"`    // 最多投递次数（含首次），之后标记为失败
+	InitialBackoff time.Duration `yaml:"initial_backoff"` // 首次重试的等待时间，之后每次翻倍
+	MaxBackoff     time.Duration `yaml:"max_backoff"`     // 重试等待时间的上限
+	PollInterval   time.Duration `yaml:"poll_interval"`   // 后台任务检查待投递记录的间隔（新事件会立即唤醒）
+}
+
+// IdempotencyConfig 幂等键配置
+// 写请求携带 Idempotency-Key 请求头时，同一用户相同键的首次响应在 ttl 内保存，重试时直接返回保存的响应
+type IdempotencyConfig struct {
+	Enabled bool          `yaml:"enabled"` // 是否支持 Idempotency-Key，关闭时忽略该请求头
+	TTL     time.Duration `yaml:"ttl"`     // 响应的保存时间，过期后相同的键视为新请求
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention"`      // 软删除内容的保留时间，超过后被彻底删除，0 表示永久保留
+	PurgeInterval time.Duration `yaml:"purge_interval"` // 后台清理任务的执行间隔
+}
+
+// ModerationConfig 内容审核配置
+type ModerationConfig struct {
+	ReportThreshold int `yaml:"report_threshold"` // 同一内容被不同用户举报达到该次数时自动隐藏，0 表示不自动隐藏
+}
+
+// FilterConfig 垃圾内容过滤配置
+// 命中任一规则的文章和评论进入待审核状态，而不是直接拒绝
+type FilterConfig struct {
+	Enabled              bool          `yaml:"enabled"`                  // 是否启用过滤
+	MaxPostLinks         int           `yaml:"max_post_links"`           // 文章允许的最大链接数
+	MaxCommentLinks      int           `yaml:"max_comment_links"`        // 评论允许的最大链接数
+	BlockedWords         []string      `yaml:"blocked_words"`            // 屏蔽词（不区分大小写）
+	DuplicateWindow      time.Duration `yaml:"duplicate_window"`         // 该时间内出现相同内容视为重复，0 表示不检测
+	NewAccountAge        time.Duration `yaml:"new_account_age"`          // 注册不满该时长的账号视为新账号，0 表示不限制
+	NewAccountMaxPerHour int           `yaml:"new_account_max_per_hour"` // 新账号每小时最多发布的文章和评论数
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level string `yaml:"level"` // 应用日志级别: "debug"、"info"、"warn"、"error"
+}
+
+// TracingConfig 链路追踪配置（OpenTelemetry）
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled"`      // 是否启用链路追踪
+	Exporter    string  `yaml:"exporter"`     // 导出器类型: "otlp" 或 "stdout"
+	Endpoint    string  `yaml:"endpoint"`     // OTLP HTTP 接收地址（如 localhost:4318）
+	Insecure    bool    `yaml:"insecure"`     // OTLP 是否使用 HTTP 明文传输（本地 Collector 一般为 true）
+	ServiceName string  `yaml:"service_name"` // 上报的服务名称
+	SampleRatio float64 `yaml:"sample_ratio"` // 采样比例，范围 0~1
 }
 
 // Config 配置结构体
 // 包含数据库连接信息、JWT密钥、服务器端口等配置
 type Config struct {
-	Database DatabaseConfig // 数据库配置
-	JWT      JWTConfig      // JWT 配置
-	Server   ServerConfig   // 服务器配置
+	Database    DatabaseConfig    `yaml:"database"`    // 数据库配置
+	JWT         JWTConfig         `yaml:"jwt"`         // JWT 配置
+	Session     SessionConfig     `yaml:"session"`     // 登录会话配置
+	TwoFactor   TwoFactorConfig   `yaml:"two_factor"`  // 两步验证配置
+	OIDC        OIDCConfig        `yaml:"oidc"`        // OpenID Connect 登录配置
+	Server      ServerConfig      `yaml:"server"`      // 服务器配置
+	Frontend    FrontendConfig    `yaml:"frontend"`    // 前端静态资源配置
+	GRPC        GRPCConfig        `yaml:"grpc"`        // gRPC 服务配置
+	GraphQL     GraphQLConfig     `yaml:"graphql"`     // GraphQL 接口配置
+	CORS        CORSConfig        `yaml:"cors"`        // 跨域配置
+	Cache       CacheConfig       `yaml:"cache"`       // 响应缓存配置
+	Feed        FeedConfig        `yaml:"feed"`        // 订阅源和站点地图配置
+	Timeline    TimelineConfig    `yaml:"timeline"`    // 首页动态配置
+	Upload      UploadConfig      `yaml:"upload"`      // 文件上传配置
+	Webhook     WebhookConfig     `yaml:"webhook"`     // 事件通知配置
+	Idempotency IdempotencyConfig `yaml:"idempotency"` // 幂等键配置
+	Trash       TrashConfig       `yaml:"trash"`       // 回收站配置
+	Moderation  ModerationConfig  `yaml:"moderation"`  // 内容审核配置
+	Filter      FilterConfig      `yaml:"filter"`      // 垃圾内容过滤配置
+	Log         LogConfig         `yaml:"log"`         // 日志配置
+	Tracing     TracingConfig     `yaml:"tracing"`     // 链路追踪配置
 }
 
-// LoadConfig 加载配置
-// 从环境变量或 .env 文件加载配置信息，如果环境变量不存在则使用默认值
-func LoadConfig() Config {
-	once.Do(func() {
-		// 尝试加载 .env 文件（如果存在）
-		_ = godotenv.Load(".env")
-
-		// 加载数据库配置
-		database := DatabaseConfig{
-			Type:     getEnv("DB_TYPE", "sqlite"),    // 默认使用 SQLite
-			Host:     getEnv("DB_HOST", "localhost"), // MySQL 主机
-			Port:     getEnv("DB_PORT", "3306"),      // MySQL 端口
-			User:     getEnv("DB_USER", "root"),      // MySQL 用户名
-			Password: getEnv("DB_PASSWORD", ""),      // MySQL 密码
-			Name:     getEnv("DB_NAME", "blog.db"),   // SQLite 文件路径或 MySQL 数据库名
-		}
+// Default 返回默认配置
+// 所有配置层（配置文件、环境变量、命令行参数）都在此基础上覆盖
+func Default() Config {
+	return Config{
+		Database: DatabaseConfig{
+			Type:    "sqlite",    // 默认使用 SQLite
+			Host:    "localhost", // MySQL/PostgreSQL 主机
+			User:    "root",      // MySQL/PostgreSQL 用户名
+			Name:    "blog.db",   // SQLite 文件路径或 MySQL/PostgreSQL 数据库名
+			SSLMode: "disable",
 
-		// 加载 JWT 配置
-		expireHours, _ := strconv.Atoi(getEnv("JWT_EXPIRE_HOURS", "24")) // 默认 24 小时
-		jwt := JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "secret"),         // JWT 密钥
-			ExpireTime: time.Duration(expireHours) * time.Hour, // Token 过期时间
-		}
+			ReadAfterWriteWindow: 5 * time.Second,
+			LogLevel:             "info",
+			MaxIdleConns:         2, // 与 database/sql 的默认值一致
+		},
+		JWT: JWTConfig{
+			Secret:     "secret",       // JWT 密钥
+			ExpireTime: 24 * time.Hour, // 默认 24 小时
+		},
+		Session: SessionConfig{
+			CacheTTL:        30 * time.Second,
+			CacheMaxEntries: 10000,
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:       "Blog",
+			ChallengeTTL: 5 * time.Minute,
+		},
+		OIDC: OIDCConfig{
+			Name:             "OpenID Connect",
+			Scopes:           []string{"openid", "email", "profile"},
+			AllowSignup:      true,
+			FrontendRedirect: "/pages/login.html",
+			StateTTL:         10 * time.Minute,
+		},
+		Server: ServerConfig{
+			Host: "localhost", // 默认仅监听本机
+			Port: "8080",      // 默认端口 8080
+		},
+		Frontend: FrontendConfig{
+			AssetMaxAge: time.Hour,
+		},
+		GRPC: GRPCConfig{
+			Port: "9090",
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      7,
+			MaxComplexity: 1000,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Origin", "X-Requested-With", "Content-Type", "Accept", "Authorization",
+				"traceparent", "tracestate", "Idempotency-Key", "If-Match", "If-None-Match"},
+			ExposedHeaders: []string{"Idempotent-Replayed", "ETag"},
+			MaxAge:         24 * time.Hour,
+		},
+		Cache: CacheConfig{
+			Enabled:              true,
+			MaxEntries:           1000,
+			TTL:                  time.Minute,
+			MaxAge:               30 * time.Second,
+			StaleWhileRevalidate: time.Minute,
+		},
+		Feed: FeedConfig{
+			Title:       "个人博客",
+			Description: "个人博客的最新文章",
+			MaxItems:    20,
+		},
+		Timeline: TimelineConfig{
+			Strategy: "read",
+			Backfill: 20,
+		},
+		Upload: UploadConfig{
+			MaxSize:        10 << 20, // 10 MB
+			AllowedTypes:   []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf", "text/plain"},
+			MaxImageWidth:  8192,
+			MaxImageHeight: 8192,
+			ThumbnailSize:  320,
+			Storage:        "local",
+			LocalDir:       "uploads",
+			S3: S3Config{
+				Region: "us-east-1",
+			},
+		},
+		Webhook: WebhookConfig{
+			Enabled:        true,
+			Timeout:        10 * time.Second,
+			MaxAttempts:    8,
+			InitialBackoff: 30 * time.Second,
+			MaxBackoff:     time.Hour,
+			PollInterval:   5 * time.Second,
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled: true,
+			TTL:     24 * time.Hour,
+		},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour, // 默认保留 30 天
+			PurgeInterval: time.Hour,
+		},
+		Moderation: ModerationConfig{
+			ReportThreshold: 3,
+		},
+		Filter: FilterConfig{
+			Enabled:              true,
+			MaxPostLinks:         10,
+			MaxCommentLinks:      2,
+			DuplicateWindow:      10 * time.Minute,
+			NewAccountAge:        24 * time.Hour,
+			NewAccountMaxPerHour: 5,
+		},
+		Log: LogConfig{
+			Level: "debug",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "stdout",         // 默认输出到标准输出
+			Endpoint:    "localhost:4318", // 本地 Collector 默认 OTLP/HTTP 端口
+			Insecure:    true,
+			ServiceName: "blog-service",
+			SampleRatio: 1,
+		},
+	}
+}
 
-		// 加载服务器配置
-		server := ServerConfig{
-			Host: getEnv("SERVER_HOST", "localhost"), // 默认监听所有接口
-			Port: getEnv("SERVER_PORT", "8080"),      // 默认端口 8080
-		}
+// Init 初始化全局配置
+// 按「默认值 → 配置文件 → 环境变量 → 命令行参数」的顺序逐层加载并校验，args 为命令行参数（不含程序名）
+func Init(args []string) (Config, error) {
+	var err error
+	once.Do(func() {
+		globalConfig, err = Load(args)
+	})
+	return globalConfig, err
+}
 
-		globalConfig = Config{
-			Database: database,
-			JWT:      jwt,
-			Server:   server,
+// LoadConfig 获取全局配置
+// 如果尚未调用 Init，则只使用默认值、配置文件和环境变量加载；配置无效时直接退出
+func LoadConfig() Config {
+	once.Do(func() {
+		cfg, err := Load(nil)
+		if err != nil {
+			log.Fatal("Blog config error: ", err)
 		}
+		globalConfig = cfg
 	})
 
 	return globalConfig
 }
 
-// getEnv 获取环境变量，如果不存在则返回默认值
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// Redacted 返回隐藏敏感字段后的配置副本，用于打印或日志输出
+func (c Config) Redacted() Config {
+	redacted := c
+	if redacted.Database.Password != "" {
+		redacted.Database.Password = redactedValue
+	}
+	if redacted.JWT.Secret != "" {
+		redacted.JWT.Secret = redactedValue
+	}
+	if redacted.OIDC.ClientSecret != "" {
+		redacted.OIDC.ClientSecret = redactedValue
+	}
+	if redacted.Upload.S3.SecretKey != "" {
+		redacted.Upload.S3.SecretKey = redactedValue
 	}
-	return defaultValue
+	return redacted
 }
@@ -0,0 +1,406 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFiles 未指定配置文件时按顺序查找的默认文件
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// flagValues 命令行参数，只有显式传入的参数才会覆盖配置
+type flagValues struct {
+	configFile  string
+	host        string
+	port        string
+	dbType      string
+	dbName      string
+	logLevel    string
+	frontendDir string
+}
+
+// Load 加载配置
+// 按「默认值 → 配置文件 → 环境变量 → 命令行参数」的顺序逐层覆盖，最后进行校验
+func Load(args []string) (Config, error) {
+	// 1. 解析命令行参数（先解析，以便获取 -config 指定的配置文件）
+	flags, visited, err := parseFlags(args)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// 尝试加载 .env 文件（如果存在）
+	_ = godotenv.Load(".env")
+
+	cfg := Default()
+
+	// 2. 配置文件
+	path := flags.configFile
+	if path == "" {
+		path = os.Getenv("BLOG_CONFIG")
+	}
+	if path == "" {
+		path = findDefaultConfigFile()
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	// 3. 环境变量
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	// 4. 命令行参数
+	applyFlags(&cfg, flags, visited)
+
+	// 5. 校验
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// parseFlags 解析命令行参数，返回参数值和显式设置过的参数名
+func parseFlags(args []string) (flagValues, map[string]bool, error) {
+	var v flagValues
+	fs := flag.NewFlagSet("blog", flag.ContinueOnError)
+	fs.StringVar(&v.configFile, "config", "", "配置文件路径（.yaml/.yml/.toml），也可通过 BLOG_CONFIG 指定")
+	fs.StringVar(&v.host, "host", "", "服务器监听地址")
+	fs.StringVar(&v.port, "port", "", "服务器端口")
+	fs.StringVar(&v.dbType, "db-type", "", "数据库类型: sqlite、mysql 或 postgres")
+	fs.StringVar(&v.dbName, "db-name", "", "数据库名称或 SQLite 文件路径")
+	fs.StringVar(&v.logLevel, "log-level", "", "应用日志级别: debug、info、warn、error")
+	fs.StringVar(&v.frontendDir, "frontend-dir", "", "从磁盘读取前端资源的目录（开发模式），为空时使用嵌入的资源")
+	if err := fs.Parse(args); err != nil {
+		return v, nil, err
+	}
+	if fs.NArg() > 0 {
+		return v, nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	visited := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { visited[f.Name] = true })
+	return v, visited, nil
+}
+
+// findDefaultConfigFile 在当前目录查找默认配置文件，找不到时返回空字符串
+func findDefaultConfigFile() string {
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// loadFile 读取配置文件并覆盖到 cfg 上
+// 未知字段会被视为错误，避免拼写错误的配置项被静默忽略
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		// TOML 先解析为通用结构，再按 YAML 规则解码，保证两种格式的字段语义（如时长 "24h"）一致
+		var raw map[string]interface{}
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("parse config file %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(raw); err != nil {
+			return fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported config file format: %s", path)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv 使用环境变量覆盖配置，格式错误的值会返回错误而不是被忽略
+func applyEnv(cfg *Config) error {
+	var errs []error
+
+	envString("DB_TYPE", &cfg.Database.Type)
+	envString("DB_HOST", &cfg.Database.Host)
+	envString("DB_PORT", &cfg.Database.Port)
+	envString("DB_USER", &cfg.Database.User)
+	envString("DB_PASSWORD", &cfg.Database.Password)
+	envString("DB_NAME", &cfg.Database.Name)
+	envString("DB_SSL_MODE", &cfg.Database.SSLMode)
+	envString("DB_SSL_ROOT_CERT", &cfg.Database.SSLRootCert)
+	envString("DB_LOG_LEVEL", &cfg.Database.LogLevel)
+	errs = append(errs,
+		envInt("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns),
+		envInt("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns),
+		envDuration("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime),
+		envDuration("DB_CONN_MAX_IDLE_TIME", &cfg.Database.ConnMaxIdleTime),
+		envDuration("DB_READ_AFTER_WRITE_WINDOW", &cfg.Database.ReadAfterWriteWindow),
+	)
+	// DB_REPLICAS 为逗号分隔的副本地址列表，如 "replica1:5432,replica2:5432"
+	if value := os.Getenv("DB_REPLICAS"); value != "" {
+		cfg.Database.Replicas = nil
+		for _, addr := range splitList(value) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				host, port = addr, ""
+			}
+			cfg.Database.Replicas = append(cfg.Database.Replicas, ReplicaConfig{Host: host, Port: port})
+		}
+	}
+
+	envString("JWT_SECRET", &cfg.JWT.Secret)
+	if value := os.Getenv("JWT_EXPIRE_HOURS"); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("JWT_EXPIRE_HOURS: invalid integer %q", value))
+		} else {
+			cfg.JWT.ExpireTime = time.Duration(hours) * time.Hour
+		}
+	}
+
+	errs = append(errs,
+		envDuration("SESSION_CACHE_TTL", &cfg.Session.CacheTTL),
+		envInt("SESSION_CACHE_MAX_ENTRIES", &cfg.Session.CacheMaxEntries),
+	)
+
+	envString("TWO_FACTOR_ISSUER", &cfg.TwoFactor.Issuer)
+	errs = append(errs, envDuration("TWO_FACTOR_CHALLENGE_TTL", &cfg.TwoFactor.ChallengeTTL))
+	if value := os.Getenv("TWO_FACTOR_REQUIRED_ROLES"); value != "" {
+		cfg.TwoFactor.RequiredRoles = splitList(value)
+	}
+
+	errs = append(errs, envBool("OIDC_ENABLED", &cfg.OIDC.Enabled))
+	envString("OIDC_NAME", &cfg.OIDC.Name)
+	envString("OIDC_DISCOVERY_URL", &cfg.OIDC.DiscoveryURL)
+	envString("OIDC_CLIENT_ID", &cfg.OIDC.ClientID)
+	envString("OIDC_CLIENT_SECRET", &cfg.OIDC.ClientSecret)
+	envString("OIDC_REDIRECT_URL", &cfg.OIDC.RedirectURL)
+	if value := os.Getenv("OIDC_SCOPES"); value != "" {
+		cfg.OIDC.Scopes = splitList(value)
+	}
+	errs = append(errs,
+		envBool("OIDC_ALLOW_SIGNUP", &cfg.OIDC.AllowSignup),
+		envDuration("OIDC_STATE_TTL", &cfg.OIDC.StateTTL),
+	)
+	envString("OIDC_FRONTEND_REDIRECT", &cfg.OIDC.FrontendRedirect)
+
+	envString("SERVER_HOST", &cfg.Server.Host)
+	envString("SERVER_PORT", &cfg.Server.Port)
+
+	envString("FRONTEND_DIR", &cfg.Frontend.Dir)
+	errs = append(errs, envDuration("FRONTEND_ASSET_MAX_AGE", &cfg.Frontend.AssetMaxAge))
+
+	errs = append(errs, envBool("GRPC_ENABLED", &cfg.GRPC.Enabled))
+	envString("GRPC_HOST", &cfg.GRPC.Host)
+	envString("GRPC_PORT", &cfg.GRPC.Port)
+
+	errs = append(errs,
+		envInt("GRAPHQL_MAX_DEPTH", &cfg.GraphQL.MaxDepth),
+		envInt("GRAPHQL_MAX_COMPLEXITY", &cfg.GraphQL.MaxComplexity),
+	)
+
+	if value := os.Getenv("CORS_ALLOWED_ORIGINS"); value != "" {
+		cfg.CORS.AllowedOrigins = splitList(value)
+	}
+	errs = append(errs,
+		envBool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials),
+		envDuration("CORS_MAX_AGE", &cfg.CORS.MaxAge),
+	)
+
+	errs = append(errs,
+		envBool("CACHE_ENABLED", &cfg.Cache.Enabled),
+		envInt("CACHE_MAX_ENTRIES", &cfg.Cache.MaxEntries),
+		envDuration("CACHE_TTL", &cfg.Cache.TTL),
+		envDuration("CACHE_MAX_AGE", &cfg.Cache.MaxAge),
+	)
+
+	envString("FEED_TITLE", &cfg.Feed.Title)
+	envString("FEED_DESCRIPTION", &cfg.Feed.Description)
+	envString("FEED_BASE_URL", &cfg.Feed.BaseURL)
+	errs = append(errs, envInt("FEED_MAX_ITEMS", &cfg.Feed.MaxItems))
+
+	envString("TIMELINE_STRATEGY", &cfg.Timeline.Strategy)
+	errs = append(errs, envInt("TIMELINE_BACKFILL", &cfg.Timeline.Backfill))
+
+	errs = append(errs,
+		envInt("UPLOAD_MAX_SIZE", &cfg.Upload.MaxSize),
+		envInt("UPLOAD_MAX_IMAGE_WIDTH", &cfg.Upload.MaxImageWidth),
+		envInt("UPLOAD_MAX_IMAGE_HEIGHT", &cfg.Upload.MaxImageHeight),
+		envInt("UPLOAD_THUMBNAIL_SIZE", &cfg.Upload.ThumbnailSize),
+	)
+	if value := os.Getenv("UPLOAD_ALLOWED_TYPES"); value != "" {
+		cfg.Upload.AllowedTypes = splitList(value)
+	}
+	envString("UPLOAD_STORAGE", &cfg.Upload.Storage)
+	envString("UPLOAD_LOCAL_DIR", &cfg.Upload.LocalDir)
+	envString("UPLOAD_S3_ENDPOINT", &cfg.Upload.S3.Endpoint)
+	envString("UPLOAD_S3_REGION", &cfg.Upload.S3.Region)
+	envString("UPLOAD_S3_BUCKET", &cfg.Upload.S3.Bucket)
+	envString("UPLOAD_S3_ACCESS_KEY", &cfg.Upload.S3.AccessKey)
+	envString("UPLOAD_S3_SECRET_KEY", &cfg.Upload.S3.SecretKey)
+
+	errs = append(errs,
+		envBool("WEBHOOK_ENABLED", &cfg.Webhook.Enabled),
+		envDuration("WEBHOOK_TIMEOUT", &cfg.Webhook.Timeout),
+		envInt("WEBHOOK_MAX_ATTEMPTS", &cfg.Webhook.MaxAttempts),
+		envDuration("WEBHOOK_INITIAL_BACKOFF", &cfg.Webhook.InitialBackoff),
+		envDuration("WEBHOOK_MAX_BACKOFF", &cfg.Webhook.MaxBackoff),
+		envDuration("WEBHOOK_POLL_INTERVAL", &cfg.Webhook.PollInterval),
+	)
+
+	errs = append(errs,
+		envBool("IDEMPOTENCY_ENABLED", &cfg.Idempotency.Enabled),
+		envDuration("IDEMPOTENCY_TTL", &cfg.Idempotency.TTL),
+	)
+
+	errs = append(errs,
+		envDuration("TRASH_RETENTION", &cfg.Trash.Retention),
+		envDuration("TRASH_PURGE_INTERVAL", &cfg.Trash.PurgeInterval),
+	)
+
+	errs = append(errs, envInt("MODERATION_REPORT_THRESHOLD", &cfg.Moderation.ReportThreshold))
+
+	errs = append(errs,
+		envBool("FILTER_ENABLED", &cfg.Filter.Enabled),
+		envInt("FILTER_MAX_POST_LINKS", &cfg.Filter.MaxPostLinks),
+		envInt("FILTER_MAX_COMMENT_LINKS", &cfg.Filter.MaxCommentLinks),
+		envDuration("FILTER_DUPLICATE_WINDOW", &cfg.Filter.DuplicateWindow),
+		envDuration("FILTER_NEW_ACCOUNT_AGE", &cfg.Filter.NewAccountAge),
+		envInt("FILTER_NEW_ACCOUNT_MAX_PER_HOUR", &cfg.Filter.NewAccountMaxPerHour),
+	)
+	if value := os.Getenv("FILTER_BLOCKED_WORDS"); value != "" {
+		cfg.Filter.BlockedWords = splitList(value)
+	}
+
+	envString("LOG_LEVEL", &cfg.Log.Level)
+
+	errs = append(errs,
+		envBool("TRACING_ENABLED", &cfg.Tracing.Enabled),
+		envBool("TRACING_INSECURE", &cfg.Tracing.Insecure),
+		envFloat("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio),
+	)
+	envString("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	envString("TRACING_ENDPOINT", &cfg.Tracing.Endpoint)
+	envString("TRACING_SERVICE_NAME", &cfg.Tracing.ServiceName)
+
+	return errors.Join(errs...)
+}
+
+// applyFlags 使用显式传入的命令行参数覆盖配置
+func applyFlags(cfg *Config, v flagValues, visited map[string]bool) {
+	if visited["host"] {
+		cfg.Server.Host = v.host
+	}
+	if visited["port"] {
+		cfg.Server.Port = v.port
+	}
+	if visited["db-type"] {
+		cfg.Database.Type = v.dbType
+	}
+	if visited["db-name"] {
+		cfg.Database.Name = v.dbName
+	}
+	if visited["log-level"] {
+		cfg.Log.Level = v.logLevel
+	}
+	if visited["frontend-dir"] {
+		cfg.Frontend.Dir = v.frontendDir
+	}
+}
+
+// envString 环境变量存在时覆盖字符串配置
+func envString(key string, dst *string) {
+	if value := os.Getenv(key); value != "" {
+		*dst = value
+	}
+}
+
+// envInt 环境变量存在时覆盖整数配置
+func envInt(key string, dst *int) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s: invalid integer %q", key, value)
+	}
+	*dst = n
+	return nil
+}
+
+// envBool 环境变量存在时覆盖布尔配置
+func envBool(key string, dst *bool) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s: invalid boolean %q", key, value)
+	}
+	*dst = b
+	return nil
+}
+
+// envFloat 环境变量存在时覆盖浮点数配置
+func envFloat(key string, dst *float64) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("%s: invalid number %q", key, value)
+	}
+	*dst = f
+	return nil
+}
+
+// envDuration 环境变量存在时覆盖时长配置（如 "30m"、"1h"）
+func envDuration(key string, dst *time.Duration) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: invalid duration %q", key, value)
+	}
+	*dst = d
+	return nil
+}
+
+// splitList 解析逗号分隔的列表，忽略空白项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
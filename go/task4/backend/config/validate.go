@@ -0,0 +1,259 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Validate 校验配置
+// 收集所有不合法的配置项一并返回，便于启动时一次性修正
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	// 数据库
+	check(oneOf(c.Database.Type, "sqlite", "mysql", "postgres"),
+		"database.type: unsupported database type %q (want sqlite, mysql or postgres)", c.Database.Type)
+	check(c.Database.Name != "", "database.name: must not be empty")
+	if c.Database.Type == "mysql" || c.Database.Type == "postgres" {
+		check(c.Database.Host != "", "database.host: must not be empty for %s", c.Database.Type)
+		check(c.Database.User != "", "database.user: must not be empty for %s", c.Database.Type)
+		check(c.Database.Port == "" || validPort(c.Database.Port), "database.port: invalid port %q", c.Database.Port)
+		check(oneOf(c.Database.SSLMode, "disable", "require", "verify-ca", "verify-full"),
+			"database.ssl_mode: unsupported mode %q (want disable, require, verify-ca or verify-full)", c.Database.SSLMode)
+	}
+	check(oneOf(c.Database.LogLevel, "silent", "error", "warn", "info"),
+		"database.log_level: unsupported level %q (want silent, error, warn or info)", c.Database.LogLevel)
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns: must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns: must not be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime: must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time: must not be negative")
+	check(c.Database.ReadAfterWriteWindow >= 0, "database.read_after_write_window: must not be negative")
+	for i, replica := range c.Database.Replicas {
+		if c.Database.Type == "sqlite" {
+			check(replica.Name != "", "database.replicas[%d].name: must not be empty for sqlite", i)
+			continue
+		}
+		check(replica.Host != "", "database.replicas[%d].host: must not be empty", i)
+		check(replica.Port == "" || validPort(replica.Port), "database.replicas[%d].port: invalid port %q", i, replica.Port)
+	}
+
+	// JWT
+	check(c.JWT.Secret != "", "jwt.secret: must not be empty")
+	check(c.JWT.ExpireTime > 0, "jwt.expire_time: must be positive")
+
+	// 登录会话
+	check(c.Session.CacheTTL > 0, "session.cache_ttl: must be positive")
+	check(c.Session.CacheMaxEntries > 0, "session.cache_max_entries: must be positive")
+
+	// 两步验证
+	check(c.TwoFactor.Issuer != "", "two_factor.issuer: must not be empty")
+	check(c.TwoFactor.ChallengeTTL > 0, "two_factor.challenge_ttl: must be positive")
+	for i, role := range c.TwoFactor.RequiredRoles {
+		check(role == "moderator" || role == "admin",
+			"two_factor.required_roles[%d]: unsupported role %q (want moderator or admin)", i, role)
+	}
+
+	// OpenID Connect
+	if c.OIDC.Enabled {
+		check(c.OIDC.Name != "", "oidc.name: must not be empty")
+		check(absoluteURL(c.OIDC.DiscoveryURL), "oidc.discovery_url: must be an absolute http(s) URL, got %q", c.OIDC.DiscoveryURL)
+		check(c.OIDC.ClientID != "", "oidc.client_id: must not be empty")
+		check(absoluteURL(c.OIDC.RedirectURL), "oidc.redirect_url: must be an absolute http(s) URL, got %q", c.OIDC.RedirectURL)
+		hasOpenID := false
+		for _, scope := range c.OIDC.Scopes {
+			hasOpenID = hasOpenID || scope == "openid"
+		}
+		check(hasOpenID, "oidc.scopes: must include openid")
+		check(strings.HasPrefix(c.OIDC.FrontendRedirect, "/") && !strings.HasPrefix(c.OIDC.FrontendRedirect, "//") || absoluteURL(c.OIDC.FrontendRedirect),
+			"oidc.frontend_redirect: must be a path starting with / or an absolute http(s) URL, got %q", c.OIDC.FrontendRedirect)
+		check(c.OIDC.StateTTL > 0, "oidc.state_ttl: must be positive")
+	}
+
+	// 服务器
+	check(validPort(c.Server.Port), "server.port: invalid port %q", c.Server.Port)
+
+	// 前端
+	check(c.Frontend.AssetMaxAge >= 0, "frontend.asset_max_age: must not be negative")
+
+	// gRPC
+	if c.GRPC.Enabled {
+		check(validPort(c.GRPC.Port), "grpc.port: invalid port %q", c.GRPC.Port)
+		check(c.GRPC.Port != c.Server.Port, "grpc.port: must differ from server.port %q", c.Server.Port)
+	}
+
+	// GraphQL
+	check(c.GraphQL.MaxDepth >= 0, "graphql.max_depth: must not be negative")
+	check(c.GraphQL.MaxComplexity >= 0, "graphql.max_complexity: must not be negative")
+
+	// 跨域
+	errs = append(errs, validateCORSOrigins("cors", c.CORS.AllowedOrigins, c.CORS.AllowCredentials)...)
+	check(len(c.CORS.AllowedMethods) > 0, "cors.allowed_methods: must not be empty")
+	check(c.CORS.MaxAge >= 0, "cors.max_age: must not be negative")
+	for i, route := range c.CORS.Routes {
+		prefix := fmt.Sprintf("cors.routes[%d]", i)
+		check(strings.HasPrefix(route.PathPrefix, "/"), "%s.path_prefix: must start with \"/\", got %q", prefix, route.PathPrefix)
+		origins, credentials := c.CORS.AllowedOrigins, c.CORS.AllowCredentials
+		if len(route.AllowedOrigins) > 0 {
+			origins = route.AllowedOrigins
+		}
+		if route.AllowCredentials != nil {
+			credentials = *route.AllowCredentials
+		}
+		errs = append(errs, validateCORSOrigins(prefix, origins, credentials)...)
+	}
+
+	// 响应缓存
+	if c.Cache.Enabled {
+		check(c.Cache.MaxEntries > 0, "cache.max_entries: must be positive")
+		check(c.Cache.TTL > 0, "cache.ttl: must be positive")
+	}
+	check(c.Cache.MaxAge >= 0, "cache.max_age: must not be negative")
+	check(c.Cache.StaleWhileRevalidate >= 0, "cache.stale_while_revalidate: must not be negative")
+
+	// 订阅源
+	check(c.Feed.Title != "", "feed.title: must not be empty")
+	check(c.Feed.MaxItems > 0 && c.Feed.MaxItems <= 100, "feed.max_items: must be between 1 and 100, got %d", c.Feed.MaxItems)
+	if c.Feed.BaseURL != "" {
+		check(absoluteURL(c.Feed.BaseURL), "feed.base_url: must be an absolute http(s) URL, got %q", c.Feed.BaseURL)
+	}
+
+	// 首页动态
+	check(oneOf(c.Timeline.Strategy, "read", "write"),
+		"timeline.strategy: unsupported strategy %q (want read or write)", c.Timeline.Strategy)
+	check(c.Timeline.Backfill >= 0 && c.Timeline.Backfill <= 1000, "timeline.backfill: must be between 0 and 1000, got %d", c.Timeline.Backfill)
+
+	// 文件上传
+	check(c.Upload.MaxSize > 0, "upload.max_size: must be positive")
+	check(len(c.Upload.AllowedTypes) > 0, "upload.allowed_types: must not be empty")
+	for i, t := range c.Upload.AllowedTypes {
+		check(strings.Count(t, "/") == 1 && !strings.ContainsAny(t, " ;"), "upload.allowed_types[%d]: invalid MIME type %q", i, t)
+	}
+	check(c.Upload.MaxImageWidth > 0, "upload.max_image_width: must be positive")
+	check(c.Upload.MaxImageHeight > 0, "upload.max_image_height: must be positive")
+	check(c.Upload.ThumbnailSize > 0, "upload.thumbnail_size: must be positive")
+	switch c.Upload.Storage {
+	case "local":
+		check(c.Upload.LocalDir != "", "upload.local_dir: must not be empty")
+	case "s3":
+		u, err := url.Parse(c.Upload.S3.Endpoint)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"upload.s3.endpoint: must be an absolute http(s) URL, got %q", c.Upload.S3.Endpoint)
+		check(c.Upload.S3.Region != "", "upload.s3.region: must not be empty")
+		check(c.Upload.S3.Bucket != "", "upload.s3.bucket: must not be empty")
+		check(c.Upload.S3.AccessKey != "" && c.Upload.S3.SecretKey != "",
+			"upload.s3: access_key and secret_key must not be empty")
+	default:
+		check(false, "upload.storage: unsupported storage %q (want local or s3)", c.Upload.Storage)
+	}
+
+	// 事件通知
+	if c.Webhook.Enabled {
+		check(c.Webhook.Timeout > 0, "webhook.timeout: must be positive")
+		check(c.Webhook.MaxAttempts > 0, "webhook.max_attempts: must be positive")
+		check(c.Webhook.InitialBackoff > 0, "webhook.initial_backoff: must be positive")
+		check(c.Webhook.MaxBackoff >= c.Webhook.InitialBackoff, "webhook.max_backoff: must not be less than initial_backoff")
+		check(c.Webhook.PollInterval > 0, "webhook.poll_interval: must be positive")
+	}
+
+	// 幂等键
+	if c.Idempotency.Enabled {
+		check(c.Idempotency.TTL > 0, "idempotency.ttl: must be positive")
+	}
+
+	// 回收站
+	check(c.Trash.Retention >= 0, "trash.retention: must not be negative")
+	check(c.Trash.PurgeInterval > 0, "trash.purge_interval: must be positive")
+
+	// 内容审核
+	check(c.Moderation.ReportThreshold >= 0, "moderation.report_threshold: must not be negative")
+
+	// 垃圾内容过滤
+	if c.Filter.Enabled {
+		check(c.Filter.MaxPostLinks >= 0, "filter.max_post_links: must not be negative")
+		check(c.Filter.MaxCommentLinks >= 0, "filter.max_comment_links: must not be negative")
+		check(c.Filter.DuplicateWindow >= 0, "filter.duplicate_window: must not be negative")
+		check(c.Filter.NewAccountAge >= 0, "filter.new_account_age: must not be negative")
+		check(c.Filter.NewAccountAge == 0 || c.Filter.NewAccountMaxPerHour > 0,
+			"filter.new_account_max_per_hour: must be positive when new_account_age is set")
+		for i, word := range c.Filter.BlockedWords {
+			check(strings.TrimSpace(word) != "", "filter.blocked_words[%d]: must not be empty", i)
+		}
+	}
+
+	// 日志
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"),
+		"log.level: unsupported level %q (want debug, info, warn or error)", c.Log.Level)
+
+	// 链路追踪
+	if c.Tracing.Enabled {
+		check(oneOf(c.Tracing.Exporter, "otlp", "stdout"),
+			"tracing.exporter: unsupported exporter %q (want otlp or stdout)", c.Tracing.Exporter)
+		check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint: must not be empty for otlp")
+		check(c.Tracing.ServiceName != "", "tracing.service_name: must not be empty")
+		check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+			"tracing.sample_ratio: must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// oneOf 判断值是否在允许的取值范围内
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// validPort 判断端口号是否合法（1~65535）
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// absoluteURL 判断是否为绝对的 http(s) 地址
+func absoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateCORSOrigins 校验跨域来源列表，允许携带凭证时不能使用 "*"
+func validateCORSOrigins(prefix string, origins []string, credentials bool) []error {
+	var errs []error
+	for _, origin := range origins {
+		if !validOrigin(origin) {
+			errs = append(errs, fmt.Errorf("%s.allowed_origins: invalid origin %q", prefix, origin))
+		}
+		if origin == "*" && credentials {
+			errs = append(errs, fmt.Errorf("%s.allowed_origins: \"*\" cannot be used with allow_credentials", prefix))
+		}
+	}
+	return errs
+}
+
+// validOrigin 判断跨域来源是否合法
+// 必须是 "*"、"scheme://host[:port]" 或 "scheme://*.domain[:port]" 形式
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+		return false
+	}
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	return host != "" && !strings.Contains(host, "*")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"blog/config"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runConfigCommand 处理 config 子命令
+// 目前支持 `config print`：以 YAML 格式打印合并后的生效配置，敏感字段会被隐藏
+func runConfigCommand(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: blog config print [flags]")
+	}
+	cfg, err := config.Load(args[1:])
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"archive/zip"
+	"blog/archive"
+	"blog/audit"
+	"blog/config"
+	"blog/database"
+	"blog/timeline"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// runExportCommand 处理 export 子命令
+// `export <file.zip> [flags]`：把所有文章及评论导出为 Markdown 文件的 zip 归档
+func runExportCommand(args []string) error {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: blog export <file.zip> [flags]")
+	}
+	if err := initContentDatabase(args[1:]); err != nil {
+		return err
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	ctx := context.Background()
+	count, err := archive.Export(ctx, database.DB, file)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		os.Remove(args[0])
+		return fmt.Errorf("export: %w", err)
+	}
+	err = audit.Write(ctx, audit.Entry{
+		ActorName: "cli",
+		Action:    audit.ActionContentExport,
+		After:     map[string]int{"posts": count},
+	}, "", "")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("exported %d posts to %s\n", count, args[0])
+	return nil
+}
+
+// runImportCommand 处理 import 子命令
+// `import <file.zip> [flags]`：导入 export 生成的归档，重复导入不会产生重复的文章和评论
+func runImportCommand(args []string) error {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: blog import <file.zip> [flags]")
+	}
+	zr, err := zip.OpenReader(args[0])
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	if err := initContentDatabase(args[1:]); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	result, err := archive.Import(ctx, database.DB, &zr.Reader)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	err = audit.Write(ctx, audit.Entry{
+		ActorName: "cli",
+		Action:    audit.ActionContentImport,
+		After:     result,
+	}, "", "")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("posts: %d imported, %d skipped\n", result.Posts, result.SkippedPosts)
+	fmt.Printf("comments: %d imported, %d skipped\n", result.Comments, result.SkippedComments)
+	for _, user := range result.CreatedUsers {
+		fmt.Printf("created user %s (random password)\n", user)
+	}
+	return nil
+}
+
+// initContentDatabase 加载配置并连接数据库、迁移表结构，并选择首页动态的分发策略（导入的文章按策略分发）
+func initContentDatabase(args []string) error {
+	cfg, err := config.Init(args)
+	if err != nil {
+		return err
+	}
+	if err := database.InitDB(&cfg.Database); err != nil {
+		return fmt.Errorf("database init: %w", err)
+	}
+	if err := database.InitTable(); err != nil {
+		return fmt.Errorf("database migrate: %w", err)
+	}
+	timeline.Init(&cfg.Timeline)
+	return nil
+}
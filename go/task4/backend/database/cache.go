@@ -0,0 +1,99 @@
+package database
+
+import (
+	"blog/cache"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// pendingInvalidationKey 事务内待失效标签在语句设置中的键
+const pendingInvalidationKey = "cache:pending_invalidation"
+
+// CacheInvalidationPlugin 响应缓存失效插件
+// 任何对文章、评论、用户表的写操作成功后，使依赖该表的缓存响应失效；
+// 通过 Transaction 开启的事务内的写操作推迟到事务提交后再失效
+type CacheInvalidationPlugin struct{}
+
+// pendingInvalidation 事务内累积的待失效标签
+type pendingInvalidation struct {
+	mu   sync.Mutex
+	tags map[string]struct{}
+}
+
+// add 记录待失效的标签
+func (p *pendingInvalidation) add(tags ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, tag := range tags {
+		p.tags[tag] = struct{}{}
+	}
+}
+
+// flush 使累积的标签失效
+func (p *pendingInvalidation) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.tags) == 0 {
+		return
+	}
+	tags := make([]string, 0, len(p.tags))
+	for tag := range p.tags {
+		tags = append(tags, tag)
+	}
+	cache.Invalidate(tags...)
+}
+
+// Transaction 在事务中执行 fc，事务提交后再使事务内写过的表对应的缓存响应失效
+// 提交前失效会让并发的读请求把未提交前的旧数据重新写入缓存；嵌套调用时由最外层事务统一失效
+func Transaction(db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	if _, ok := db.Get(pendingInvalidationKey); ok {
+		return db.Transaction(fc)
+	}
+	pending := &pendingInvalidation{tags: make(map[string]struct{})}
+	if err := db.Set(pendingInvalidationKey, pending).Transaction(fc); err != nil {
+		return err
+	}
+	pending.flush()
+	return nil
+}
+
+// Name 插件名称
+func (CacheInvalidationPlugin) Name() string {
+	return "cache-invalidation"
+}
+
+// Initialize 在创建、更新、删除和原生执行语句之后注册失效回调
+func (p CacheInvalidationPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("cache:invalidate_create", p.invalidate); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("cache:invalidate_update", p.invalidate); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("cache:invalidate_delete", p.invalidate); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("cache:invalidate_raw", p.invalidate)
+}
+
+// invalidate 根据语句涉及的表使缓存失效；无法确定表名的原生语句使全部缓存失效
+func (CacheInvalidationPlugin) invalidate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	var tags []string
+	if tag := cache.TagForTable(db.Statement.Table); tag != "" {
+		tags = []string{tag}
+	} else if db.Statement.Table == "" {
+		tags = []string{cache.TagPost, cache.TagComment, cache.TagUser}
+	} else {
+		return
+	}
+	if pending, ok := db.Get(pendingInvalidationKey); ok {
+		pending.(*pendingInvalidation).add(tags...)
+		return
+	}
+	cache.Invalidate(tags...)
+}
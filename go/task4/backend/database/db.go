@@ -3,10 +3,18 @@ package database
 import (
 	"blog/config"
 	"blog/models"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
 
 	"github.com/glebarez/sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
@@ -15,52 +23,184 @@ import (
 // DB 全局数据库连接
 var DB *gorm.DB
 
+// mysqlTLSConfigName 使用自定义 CA 证书时注册到 MySQL 驱动的 TLS 配置名
+const mysqlTLSConfigName = "blog"
+
 // InitDB 初始化数据库连接
-// 根据配置连接 MySQL 或 SQLite 数据库
+// 根据配置连接 MySQL、PostgreSQL 或 SQLite 数据库，并连接配置的只读副本
 func InitDB(cfg *config.DatabaseConfig) error {
-	switch cfg.Type {
-	case "sqlite":
-		db, err := gorm.Open(sqlite.Open(cfg.Name), &gorm.Config{
-			Logger:                 logger.Default.LogMode(logger.Info),
-			SkipDefaultTransaction: true,
-			NamingStrategy: schema.NamingStrategy{
-				SingularTable: true,
-			},
-		})
+	db, err := open(cfg)
+	if err != nil {
+		return err
+	}
+	DB = db
+
+	// 连接只读副本
+	replicas = nil
+	for i, replicaCfg := range cfg.Replicas {
+		replica, err := open(replicaConfig(cfg, replicaCfg))
 		if err != nil {
-			return err
+			return fmt.Errorf("replica %d: %w", i, err)
 		}
-		DB = db
+		replicas = append(replicas, replica)
+	}
+	return nil
+}
+
+// open 打开一个数据库连接并完成连接池、插件等公共配置
+func open(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:                 logger.Default.LogMode(logLevel(cfg.LogLevel)),
+		SkipDefaultTransaction: true,
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 配置连接池
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
+	// 注册链路追踪插件，为每条 SQL 语句创建子 Span
+	if err := db.Use(NewTracingPlugin(cfg.Type)); err != nil {
+		return nil, err
+	}
+	// 注册缓存失效插件，写操作后清除相关的响应缓存
+	if err := db.Use(CacheInvalidationPlugin{}); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// newDialector 根据数据库类型创建 GORM 方言
+func newDialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Type {
+	case "sqlite":
+		return sqlite.Open(cfg.Name), nil
 	case "mysql":
-		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
-
-		db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-			Logger:                 logger.Default.LogMode(logger.Info),
-			SkipDefaultTransaction: true,
-			NamingStrategy: schema.NamingStrategy{
-				SingularTable: true,
-			},
-		})
+		dsn, err := mysqlDSN(cfg)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		DB = db
+		return mysql.Open(dsn), nil
+	case "postgres":
+		return postgres.Open(postgresDSN(cfg)), nil
 	default:
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
+	}
+}
+
+// mysqlDSN 构建 MySQL 连接字符串
+// SSL 模式映射：disable 不加密，require 加密但不校验证书，verify-ca / verify-full 校验证书
+func mysqlDSN(cfg *config.DatabaseConfig) (string, error) {
+	dsnCfg := mysqldriver.NewConfig()
+	dsnCfg.User = cfg.User
+	dsnCfg.Passwd = cfg.Password
+	dsnCfg.Net = "tcp"
+	dsnCfg.Addr = net.JoinHostPort(cfg.Host, portOrDefault(cfg))
+	dsnCfg.DBName = cfg.Name
+	dsnCfg.ParseTime = true
+	dsnCfg.Loc = time.Local
+	dsnCfg.Params = map[string]string{"charset": "utf8mb4"}
+
+	switch cfg.SSLMode {
+	case "require":
+		dsnCfg.TLSConfig = "skip-verify"
+	case "verify-ca", "verify-full":
+		dsnCfg.TLSConfig = "true"
+		if cfg.SSLRootCert != "" {
+			if err := registerMySQLTLS(cfg); err != nil {
+				return "", err
+			}
+			dsnCfg.TLSConfig = mysqlTLSConfigName
+		}
+	}
+	return dsnCfg.FormatDSN(), nil
+}
 
-		return fmt.Errorf("unsupported database type: %s", cfg.Type)
+// registerMySQLTLS 使用自定义 CA 证书注册 MySQL TLS 配置
+func registerMySQLTLS(cfg *config.DatabaseConfig) error {
+	pem, err := os.ReadFile(cfg.SSLRootCert)
+	if err != nil {
+		return fmt.Errorf("read ssl root cert: %w", err)
 	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("invalid ssl root cert: %s", cfg.SSLRootCert)
+	}
+	return mysqldriver.RegisterTLSConfig(mysqlTLSConfigName, &tls.Config{
+		RootCAs:    pool,
+		ServerName: cfg.Host,
+	})
+}
 
-	return nil
+// postgresDSN 构建 PostgreSQL 连接字符串（URL 形式，自动转义用户名和密码中的特殊字符）
+func postgresDSN(cfg *config.DatabaseConfig) string {
+	query := url.Values{}
+	query.Set("sslmode", cfg.SSLMode)
+	if cfg.SSLRootCert != "" {
+		query.Set("sslrootcert", cfg.SSLRootCert)
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, portOrDefault(cfg)),
+		Path:     "/" + cfg.Name,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+// portOrDefault 返回配置的端口，未配置时使用各数据库的默认端口
+func portOrDefault(cfg *config.DatabaseConfig) string {
+	if cfg.Port != "" {
+		return cfg.Port
+	}
+	switch cfg.Type {
+	case "postgres":
+		return "5432"
+	default:
+		return "3306"
+	}
+}
+
+// logLevel 将配置中的 SQL 日志级别转换为 GORM 日志级别
+func logLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "warn":
+		return logger.Warn
+	default:
+		return logger.Info
+	}
 }
 
 // InitTable 自动迁移表结构
 // 根据模型自动创建或更新数据库表
 func InitTable() error {
 	//  实现自动迁移逻辑
-	// 迁移 User, Post, Comment 模型
-	err := DB.AutoMigrate(&models.User{}, &models.Post{}, &models.Comment{})
+	// 迁移 User, Post, Comment, AuditLog, Report 模型
+	err := DB.AutoMigrate(&models.User{}, &models.Post{}, &models.Comment{}, &models.AuditLog{}, &models.Report{},
+		&models.Attachment{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.WebhookAttempt{}, &models.IdempotencyKey{},
+		&models.PersonalAccessToken{}, &models.RecoveryCode{}, &models.TwoFactorChallenge{}, &models.UserIdentity{},
+		&models.OIDCLoginState{}, &models.Session{}, &models.Follow{}, &models.TimelineItem{})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"blog/config"
+	"blog/models"
+	"context"
+	"log"
+	"time"
+)
+
+// idempotencyPurgeInterval 清理过期幂等键的间隔
+const idempotencyPurgeInterval = time.Hour
+
+// StartIdempotencyPurge 启动过期幂等键的清理任务
+// 每小时删除一次过期的幂等键和保存的响应，ctx 取消时退出；未启用幂等键时不启动
+func StartIdempotencyPurge(ctx context.Context, cfg *config.IdempotencyConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(idempotencyPurgeInterval)
+		defer ticker.Stop()
+		for {
+			result := DB.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{})
+			if result.Error != nil && ctx.Err() == nil {
+				log.Println("Blog idempotency purge error: ", result.Error)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
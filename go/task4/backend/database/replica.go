@@ -0,0 +1,58 @@
+package database
+
+import (
+	"blog/config"
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// replicas 只读副本连接
+var replicas []*gorm.DB
+
+// replicaIndex 轮询选择副本的计数器
+var replicaIndex atomic.Uint64
+
+// primaryKey 标记当前请求必须读主库的 context 键
+type primaryKey struct{}
+
+// WithPrimary 标记 ctx 中后续的读操作必须走主库（用于写后读场景）
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// usePrimary 判断 ctx 是否要求读主库
+func usePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// ReadDB 返回用于只读查询的数据库连接
+// 配置了副本时按轮询选择一个副本；未配置副本或 ctx 要求读主库时返回主库
+func ReadDB(ctx context.Context) *gorm.DB {
+	if len(replicas) == 0 || usePrimary(ctx) {
+		return DB.WithContext(ctx)
+	}
+	n := replicaIndex.Add(1)
+	return replicas[n%uint64(len(replicas))].WithContext(ctx)
+}
+
+// HasReplicas 是否配置了只读副本
+func HasReplicas() bool {
+	return len(replicas) > 0
+}
+
+// replicaConfig 基于主库配置生成副本的连接配置
+func replicaConfig(primary *config.DatabaseConfig, replica config.ReplicaConfig) *config.DatabaseConfig {
+	cfg := *primary
+	cfg.Replicas = nil
+	if replica.Host != "" {
+		cfg.Host = replica.Host
+	}
+	cfg.Port = replica.Port
+	if replica.Name != "" {
+		cfg.Name = replica.Name
+	}
+	return &cfg
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"blog/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey 在 gorm.DB 实例中保存当前语句 Span 的键
+const spanKey = "otel:span"
+
+// TracingPlugin GORM 链路追踪插件
+// 为每条 SQL 语句创建一个子 Span，父 Span 取自 db.WithContext 传入的请求上下文
+type TracingPlugin struct {
+	system string // 数据库类型，对应 db.system 属性
+}
+
+// NewTracingPlugin 创建 GORM 链路追踪插件
+func NewTracingPlugin(system string) *TracingPlugin {
+	return &TracingPlugin{system: system}
+}
+
+// Name 插件名称
+func (p *TracingPlugin) Name() string {
+	return "otel-tracing"
+}
+
+// Initialize 注册各类操作的前置/后置回调
+func (p *TracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		name      string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+		operation string
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register, "INSERT"},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register, "SELECT"},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register, "UPDATE"},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register, "DELETE"},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register, "ROW"},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register, "RAW"},
+	}
+	for _, h := range hooks {
+		if err := h.before("otel:before_"+h.name, p.before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after("otel:after_"+h.name, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 在语句执行前开启子 Span
+func (p *TracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		// 没有上游 Span 时不单独创建根 Span，避免启动迁移等语句产生大量孤立 Trace
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", p.system),
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// after 在语句执行后记录 SQL、影响行数和错误并结束 Span
+func (p *TracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
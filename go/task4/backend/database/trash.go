@@ -0,0 +1,73 @@
+package database
+
+import (
+	"blog/config"
+	"blog/models"
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StartTrashPurge 启动回收站清理任务
+// 每隔 PurgeInterval 彻底删除超过保留时间的文章和评论，ctx 取消时退出；Retention 为 0 时不启动
+func StartTrashPurge(ctx context.Context, cfg *config.TrashConfig) {
+	if cfg.Retention <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			purgeTrash(ctx, cfg.Retention)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeTrash 执行一次清理并记录结果
+func purgeTrash(ctx context.Context, retention time.Duration) {
+	posts, comments, err := PurgeTrash(DB.WithContext(ctx), time.Now().Add(-retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Println("Blog trash purge error: ", err)
+		}
+		return
+	}
+	if posts > 0 || comments > 0 {
+		log.Printf("Blog trash purge: removed %d posts and %d comments", posts, comments)
+	}
+}
+
+// PurgeTrash 彻底删除 before 之前软删除的文章和评论
+// 被清理文章下的所有评论和首页动态收件箱中的条目一并删除，返回删除的文章数和评论数
+func PurgeTrash(db *gorm.DB, before time.Time) (posts int64, comments int64, err error) {
+	err = Transaction(db, func(tx *gorm.DB) error {
+		expiredPosts := tx.Unscoped().Model(&models.Post{}).Select("id").Where("deleted_at < ?", before)
+		result := tx.Unscoped().
+			Where("deleted_at < ?", before).
+			Or("post_id IN (?)", expiredPosts).
+			Delete(&models.Comment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		comments = result.RowsAffected
+
+		if err := tx.Where("post_id IN (?)", expiredPosts).Delete(&models.TimelineItem{}).Error; err != nil {
+			return err
+		}
+
+		result = tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.Post{})
+		if result.Error != nil {
+			return result.Error
+		}
+		posts = result.RowsAffected
+		return nil
+	})
+	return posts, comments, err
+}
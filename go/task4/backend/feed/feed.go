@@ -0,0 +1,108 @@
+package feed
+
+import (
+	"blog/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 订阅源和站点地图的 Content-Type
+const (
+	ContentTypeRSS     = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom    = "application/atom+xml; charset=utf-8"
+	ContentTypeJSON    = "application/feed+json; charset=utf-8"
+	ContentTypeSitemap = "application/xml; charset=utf-8"
+)
+
+// Site 站点信息
+type Site struct {
+	Title       string
+	Description string
+	BaseURL     string // 站点对外访问地址，不以 / 结尾
+}
+
+// URL 返回站点内路径的绝对地址
+func (s Site) URL(path string) string {
+	return s.BaseURL + path
+}
+
+// PostURL 返回文章详情页的绝对地址
+func (s Site) PostURL(id uint) string {
+	return s.URL(fmt.Sprintf("/pages/post-detail.html?id=%d", id))
+}
+
+// Feed 与输出格式无关的订阅源
+type Feed struct {
+	Title       string
+	Description string
+	Link        string    // 对应的网页地址
+	Path        string    // 订阅源自身的路径（不含扩展名），如 /feed、/authors/1/feed
+	Updated     time.Time // 最近一篇文章的更新时间
+	Items       []Item
+	site        Site
+}
+
+// Item 订阅源中的一篇文章
+type Item struct {
+	ID        uint
+	Title     string
+	Content   string
+	Link      string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+}
+
+// New 根据文章生成订阅源，posts 需按时间倒序排列并关联作者信息
+// author 不为空时生成该作者的订阅源
+func New(site Site, posts []models.Post, author *models.User) *Feed {
+	f := &Feed{
+		Title:       site.Title,
+		Description: site.Description,
+		Link:        site.URL("/"),
+		Path:        "/feed",
+		site:        site,
+	}
+	if author != nil {
+		f.Title = fmt.Sprintf("%s - %s", author.Name, site.Title)
+		f.Description = fmt.Sprintf("%s 发布的文章", author.Name)
+		f.Path = fmt.Sprintf("/authors/%d/feed", author.ID)
+	}
+	for _, post := range posts {
+		item := Item{
+			ID:        post.ID,
+			Title:     post.Title,
+			Content:   post.Content,
+			Link:      site.PostURL(post.ID),
+			Published: post.CreatedAt,
+			Updated:   post.UpdatedAt,
+		}
+		if post.User != nil {
+			item.Author = post.User.Name
+		}
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+	if f.Updated.IsZero() {
+		// 没有文章时使用固定时间，保证输出稳定，便于缓存
+		f.Updated = time.Unix(0, 0)
+	}
+	return f
+}
+
+// URL 返回订阅源指定格式（rss、atom、json）的绝对地址
+func (f *Feed) URL(format string) string {
+	return f.site.URL(f.Path + "." + format)
+}
+
+// summary 截取内容开头作为摘要
+func summary(content string, limit int) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+	return string(runes[:limit]) + "…"
+}
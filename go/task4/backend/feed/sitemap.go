@@ -0,0 +1,41 @@
+package feed
+
+import (
+	"blog/models"
+	"encoding/xml"
+	"time"
+)
+
+// MaxSitemapURLs 单个站点地图最多包含的地址数（sitemaps.org 协议限制）
+const MaxSitemapURLs = 50000
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Sitemap 输出站点地图，包含首页和所有已发布的文章
+// 首页的最后修改时间为最近一篇文章的更新时间
+func Sitemap(site Site, posts []models.Post) ([]byte, error) {
+	var latest time.Time
+	urls := make([]sitemapURL, 0, len(posts)+1)
+	urls = append(urls, sitemapURL{Loc: site.URL("/")})
+	for _, post := range posts {
+		if post.UpdatedAt.After(latest) {
+			latest = post.UpdatedAt
+		}
+		urls = append(urls, sitemapURL{
+			Loc:     site.PostURL(post.ID),
+			LastMod: post.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	if !latest.IsZero() {
+		urls[0].LastMod = latest.UTC().Format(time.RFC3339)
+	}
+	return marshalXML(urlSet{URLs: urls})
+}
@@ -0,0 +1,108 @@
+package filter
+
+import (
+	"blog/config"
+	"context"
+	"log"
+	"strings"
+	"sync"
+)
+
+// 待检查内容的类型
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+)
+
+// maxReasonLength 命中原因的最大长度，与 flag_reason 列长度一致
+const maxReasonLength = 255
+
+// Content 待检查的文章或评论
+type Content struct {
+	Type   string // 内容类型: post、comment
+	UserID uint   // 作者ID
+	Title  string // 文章标题，评论为空
+	Body   string // 文章或评论正文
+}
+
+// Classifier 内容分类器
+// 内置规则和自定义分类器（如接入第三方反垃圾服务）都实现该接口
+type Classifier interface {
+	// Name 分类器名称，出现在命中原因中
+	Name() string
+	// Classify 判断内容是否可疑，可疑时返回命中原因
+	Classify(ctx context.Context, content Content) (suspicious bool, reason string, err error)
+}
+
+// Verdict 过滤结果
+type Verdict struct {
+	Suspicious bool     // 是否可疑，可疑的内容进入待审核状态
+	Reasons    []string // 命中的原因，格式为 "分类器名称: 原因"
+}
+
+// Reason 返回合并后的命中原因，用于保存到数据库
+func (v Verdict) Reason() string {
+	reason := []rune(strings.Join(v.Reasons, "; "))
+	if len(reason) > maxReasonLength {
+		reason = reason[:maxReasonLength]
+	}
+	return string(reason)
+}
+
+var (
+	mu       sync.RWMutex
+	enabled  bool
+	builtins []Classifier
+	custom   []Classifier
+)
+
+// Init 根据配置初始化内置规则
+// 通过 Register 注册的自定义分类器不受影响
+func Init(cfg *config.FilterConfig) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = cfg.Enabled
+	builtins = []Classifier{
+		LinkLimit{MaxPostLinks: cfg.MaxPostLinks, MaxCommentLinks: cfg.MaxCommentLinks},
+		NewBlocklist(cfg.BlockedWords),
+	}
+	if cfg.DuplicateWindow > 0 {
+		builtins = append(builtins, Duplicate{Window: cfg.DuplicateWindow})
+	}
+	if cfg.NewAccountAge > 0 {
+		builtins = append(builtins, NewAccountThrottle{Age: cfg.NewAccountAge, MaxPerHour: cfg.NewAccountMaxPerHour})
+	}
+}
+
+// Register 注册自定义分类器，在内置规则之后执行
+func Register(classifier Classifier) {
+	mu.Lock()
+	defer mu.Unlock()
+	custom = append(custom, classifier)
+}
+
+// Check 依次执行所有分类器并汇总结果
+// 单个分类器出错时只记录日志并跳过，不影响内容发布
+func Check(ctx context.Context, content Content) Verdict {
+	mu.RLock()
+	classifiers := make([]Classifier, 0, len(builtins)+len(custom))
+	if enabled {
+		classifiers = append(classifiers, builtins...)
+		classifiers = append(classifiers, custom...)
+	}
+	mu.RUnlock()
+
+	var verdict Verdict
+	for _, classifier := range classifiers {
+		suspicious, reason, err := classifier.Classify(ctx, content)
+		if err != nil {
+			log.Printf("Blog filter error: %s: %v", classifier.Name(), err)
+			continue
+		}
+		if suspicious {
+			verdict.Suspicious = true
+			verdict.Reasons = append(verdict.Reasons, classifier.Name()+": "+reason)
+		}
+	}
+	return verdict
+}
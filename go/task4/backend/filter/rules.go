@@ -0,0 +1,138 @@
+package filter
+
+import (
+	"blog/database"
+	"blog/models"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// linkPattern 匹配正文中的链接（http/https 和 www. 开头的地址）
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.`)
+
+// LinkLimit 链接数量限制
+// 文章和评论分别限制，0 表示不允许出现链接
+type LinkLimit struct {
+	MaxPostLinks    int
+	MaxCommentLinks int
+}
+
+// Name 分类器名称
+func (LinkLimit) Name() string {
+	return "links"
+}
+
+// Classify 链接数超过限制时判定为可疑
+func (r LinkLimit) Classify(ctx context.Context, content Content) (bool, string, error) {
+	limit := r.MaxCommentLinks
+	if content.Type == TypePost {
+		limit = r.MaxPostLinks
+	}
+	count := len(linkPattern.FindAllStringIndex(content.Title+"\n"+content.Body, -1))
+	if count > limit {
+		return true, fmt.Sprintf("%d links (max %d)", count, limit), nil
+	}
+	return false, "", nil
+}
+
+// Blocklist 屏蔽词过滤，不区分大小写
+type Blocklist struct {
+	words []string
+}
+
+// NewBlocklist 创建屏蔽词过滤，忽略空白词
+func NewBlocklist(words []string) Blocklist {
+	var b Blocklist
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			b.words = append(b.words, word)
+		}
+	}
+	return b
+}
+
+// Name 分类器名称
+func (Blocklist) Name() string {
+	return "blocklist"
+}
+
+// Classify 标题或正文包含屏蔽词时判定为可疑
+func (b Blocklist) Classify(ctx context.Context, content Content) (bool, string, error) {
+	text := strings.ToLower(content.Title + "\n" + content.Body)
+	for _, word := range b.words {
+		if strings.Contains(text, word) {
+			return true, fmt.Sprintf("contains %q", word), nil
+		}
+	}
+	return false, "", nil
+}
+
+// Duplicate 重复内容检测
+// Window 时间内已有相同正文的同类内容（任意作者）时判定为可疑，用于发现刷屏和多账号群发
+type Duplicate struct {
+	Window time.Duration
+}
+
+// Name 分类器名称
+func (Duplicate) Name() string {
+	return "duplicate"
+}
+
+// Classify 查询时间窗口内是否存在相同正文
+func (r Duplicate) Classify(ctx context.Context, content Content) (bool, string, error) {
+	var model interface{} = &models.Comment{}
+	if content.Type == TypePost {
+		model = &models.Post{}
+	}
+	var count int64
+	err := database.DB.WithContext(ctx).Model(model).
+		Where("content = ? AND created_at > ?", content.Body, time.Now().Add(-r.Window)).
+		Count(&count).Error
+	if err != nil {
+		return false, "", err
+	}
+	if count > 0 {
+		return true, fmt.Sprintf("same content posted %d times in %s", count, r.Window), nil
+	}
+	return false, "", nil
+}
+
+// NewAccountThrottle 新账号发布频率限制
+// 注册不满 Age 的账号，最近一小时内发布的文章和评论总数达到 MaxPerHour 后的内容判定为可疑
+type NewAccountThrottle struct {
+	Age        time.Duration
+	MaxPerHour int
+}
+
+// Name 分类器名称
+func (NewAccountThrottle) Name() string {
+	return "new_account"
+}
+
+// Classify 统计新账号最近一小时的发布数量
+func (r NewAccountThrottle) Classify(ctx context.Context, content Content) (bool, string, error) {
+	db := database.DB.WithContext(ctx)
+	var user models.User
+	if err := db.Select("id", "created_at").First(&user, content.UserID).Error; err != nil {
+		return false, "", err
+	}
+	if time.Since(user.CreatedAt) >= r.Age {
+		return false, "", nil
+	}
+
+	since := time.Now().Add(-time.Hour)
+	var posts, comments int64
+	if err := db.Model(&models.Post{}).Where("user_id = ? AND created_at > ?", content.UserID, since).Count(&posts).Error; err != nil {
+		return false, "", err
+	}
+	if err := db.Model(&models.Comment{}).Where("user_id = ? AND created_at > ?", content.UserID, since).Count(&comments).Error; err != nil {
+		return false, "", err
+	}
+	if posts+comments >= int64(r.MaxPerHour) {
+		return true, fmt.Sprintf("account younger than %s published %d items in the last hour", r.Age, posts+comments), nil
+	}
+	return false, "", nil
+}
@@ -0,0 +1,129 @@
+package gql
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/models"
+	"blog/service"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// request GraphQL 请求
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler 返回 /graphql 接口的处理函数
+// 支持 POST（JSON 请求体）和 GET（查询参数，只允许查询操作）；携带 Authorization 头时校验 Token，
+// 校验失败直接返回 401 / 403。响应使用 GraphQL 标准结构 {data, errors}，而不是 REST 接口的统一响应结构
+func Handler() gin.HandlerFunc {
+	schema, err := Schema()
+	if err != nil {
+		log.Fatal("Blog GraphQL schema error: ", err)
+	}
+	cfg := config.LoadConfig().GraphQL
+
+	return func(c *gin.Context) {
+		var req request
+		if c.Request.Method == http.MethodGet {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+			if variables := c.Query("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					respondErrors(c, http.StatusBadRequest, "variables must be a JSON object")
+					return
+				}
+			}
+		} else if err := c.ShouldBindJSON(&req); err != nil {
+			respondErrors(c, http.StatusBadRequest, "request body must be a JSON object")
+			return
+		}
+		if req.Query == "" {
+			respondErrors(c, http.StatusBadRequest, "query must not be empty")
+			return
+		}
+
+		ctx := audit.RequestContext(c)
+		var viewer *models.User
+		var token *models.PersonalAccessToken
+		if authorization := c.GetHeader("Authorization"); authorization != "" {
+			var err error
+			viewer, token, err = service.Authenticate(ctx, authorization)
+			if err != nil {
+				code, message := service.Status(err)
+				respondErrors(c, code, message)
+				return
+			}
+			ctx = withViewer(ctx, viewer)
+		}
+
+		doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+			Body: []byte(req.Query),
+			Name: "GraphQL request",
+		})})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if result := graphql.ValidateDocument(&schema, doc, nil); !result.IsValid {
+			c.JSON(http.StatusBadRequest, &graphql.Result{Errors: result.Errors})
+			return
+		}
+		if c.Request.Method == http.MethodGet && isMutation(doc, req.OperationName) {
+			// 变更操作只允许 POST，避免通过链接或图片触发
+			respondErrors(c, http.StatusMethodNotAllowed, "mutations must use POST")
+			return
+		}
+		// 个人访问令牌只能执行查询（需要 read 权限），变更操作请使用 REST 接口
+		if token != nil && (isMutation(doc, req.OperationName) || !token.HasScope(models.ScopeRead)) {
+			code, message := service.Status(service.DenyScope(ctx, viewer, token))
+			respondErrors(c, code, message)
+			return
+		}
+		if err := checkLimits(analyze(&schema, doc, req.OperationName, req.Variables), cfg.MaxDepth, cfg.MaxComplexity); err != nil {
+			respondErrors(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+			Context:       withLoaders(ctx),
+		})
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// isMutation 判断要执行的操作是否为变更操作
+func isMutation(doc *ast.Document, operationName string) bool {
+	for _, def := range doc.Definitions {
+		operation, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || (operation.Name != nil && operation.Name.Value == operationName) {
+			return operation.Operation == ast.OperationTypeMutation
+		}
+	}
+	return false
+}
+
+// respondErrors 返回只包含错误信息的 GraphQL 响应
+func respondErrors(c *gin.Context, status int, message string) {
+	c.JSON(status, &graphql.Result{Errors: []gqlerrors.FormattedError{
+		{Message: message, Extensions: map[string]interface{}{"code": status}},
+	}})
+}
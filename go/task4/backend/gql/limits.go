@@ -0,0 +1,160 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListSize 列表字段未指定 first / pageSize 时估算的返回条数
+const defaultListSize = 10
+
+// analysis 查询的深度和复杂度
+type analysis struct {
+	Depth      int
+	Complexity int
+}
+
+// analyzer 在执行前遍历查询语法树，计算深度和复杂度
+// 每个字段计 1，列表字段的子字段复杂度按返回条数（first / pageSize 参数）放大；内省字段（__ 开头）不计入
+type analyzer struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// analyze 计算文档中指定操作的深度和复杂度，operationName 为空时计算第一个操作
+func analyze(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) analysis {
+	a := &analyzer{
+		schema:    schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operation != nil {
+				continue
+			}
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return analysis{}
+	}
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	return a.selectionSet(root, operation.SelectionSet)
+}
+
+// selectionSet 计算选择集的深度和复杂度，片段展开不增加深度
+func (a *analyzer) selectionSet(parent *graphql.Object, set *ast.SelectionSet) analysis {
+	var result analysis
+	if set == nil || parent == nil {
+		return result
+	}
+	for _, selection := range set.Selections {
+		var child analysis
+		switch selection := selection.(type) {
+		case *ast.Field:
+			child = a.field(parent, selection)
+		case *ast.InlineFragment:
+			child = a.selectionSet(a.typeCondition(parent, selection.TypeCondition), selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if fragment, ok := a.fragments[selection.Name.Value]; ok {
+				child = a.selectionSet(a.typeCondition(parent, fragment.TypeCondition), fragment.SelectionSet)
+			}
+		}
+		result.Complexity += child.Complexity
+		result.Depth = max(result.Depth, child.Depth)
+	}
+	return result
+}
+
+// field 计算单个字段的深度和复杂度
+func (a *analyzer) field(parent *graphql.Object, field *ast.Field) analysis {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return analysis{}
+	}
+	def, ok := parent.Fields()[name]
+	if !ok {
+		return analysis{}
+	}
+	object, _ := graphql.GetNamed(def.Type).(*graphql.Object)
+	children := a.selectionSet(object, field.SelectionSet)
+	multiplier := 1
+	if isList(def.Type) {
+		multiplier = a.listSize(field)
+	}
+	return analysis{
+		Depth:      children.Depth + 1,
+		Complexity: 1 + multiplier*children.Complexity,
+	}
+}
+
+// listSize 列表字段的估算返回条数
+func (a *analyzer) listSize(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" && arg.Name.Value != "pageSize" {
+			continue
+		}
+		if n, ok := a.intValue(arg.Value); ok && n > 0 {
+			return min(n, maxListSize)
+		}
+	}
+	return defaultListSize
+}
+
+// intValue 解析整数参数，支持字面量和变量
+func (a *analyzer) intValue(value ast.Value) (int, bool) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := a.variables[value.Name.Value].(type) {
+		case int:
+			return n, true
+		case float64:
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// typeCondition 返回片段的类型，未指定时沿用父类型
+func (a *analyzer) typeCondition(parent *graphql.Object, condition *ast.Named) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	object, _ := a.schema.Type(condition.Name.Value).(*graphql.Object)
+	return object
+}
+
+// isList 判断字段类型是否为列表（忽略非空修饰）
+func isList(t graphql.Type) bool {
+	_, ok := graphql.GetNullable(t).(*graphql.List)
+	return ok
+}
+
+// checkLimits 校验查询是否超过深度和复杂度限制，limit 为 0 表示不限制
+func checkLimits(result analysis, maxDepth, maxComplexity int) error {
+	if maxDepth > 0 && result.Depth > maxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", result.Depth, maxDepth)
+	}
+	if maxComplexity > 0 && result.Complexity > maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", result.Complexity, maxComplexity)
+	}
+	return nil
+}
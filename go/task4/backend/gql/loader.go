@@ -0,0 +1,199 @@
+package gql
+
+import (
+	"blog/database"
+	"blog/models"
+	"context"
+	"sync"
+)
+
+// Loader 批量加载器
+// 解析函数通过 Load 登记要加载的键并返回 thunk；graphql-go 按广度优先执行 thunk，
+// 同一层级的第一个 thunk 执行时一次性加载所有已登记的键，把 N 次查询合并为 1 次
+type Loader[K comparable, V any] struct {
+	batch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]V
+	errs    map[K]error
+}
+
+// NewLoader 创建批量加载器，batch 返回的结果中缺少的键视为不存在
+func NewLoader[K comparable, V any](batch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:   batch,
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// Load 登记要加载的键，返回供解析函数直接返回的 thunk
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.loaded(key) && !l.isPending(key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !l.loaded(key) {
+			l.dispatch(ctx)
+		}
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		value, ok := l.results[key]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	}
+}
+
+// loaded 键是否已加载（成功或失败）
+func (l *Loader[K, V]) loaded(key K) bool {
+	_, ok := l.results[key]
+	_, failed := l.errs[key]
+	return ok || failed
+}
+
+// isPending 键是否已登记
+func (l *Loader[K, V]) isPending(key K) bool {
+	for _, k := range l.pending {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch 加载所有已登记的键，调用方需持有锁
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	results, err := l.batch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		if value, ok := results[key]; ok {
+			l.results[key] = value
+		} else {
+			// 不存在的键也记为已加载，避免重复查询
+			l.errs[key] = nil
+		}
+	}
+}
+
+// loaders 单次请求内使用的加载器，按请求创建，避免跨请求缓存过期数据
+type loaders struct {
+	users          *Loader[uint, *models.User]
+	posts          *Loader[uint, *models.Post]
+	postsByUser    *Loader[listKey, []models.Post]
+	commentsByPost *Loader[listKey, []models.Comment]
+	commentsByUser *Loader[listKey, []models.Comment]
+}
+
+// listKey 关联列表加载器的键：所属用户或文章的ID，以及最多返回的条数
+type listKey struct {
+	id    uint
+	first int
+}
+
+// newLoaders 创建加载器，只加载已发布的文章和评论，与 REST 公开接口一致
+func newLoaders() *loaders {
+	return &loaders{
+		users: NewLoader(func(ctx context.Context, ids []uint) (map[uint]*models.User, error) {
+			var users []models.User
+			if err := database.ReadDB(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+				return nil, err
+			}
+			result := make(map[uint]*models.User, len(users))
+			for i := range users {
+				result[users[i].ID] = &users[i]
+			}
+			return result, nil
+		}),
+		posts: NewLoader(func(ctx context.Context, ids []uint) (map[uint]*models.Post, error) {
+			var posts []models.Post
+			err := database.ReadDB(ctx).Where("id IN ? AND status = ?", ids, models.StatusPublished).Find(&posts).Error
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[uint]*models.Post, len(posts))
+			for i := range posts {
+				result[posts[i].ID] = &posts[i]
+			}
+			return result, nil
+		}),
+		postsByUser: NewLoader(func(ctx context.Context, keys []listKey) (map[listKey][]models.Post, error) {
+			return loadLatest(ctx, keys, (&models.Post{}).TableName(), "user_id", func(p models.Post) uint { return p.UserID })
+		}),
+		commentsByPost: NewLoader(func(ctx context.Context, keys []listKey) (map[listKey][]models.Comment, error) {
+			return loadLatest(ctx, keys, (&models.Comment{}).TableName(), "post_id", func(c models.Comment) uint { return c.PostID })
+		}),
+		commentsByUser: NewLoader(func(ctx context.Context, keys []listKey) (map[listKey][]models.Comment, error) {
+			return loadLatest(ctx, keys, (&models.Comment{}).TableName(), "user_id", func(c models.Comment) uint { return c.UserID })
+		}),
+	}
+}
+
+// loadLatest 按 column 分组加载已发布的文章或评论，每组按创建时间倒序最多返回 first 条
+// 用 ROW_NUMBER() 在数据库中限制每组的条数，避免加载用户或文章的全部内容；同一批中 first 不同时按最大值查询，再按各自的 first 截取
+func loadLatest[T any](ctx context.Context, keys []listKey, table, column string, keyOf func(T) uint) (map[listKey][]T, error) {
+	ids := make([]uint, 0, len(keys))
+	limit := 0
+	for _, key := range keys {
+		ids = append(ids, key.id)
+		limit = max(limit, key.first)
+	}
+	db := database.ReadDB(ctx)
+	ranked := db.Model(new(T)).
+		Select(table+".*, ROW_NUMBER() OVER (PARTITION BY "+column+" ORDER BY created_at DESC, id DESC) AS row_num").
+		Where(column+" IN ? AND status = ?", ids, models.StatusPublished)
+	var items []T
+	err := db.Table("(?) AS ranked", ranked).Where("row_num <= ?", limit).
+		Order("created_at DESC, id DESC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	grouped := groupBy(ids, items, keyOf)
+	result := make(map[listKey][]T, len(keys))
+	for _, key := range keys {
+		list := grouped[key.id]
+		if len(list) > key.first {
+			list = list[:key.first]
+		}
+		result[key] = list
+	}
+	return result, nil
+}
+
+// groupBy 按键分组，没有数据的键对应空列表
+func groupBy[T any](keys []uint, items []T, key func(T) uint) map[uint][]T {
+	result := make(map[uint][]T, len(keys))
+	for _, k := range keys {
+		result[k] = []T{}
+	}
+	for _, item := range items {
+		result[key(item)] = append(result[key(item)], item)
+	}
+	return result
+}
+
+// loadersKey context 中加载器的键
+type loadersKey struct{}
+
+// withLoaders 在 ctx 中放入本次请求的加载器
+func withLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, newLoaders())
+}
+
+// loadersFrom 返回 ctx 中的加载器
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
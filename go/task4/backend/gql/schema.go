@@ -0,0 +1,517 @@
+package gql
+
+import (
+	"blog/models"
+	"blog/service"
+	"blog/utils"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+)
+
+// maxListSize 列表字段 first / pageSize 参数的最大值，与 REST 接口的分页上限一致
+const maxListSize = 50
+
+// Schema 构建 GraphQL Schema
+// 类型与 models 中的关联关系对应：User.posts / User.comments、Post.author / Post.comments、Comment.author / Comment.post；
+// 只公开已发布的文章和评论，变更操作调用 service 包，与 REST、gRPC 接口共用验证规则
+func Schema() (graphql.Schema, error) {
+	var userType, postType, commentType *graphql.Object
+
+	// first 参数：关联列表返回的最大条数
+	firstArg := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{
+			Type:         graphql.Int,
+			DefaultValue: defaultListSize,
+			Description:  "返回的最大条数，最大 50",
+		},
+	}
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "用户",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        field(graphql.NewNonNull(graphql.ID), func(u *models.User) interface{} { return u.ID }),
+				"name":      field(graphql.NewNonNull(graphql.String), func(u *models.User) interface{} { return u.Name }),
+				"email":     field(graphql.NewNonNull(graphql.String), func(u *models.User) interface{} { return u.Email }),
+				"role":      field(graphql.NewNonNull(graphql.String), func(u *models.User) interface{} { return u.Role }),
+				"createdAt": field(graphql.NewNonNull(graphql.DateTime), func(u *models.User) interface{} { return u.CreatedAt }),
+				"posts": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(postType))),
+					Description: "已发布的文章，按创建时间倒序",
+					Args:        firstArg,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						thunk := loadersFrom(p.Context).postsByUser.Load(p.Context, listKey{p.Source.(*models.User).ID, first(p.Args)})
+						return listOf(thunk, pointers[models.Post]), nil
+					},
+				},
+				"comments": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType))),
+					Description: "已发布的评论，按创建时间倒序",
+					Args:        firstArg,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						thunk := loadersFrom(p.Context).commentsByUser.Load(p.Context, listKey{p.Source.(*models.User).ID, first(p.Args)})
+						return listOf(thunk, pointers[models.Comment]), nil
+					},
+				},
+			}
+		}),
+	})
+
+	postType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Post",
+		Description: "文章",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        field(graphql.NewNonNull(graphql.ID), func(p *models.Post) interface{} { return p.ID }),
+				"title":     field(graphql.NewNonNull(graphql.String), func(p *models.Post) interface{} { return p.Title }),
+				"content":   field(graphql.NewNonNull(graphql.String), func(p *models.Post) interface{} { return p.Content }),
+				"status":    field(graphql.NewNonNull(graphql.String), func(p *models.Post) interface{} { return p.Status }),
+				"version":   field(graphql.NewNonNull(graphql.Int), func(p *models.Post) interface{} { return p.Version }),
+				"createdAt": field(graphql.NewNonNull(graphql.DateTime), func(p *models.Post) interface{} { return p.CreatedAt }),
+				"updatedAt": field(graphql.NewNonNull(graphql.DateTime), func(p *models.Post) interface{} { return p.UpdatedAt }),
+				"author": {
+					Type:        userType,
+					Description: "作者",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						post := p.Source.(*models.Post)
+						if post.User != nil {
+							return post.User, nil
+						}
+						return loadersFrom(p.Context).users.Load(p.Context, post.UserID), nil
+					},
+				},
+				"comments": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType))),
+					Description: "已发布的评论，按创建时间倒序",
+					Args:        firstArg,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						thunk := loadersFrom(p.Context).commentsByPost.Load(p.Context, listKey{p.Source.(*models.Post).ID, first(p.Args)})
+						return listOf(thunk, pointers[models.Comment]), nil
+					},
+				},
+			}
+		}),
+	})
+
+	commentType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Comment",
+		Description: "评论",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        field(graphql.NewNonNull(graphql.ID), func(c *models.Comment) interface{} { return c.ID }),
+				"content":   field(graphql.NewNonNull(graphql.String), func(c *models.Comment) interface{} { return c.Content }),
+				"status":    field(graphql.NewNonNull(graphql.String), func(c *models.Comment) interface{} { return c.Status }),
+				"version":   field(graphql.NewNonNull(graphql.Int), func(c *models.Comment) interface{} { return c.Version }),
+				"createdAt": field(graphql.NewNonNull(graphql.DateTime), func(c *models.Comment) interface{} { return c.CreatedAt }),
+				"updatedAt": field(graphql.NewNonNull(graphql.DateTime), func(c *models.Comment) interface{} { return c.UpdatedAt }),
+				"author": {
+					Type:        userType,
+					Description: "作者",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						comment := p.Source.(*models.Comment)
+						if comment.User != nil {
+							return comment.User, nil
+						}
+						return loadersFrom(p.Context).users.Load(p.Context, comment.UserID), nil
+					},
+				},
+				"post": {
+					Type:        postType,
+					Description: "所属文章，未公开时为 null",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						comment := p.Source.(*models.Comment)
+						return loadersFrom(p.Context).posts.Load(p.Context, comment.PostID), nil
+					},
+				},
+			}
+		}),
+	})
+
+	authPayloadType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AuthPayload",
+		Description: "登录结果；启用了两步验证的用户只返回挑战令牌，需调用 verifyTwoFactor 完成登录",
+		Fields: graphql.Fields{
+			"token":                  &graphql.Field{Type: graphql.String, Description: "JWT Token，需要两步验证时为 null"},
+			"user":                   &graphql.Field{Type: userType, Description: "登录的用户，需要两步验证时为 null"},
+			"twoFactorRequired":      &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "账号已启用两步验证"},
+			"challengeToken":         &graphql.Field{Type: graphql.String, Description: "两步验证挑战令牌"},
+			"challengeExpiresAt":     &graphql.Field{Type: graphql.DateTime, Description: "挑战令牌过期时间"},
+			"twoFactorSetupRequired": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "角色要求两步验证而用户尚未启用"},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:        userType,
+				Description: "当前登录用户，未登录时为 null",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					viewer := viewerFrom(p.Context)
+					if viewer == nil {
+						return nil, nil
+					}
+					return loadersFrom(p.Context).users.Load(p.Context, viewer.ID), nil
+				},
+			},
+			"user": &graphql.Field{
+				Type:        userType,
+				Description: "按ID查询用户，不存在时为 null",
+				Args:        graphql.FieldConfigArgument{"id": idArg()},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					return loadersFrom(p.Context).users.Load(p.Context, id), nil
+				},
+			},
+			"post": &graphql.Field{
+				Type:        postType,
+				Description: "按ID查询已发布的文章，不存在时为 null",
+				Args:        graphql.FieldConfigArgument{"id": idArg()},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					return loadersFrom(p.Context).posts.Load(p.Context, id), nil
+				},
+			},
+			"posts": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(postType))),
+				Description: "已发布的文章列表，按创建时间倒序分页",
+				Args: graphql.FieldConfigArgument{
+					"page":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1, Description: "页码"},
+					"pageSize": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultListSize, Description: "每页条数，最大 50"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					posts, _, err := service.ListPosts(p.Context, p.Args["page"].(int), p.Args["pageSize"].(int))
+					if err != nil {
+						return nil, toError(err)
+					}
+					return pointers(posts), nil
+				},
+			},
+			"postCount": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "已发布的文章总数，用于分页",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					_, pagination, err := service.ListPosts(p.Context, 1, 1)
+					if err != nil {
+						return nil, toError(err)
+					}
+					return pagination.Total, nil
+				},
+			},
+			"comments": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType))),
+				Description: "已发布文章的评论列表，按创建时间倒序",
+				Args:        graphql.FieldConfigArgument{"postId": idArg()},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					postId, err := parseID(p.Args["postId"])
+					if err != nil {
+						return nil, err
+					}
+					comments, err := service.ListComments(p.Context, postId)
+					if err != nil {
+						return nil, toError(err)
+					}
+					return pointers(comments), nil
+				},
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"register": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "用户注册",
+				Args: graphql.FieldConfigArgument{
+					"name":     stringArg(),
+					"email":    stringArg(),
+					"password": stringArg(),
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					user, err := service.Register(p.Context, service.RegisterInput{
+						Name:     p.Args["name"].(string),
+						Email:    p.Args["email"].(string),
+						Password: p.Args["password"].(string),
+					})
+					if err != nil {
+						return nil, toError(err)
+					}
+					return user, nil
+				},
+			},
+			"login": &graphql.Field{
+				Type:        graphql.NewNonNull(authPayloadType),
+				Description: "用户登录，返回 JWT Token；启用了两步验证时返回挑战令牌",
+				Args: graphql.FieldConfigArgument{
+					"name":     stringArg(),
+					"password": stringArg(),
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					token, user, err := service.Login(p.Context, p.Args["name"].(string), p.Args["password"].(string))
+					var challenge *service.TwoFactorRequiredError
+					if errors.As(err, &challenge) {
+						return map[string]interface{}{
+							"twoFactorRequired":      true,
+							"challengeToken":         challenge.ChallengeToken,
+							"challengeExpiresAt":     challenge.ExpiresAt,
+							"twoFactorSetupRequired": false,
+						}, nil
+					}
+					if err != nil {
+						return nil, toError(err)
+					}
+					return authPayload(token, user), nil
+				},
+			},
+			"verifyTwoFactor": &graphql.Field{
+				Type:        graphql.NewNonNull(authPayloadType),
+				Description: "提交 login 返回的挑战令牌和验证码（或恢复码）完成两步验证登录",
+				Args: graphql.FieldConfigArgument{
+					"challengeToken": stringArg(),
+					"code":           stringArg(),
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					token, user, err := service.VerifyTwoFactorLogin(p.Context, p.Args["challengeToken"].(string), p.Args["code"].(string))
+					if err != nil {
+						return nil, toError(err)
+					}
+					return authPayload(token, user), nil
+				},
+			},
+			"createPost": &graphql.Field{
+				Type:        graphql.NewNonNull(postType),
+				Description: "创建文章，被判定为可疑的文章 status 为 pending",
+				Args: graphql.FieldConfigArgument{
+					"title":   stringArg(),
+					"content": stringArg(),
+				},
+				Resolve: authenticated(func(p graphql.ResolveParams, viewer *models.User) (interface{}, error) {
+					return service.CreatePost(p.Context, viewer.ID, service.PostInput{
+						Title:   p.Args["title"].(string),
+						Content: p.Args["content"].(string),
+					})
+				}),
+			},
+			"updatePost": &graphql.Field{
+				Type:        graphql.NewNonNull(postType),
+				Description: "更新文章，只有作者可以更新；version 与当前版本号不一致时返回 409",
+				Args: graphql.FieldConfigArgument{
+					"id":      idArg(),
+					"title":   stringArg(),
+					"content": stringArg(),
+					"version": versionArg(),
+				},
+				Resolve: authenticated(func(p graphql.ResolveParams, viewer *models.User) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					version := uint(p.Args["version"].(int))
+					return service.UpdatePost(p.Context, viewer.ID, id, service.PostInput{
+						Title:   p.Args["title"].(string),
+						Content: p.Args["content"].(string),
+					}, &version)
+				}),
+			},
+			"deletePost": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "删除文章，只有作者可以删除；文章及其评论进入回收站",
+				Args:        graphql.FieldConfigArgument{"id": idArg()},
+				Resolve: authenticated(func(p graphql.ResolveParams, viewer *models.User) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					if err := service.DeletePost(p.Context, viewer.ID, id); err != nil {
+						return nil, err
+					}
+					return true, nil
+				}),
+			},
+			"createComment": &graphql.Field{
+				Type:        graphql.NewNonNull(commentType),
+				Description: "对已发布的文章发表评论，被判定为可疑的评论 status 为 pending",
+				Args: graphql.FieldConfigArgument{
+					"postId":  idArg(),
+					"content": stringArg(),
+				},
+				Resolve: authenticated(func(p graphql.ResolveParams, viewer *models.User) (interface{}, error) {
+					postId, err := parseID(p.Args["postId"])
+					if err != nil {
+						return nil, err
+					}
+					return service.CreateComment(p.Context, viewer.ID, postId, p.Args["content"].(string))
+				}),
+			},
+			"updateComment": &graphql.Field{
+				Type:        graphql.NewNonNull(commentType),
+				Description: "编辑评论，只有作者可以编辑；version 与当前版本号不一致时返回 409",
+				Args: graphql.FieldConfigArgument{
+					"id":      idArg(),
+					"content": stringArg(),
+					"version": versionArg(),
+				},
+				Resolve: authenticated(func(p graphql.ResolveParams, viewer *models.User) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					version := uint(p.Args["version"].(int))
+					return service.UpdateComment(p.Context, viewer.ID, id, p.Args["content"].(string), &version)
+				}),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+}
+
+// field 读取模型字段的解析函数
+func field[T any](t graphql.Output, get func(T) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(T)), nil
+		},
+	}
+}
+
+// authenticated 要求已登录的解析函数，未登录时返回 401；错误统一转换为带状态码的 GraphQL 错误
+func authenticated(resolve func(p graphql.ResolveParams, viewer *models.User) (interface{}, error)) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		viewer := viewerFrom(p.Context)
+		if viewer == nil {
+			return nil, toError(&service.Error{Code: utils.CodeUnauthorized, Message: utils.MsgUnauthorized})
+		}
+		result, err := resolve(p, viewer)
+		if err != nil {
+			return nil, toError(err)
+		}
+		return result, nil
+	}
+}
+
+// listOf 转换关联列表加载结果的元素类型，条数已在加载时按 first 限制
+func listOf[T any](thunk func() (interface{}, error), convert func([]T) interface{}) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		value, err := thunk()
+		if err != nil || value == nil {
+			return []interface{}{}, toError(err)
+		}
+		return convert(value.([]T)), nil
+	}
+}
+
+// first 读取 first 参数，限制在 1~50 之间
+func first(args map[string]interface{}) int {
+	n, _ := args["first"].(int)
+	if n < 1 {
+		return defaultListSize
+	}
+	return min(n, maxListSize)
+}
+
+// pointers 把模型切片转换为指针切片，字段解析函数统一使用指针
+func pointers[T any](items []T) interface{} {
+	result := make([]*T, len(items))
+	for i := range items {
+		result[i] = &items[i]
+	}
+	return result
+}
+
+// idArg 必填的 ID 参数
+func idArg() *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+}
+
+// stringArg 必填的字符串参数
+func stringArg() *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}
+}
+
+// versionArg 必填的乐观锁版本号参数
+func versionArg() *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int), Description: "期望的当前版本号"}
+}
+
+// parseID 解析 ID 参数
+func parseID(value interface{}) (uint, error) {
+	id, err := strconv.ParseUint(value.(string), 10, 64)
+	if err != nil {
+		return 0, toError(&service.Error{Code: utils.CodeBadRequest, Message: utils.MsgBadRequest})
+	}
+	return uint(id), nil
+}
+
+// Error 带扩展信息的 GraphQL 错误
+// extensions.code 为与 REST 接口一致的状态码，版本冲突时 extensions.current_version 为当前版本号
+type Error struct {
+	Message string
+	Ext     map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions 实现 gqlerrors.ExtendedError，输出到响应的 errors[].extensions
+func (e *Error) Extensions() map[string]interface{} {
+	return e.Ext
+}
+
+// authPayload 登录成功的结果
+func authPayload(token string, user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"token":                  token,
+		"user":                   user,
+		"twoFactorRequired":      false,
+		"twoFactorSetupRequired": user.TOTPEnabledAt == nil && service.TwoFactorRequired(user.Role),
+	}
+}
+
+// toError 把 service 错误转换为 GraphQL 错误，err 为 nil 时返回 nil
+func toError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	code, message := service.Status(err)
+	ext := map[string]interface{}{"code": code}
+	var conflict *service.VersionConflictError
+	if errors.As(err, &conflict) {
+		ext["current_version"] = conflict.Current
+	}
+	return &Error{Message: message, Ext: ext}
+}
+
+// viewerKey context 中当前用户的键
+type viewerKey struct{}
+
+// withViewer 在 ctx 中放入当前用户，未登录时为 nil
+func withViewer(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, viewerKey{}, user)
+}
+
+// viewerFrom 返回当前用户，未登录时为 nil
+func viewerFrom(ctx context.Context) *models.User {
+	user, _ := ctx.Value(viewerKey{}).(*models.User)
+	return user
+}
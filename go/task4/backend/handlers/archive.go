@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"blog/archive"
+	"blog/audit"
+	"blog/database"
+	"blog/middleware"
+	"blog/utils"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportContent 导出全部文章
+// 仅管理员可用，把所有文章及评论以带 YAML Front Matter 的 Markdown 文件打包为 zip 流式输出，可用 `blog import` 导入
+func ExportContent(c *gin.Context) {
+	// 1. 从上下文获取当前用户ID（通过中间件）
+	userId, _ := middleware.GetUserFromContext(c)
+	// 2. 边查询边输出 zip
+	filename := "blog-export-" + time.Now().Format("20060102-150405") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(utils.CodeSuccess)
+	count, err := archive.Export(c.Request.Context(), database.DB, c.Writer)
+	if err != nil {
+		// 响应已经开始输出，无法再返回错误响应，中止连接让客户端感知导出不完整
+		log.Println("Blog export error: ", err)
+		panic(http.ErrAbortHandler)
+	}
+	// 3. 记录审计日志
+	audit.Record(c, audit.Entry{
+		ActorID: userId,
+		Action:  audit.ActionContentExport,
+		After:   map[string]int{"posts": count},
+	})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/database"
+	"blog/models"
+	"blog/utils"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAuditLogs 查询审计日志
+// 仅管理员可用，支持按操作者、操作类型和时间范围过滤，按时间倒序分页返回
+func GetAuditLogs(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	query, err := auditLogQuery(c, db)
+	if err != nil {
+		utils.Error(c, utils.CodeBadRequest, err.Error())
+		return
+	}
+	var pageReq struct {
+		Page     int `form:"page"`
+		PageSize int `form:"page_size"`
+	}
+	_ = c.ShouldBindQuery(&pageReq)
+
+	page := pageReq.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := pageReq.PageSize
+	if pageSize < 1 {
+		pageSize = 20 // 默认每页20条
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 最大每页100条
+	}
+
+	var total int64
+	query.Count(&total)
+	var logs []models.AuditLog
+	result := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs)
+	if result.Error != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	utils.Success(c, gin.H{
+		"logs": logs,
+		"pagination": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (int(total) + pageSize - 1) / pageSize,
+		},
+	})
+}
+
+// ExportAuditLogs 导出审计日志
+// 仅管理员可用，过滤条件与 GetAuditLogs 相同，以 JSON Lines 格式按时间正序流式输出
+func ExportAuditLogs(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	query, err := auditLogQuery(c, db)
+	if err != nil {
+		utils.Error(c, utils.CodeBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit-log.jsonl"`)
+	c.Status(utils.CodeSuccess)
+	encoder := json.NewEncoder(c.Writer)
+	var logs []models.AuditLog
+	// 分批读取，避免一次性加载全部日志
+	result := query.Order("id ASC").FindInBatches(&logs, 500, func(tx *gorm.DB, batch int) error {
+		for _, entry := range logs {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if result.Error != nil {
+		// 响应已经开始输出，无法再返回错误响应，中止连接让客户端感知导出不完整
+		log.Println("Blog audit export error: ", result.Error)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// auditLogQuery 根据查询参数构建审计日志查询
+// 支持 actor_id、action 和 RFC 3339 格式的 from / to 时间范围
+func auditLogQuery(c *gin.Context, db *gorm.DB) (*gorm.DB, error) {
+	query := db.Model(&models.AuditLog{})
+	if value := c.Query("actor_id"); value != "" {
+		actorID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, errors.New("actor_id 格式错误")
+		}
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New("from 必须是 RFC 3339 格式的时间")
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New("to 必须是 RFC 3339 格式的时间")
+		}
+		query = query.Where("created_at < ?", to)
+	}
+	return query, nil
+}
+
+// denyNotOwner 非作者操作资源时记录审计日志并返回 403
+func denyNotOwner(c *gin.Context, userId uint, targetType string, targetID uint) {
+	audit.Record(c, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionForbidden,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Detail:     "not owner: " + c.Request.Method + " " + c.Request.URL.Path,
+	})
+	utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+}
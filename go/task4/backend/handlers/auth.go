@@ -1,55 +1,15 @@
 package handlers
 
 import (
-	"blog/database"
+	"blog/audit"
 	"blog/models"
+	"blog/service"
 	"blog/utils"
-	"regexp"
-	"strings"
+	"errors"
 
 	"github.com/gin-gonic/gin"
 )
 
-// validateRegisterInput 验证注册输入
-// 验证用户名、邮箱格式和密码长度
-func validateRegisterInput(name, email, password string) string {
-	// 验证用户名
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return "用户名不能为空"
-	}
-	if len(name) < 3 {
-		return "用户名长度至少3个字符"
-	}
-	if len(name) > 20 {
-		return "用户名长度不能超过20个字符"
-	}
-
-	// 验证邮箱
-	email = strings.TrimSpace(email)
-	if email == "" {
-		return "邮箱不能为空"
-	}
-	// 邮箱格式验证正则表达式
-	emailRegex := regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
-	if !emailRegex.MatchString(email) {
-		return "邮箱格式不正确"
-	}
-
-	// 验证密码
-	if password == "" {
-		return "密码不能为空"
-	}
-	if len(password) < 6 {
-		return "密码长度至少6位"
-	}
-	if len(password) > 100 {
-		return "密码长度不能超过100位"
-	}
-
-	return "" // 返回空字符串表示验证通过
-}
-
 // Register 用户注册
 // 处理用户注册请求：验证输入、加密密码、创建用户
 func Register(c *gin.Context) {
@@ -65,36 +25,14 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// 2. 验证输入（用户名、邮箱、密码）
-	if errMsg := validateRegisterInput(registerReq.Name, registerReq.Email, registerReq.Password); errMsg != "" {
-		utils.Error(c, utils.CodeBadRequest, errMsg)
-		return
-	}
-
-	// 3. 检查用户名和邮箱是否已存在
-	var count int64
-	database.DB.Model(&models.User{}).
-		Where("name = ? ", registerReq.Name).Count(&count)
-	if count > 0 {
-		utils.Error(c, utils.CodeConflict, utils.MsgUsernameExists)
-		return
-	}
-	database.DB.Model(&models.User{}).
-		Where("email = ? ", registerReq.Email).Count(&count)
-	if count > 0 {
-		utils.Error(c, utils.CodeConflict, utils.MsgEmailExists)
-		return
-	}
-
-	//  4. 创建用户记录（密码加密由 User 模型的 BeforeCreate 钩子自动处理）
-	user := models.User{
+	// 2. 验证输入、检查用户名和邮箱是否已存在并创建用户
+	user, err := service.Register(audit.RequestContext(c), service.RegisterInput{
 		Name:     registerReq.Name,
 		Email:    registerReq.Email,
 		Password: registerReq.Password,
-	}
-	err := database.DB.Create(&user).Error
+	})
 	if err != nil {
-		utils.Error(c, utils.CodeInternalError, err.Error())
+		respondError(c, err)
 		return
 	}
 	utils.Success(c, map[string]interface{}{
@@ -105,7 +43,8 @@ func Register(c *gin.Context) {
 }
 
 // Login 用户登录
-// 处理用户登录请求：验证用户名密码、生成JWT Token
+// 处理用户登录请求：验证用户名密码、生成JWT Token；
+// 启用了两步验证的用户返回挑战令牌（two_factor_required: true），需再调用 LoginTwoFactor 提交验证码
 func Login(c *gin.Context) {
 	//  登录逻辑
 	// 1. 解析请求体（用户名、密码）
@@ -118,31 +57,61 @@ func Login(c *gin.Context) {
 		utils.Error(c, utils.CodeBadRequest, err.Error())
 		return
 	}
-	// 2. 查询用户
-	var existUser models.User
-	result := database.DB.Model(&models.User{}).Where("name = ? ", loginReq.Name).First(&existUser)
-	if result.Error != nil {
-		utils.Error(c, utils.CodeUnauthorized, utils.MsgLoginFailed)
+	// 2. 验证用户名密码并生成JWT Token
+	token, user, err := service.Login(audit.RequestContext(c), loginReq.Name, loginReq.Password)
+	var challenge *service.TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		utils.Success(c, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge.ChallengeToken,
+			"expires_at":          challenge.ExpiresAt,
+		})
 		return
 	}
-	// 3. 验证密码
-	if !utils.CheckPassword(loginReq.Password, existUser.Password) {
-		utils.Error(c, utils.CodeUnauthorized, utils.MsgLoginFailed)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	// 4. 生成JWT Token
-	token, err := utils.GenerateToken(existUser.ID)
+	// 3. 返回Token和用户信息
+	respondLogin(c, token, user)
+}
+
+// LoginTwoFactor 两步验证登录
+// 提交登录返回的挑战令牌和身份验证器中的验证码（或恢复码），验证通过后返回与 Login 相同的Token和用户信息
+func LoginTwoFactor(c *gin.Context) {
+	// 1. 解析请求体（挑战令牌、验证码）
+	var verifyReq struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&verifyReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	// 2. 校验挑战令牌和验证码并生成JWT Token
+	token, user, err := service.VerifyTwoFactorLogin(audit.RequestContext(c), verifyReq.ChallengeToken, verifyReq.Code)
 	if err != nil {
-		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		respondError(c, err)
 		return
 	}
-	// 5. 返回Token和用户信息
-	utils.Success(c, map[string]interface{}{
+	// 3. 返回Token和用户信息
+	respondLogin(c, token, user)
+}
+
+// respondLogin 返回登录成功的Token和用户信息
+// 角色要求两步验证而用户尚未启用时返回 two_factor_setup_required，提示前端引导用户启用
+func respondLogin(c *gin.Context, token string, user *models.User) {
+	data := map[string]interface{}{
 		"token": token,
 		"user": map[string]interface{}{
-			"id":    existUser.ID,
-			"name":  existUser.Name,
-			"email": existUser.Email,
+			"id":    user.ID,
+			"name":  user.Name,
+			"email": user.Email,
+			"role":  user.Role,
 		},
-	})
+	}
+	if user.TOTPEnabledAt == nil && service.TwoFactorRequired(user.Role) {
+		data["two_factor_setup_required"] = true
+	}
+	utils.Success(c, data)
 }
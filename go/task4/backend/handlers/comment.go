@@ -1,11 +1,10 @@
 package handlers
 
 import (
-	"blog/database"
+	"blog/audit"
 	"blog/middleware"
-	"blog/models"
+	"blog/service"
 	"blog/utils"
-	"sort"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -36,28 +35,17 @@ func CreateComment(c *gin.Context) {
 		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
 		return
 	}
-	// 3. 验证文章是否存在
-	var count int64
-	database.DB.Model(&models.Post{}).Where("id = ? ", postId).Count(&count)
-	if count == 0 {
-		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
-		return
-	}
-	// 4. 验证输入
-	// 5. 创建评论记录
-	comment := &models.Comment{
-		Content: commentReq.Content,
-		UserID:  userId,
-		PostID:  uint(postId),
-	}
-	result := database.DB.Create(&comment)
-	// 6. 返回响应
-	if result.Error != nil {
-		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+	// 3. 验证文章是否存在、垃圾内容过滤并创建评论记录
+	comment, err := service.CreateComment(audit.RequestContext(c), userId, uint(postId), commentReq.Content)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
+	// 4. 返回响应
 	utils.Success(c, gin.H{
-		"msg": utils.MsgSuccess,
+		"msg":        utils.MsgSuccess,
+		"comment_id": comment.ID,
+		"status":     comment.Status,
 	})
 }
 
@@ -66,34 +54,69 @@ func CreateComment(c *gin.Context) {
 func GetCommentsByPost(c *gin.Context) {
 	// 获取评论列表逻辑
 	// 1. 获取URL参数中的文章ID
-	postId := c.Param("post_id")
-	if postId == "" {
+	postIdStr := c.Param("post_id")
+	if postIdStr == "" {
 		utils.Error(c, utils.CodeBadRequest, utils.MsgPostNotFound)
 		return
 	}
-
-	// 2. 验证文章是否存在
-	var existPost models.Post
-	err := database.DB.Where("id = ?", postId).First(&existPost).Error
+	postId, err := strconv.ParseUint(postIdStr, 10, 64)
 	if err != nil {
 		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
 		return
 	}
-	// 3. 查询该文章的所有评论（关联用户信息）
-	var comments []models.Comment
-	err = database.DB.Where("post_id = ?", postId).Preload("User").Order("created_at DESC ").Find(&comments).Error
+	// 2. 查询该文章的所有评论（关联用户信息，按时间倒序）
+	comments, err := service.ListComments(c.Request.Context(), uint(postId))
 	if err != nil {
-		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		respondError(c, err)
 		return
 	}
-
-	// 4. 可选：排序（按时间）
-	sort.Slice(comments, func(i, j int) bool {
-		return comments[i].CreatedAt.After(comments[j].CreatedAt)
-	})
-	// 5. 返回评论列表
+	// 3. 返回评论列表
 	utils.Success(c, gin.H{
 		"comments": comments,
 		"count":    len(comments),
 	})
 }
+
+// UpdateComment 编辑评论
+// 只有评论的作者才能编辑自己的评论（需认证+作者权限），使用版本号防止并发覆盖
+func UpdateComment(c *gin.Context) {
+	// 1. 获取评论ID
+	var getReq struct {
+		ID uint `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&getReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, utils.MsgCommentNotFound)
+		return
+	}
+	// 2. 从上下文获取当前用户ID
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	// 3. 解析请求体和期望的版本号
+	var updateReq struct {
+		Content string `json:"content" binding:"required"`
+		Version *uint  `json:"version"` // 乐观锁版本号，也可通过 If-Match 请求头提供
+	}
+	if err := c.ShouldBindJSON(&updateReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	version, fromHeader, ok := expectedVersion(c, updateReq.Version)
+	if !ok {
+		return
+	}
+	// 4. 验证作者权限和输入，只有版本号未变化时才会更新成功
+	comment, err := service.UpdateComment(audit.RequestContext(c), userId, getReq.ID, updateReq.Content, version)
+	if err != nil {
+		respondUpdateError(c, err, fromHeader)
+		return
+	}
+	// 5. 返回响应，ETag 为新版本号
+	c.Header("ETag", middleware.VersionETag(comment.Version))
+	utils.Success(c, gin.H{
+		"msg":     utils.MsgSuccess,
+		"version": comment.Version,
+	})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"blog/config"
+	"blog/database"
+	"blog/feed"
+	"blog/models"
+	"blog/utils"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SiteFeed 站点订阅源
+// format 为 rss、atom 或 json，包含最新发布的 feed.max_items 篇文章
+func SiteFeed(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := database.ReadDB(c.Request.Context())
+		posts, err := latestPosts(db)
+		if err != nil {
+			log.Println("Blog feed error: ", err)
+			utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+			return
+		}
+		writeFeed(c, format, feed.New(feedSite(), posts, nil))
+	}
+}
+
+// AuthorFeed 作者订阅源
+// format 为 rss、atom 或 json，包含该作者最新发布的 feed.max_items 篇文章
+func AuthorFeed(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := database.ReadDB(c.Request.Context())
+		// 1. 查询作者
+		var uriReq struct {
+			ID uint `uri:"id" binding:"required"`
+		}
+		if err := c.ShouldBindUri(&uriReq); err != nil {
+			utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+			return
+		}
+		var author models.User
+		if err := db.Select("id", "name").First(&author, uriReq.ID).Error; err != nil {
+			utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+			return
+		}
+		// 2. 查询该作者最新发布的文章
+		posts, err := latestPosts(db.Where("user_id = ?", author.ID))
+		if err != nil {
+			log.Println("Blog feed error: ", err)
+			utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+			return
+		}
+		writeFeed(c, format, feed.New(feedSite(), posts, &author))
+	}
+}
+
+// Sitemap 站点地图
+// 包含首页和所有已发布的文章及其最后修改时间
+func Sitemap(c *gin.Context) {
+	db := database.ReadDB(c.Request.Context())
+	var posts []models.Post
+	err := db.Select("id", "updated_at").
+		Where("status = ?", models.StatusPublished).
+		Order("id DESC").
+		Limit(feed.MaxSitemapURLs - 1). // 首页占用一个地址
+		Find(&posts).Error
+	if err != nil {
+		log.Println("Blog sitemap error: ", err)
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	data, err := feed.Sitemap(feedSite(), posts)
+	if err != nil {
+		log.Println("Blog sitemap error: ", err)
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	c.Data(http.StatusOK, feed.ContentTypeSitemap, data)
+}
+
+// latestPosts 查询最新发布的文章（关联作者信息），数量由 feed.max_items 决定
+func latestPosts(db *gorm.DB) ([]models.Post, error) {
+	var posts []models.Post
+	err := db.Where("status = ?", models.StatusPublished).
+		Preload("User", func(tx *gorm.DB) *gorm.DB { return tx.Select("id", "name") }).
+		Order("created_at DESC, id DESC").
+		Limit(config.LoadConfig().Feed.MaxItems).
+		Find(&posts).Error
+	return posts, err
+}
+
+// writeFeed 按格式输出订阅源，并以最近一篇文章的更新时间作为 Last-Modified
+func writeFeed(c *gin.Context, format string, f *feed.Feed) {
+	var (
+		data        []byte
+		contentType string
+		err         error
+	)
+	switch format {
+	case "rss":
+		data, err = f.RSS()
+		contentType = feed.ContentTypeRSS
+	case "atom":
+		data, err = f.Atom()
+		contentType = feed.ContentTypeAtom
+	default:
+		data, err = f.JSON()
+		contentType = feed.ContentTypeJSON
+	}
+	if err != nil {
+		log.Println("Blog feed error: ", err)
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	c.Header("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// feedSite 返回订阅源使用的站点信息
+// 绝对链接只使用配置的地址，不信任请求的 Host 头，避免缓存的订阅源被注入其他域名
+func feedSite() feed.Site {
+	cfg := config.LoadConfig()
+	baseURL := cfg.Feed.BaseURL
+	if baseURL == "" {
+		host := cfg.Server.Host
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		baseURL = "http://" + net.JoinHostPort(host, cfg.Server.Port)
+	}
+	return feed.Site{
+		Title:       cfg.Feed.Title,
+		Description: cfg.Feed.Description,
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+	}
+}
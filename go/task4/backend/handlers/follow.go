@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"blog/middleware"
+	"blog/service"
+	"blog/utils"
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userUri URL 中的用户ID
+type userUri struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+// GetUserProfile 获取用户的公开资料
+// 公开接口，返回用户名、注册时间、粉丝数和关注数
+func GetUserProfile(c *gin.Context) {
+	var uriReq userUri
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, "用户不存在")
+		return
+	}
+	profile, err := service.GetProfile(c.Request.Context(), uriReq.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"user": profile,
+	})
+}
+
+// FollowUser 关注用户
+// 需认证，不能关注自己，重复关注不报错；返回被关注用户的最新资料
+func FollowUser(c *gin.Context) {
+	changeFollow(c, service.Follow)
+}
+
+// UnfollowUser 取消关注用户
+// 需认证，未关注时不报错；返回被取消关注用户的最新资料
+func UnfollowUser(c *gin.Context) {
+	changeFollow(c, service.Unfollow)
+}
+
+// changeFollow 关注或取消关注 URL 中的用户
+func changeFollow(c *gin.Context, change func(ctx context.Context, userId, authorId uint) (*service.Profile, error)) {
+	// 1. 获取URL参数中的用户ID
+	var uriReq userUri
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, "用户不存在")
+		return
+	}
+	// 2. 从上下文获取当前用户ID（通过中间件）
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	// 3. 修改关注关系
+	profile, err := change(c.Request.Context(), userId, uriReq.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// 4. 返回最新的用户资料
+	utils.Success(c, gin.H{
+		"user": profile,
+	})
+}
+
+// GetFollowers 获取用户的粉丝
+// 公开接口，按关注时间倒序分页
+func GetFollowers(c *gin.Context) {
+	listFollows(c, service.ListFollowers)
+}
+
+// GetFollowing 获取用户关注的作者
+// 公开接口，按关注时间倒序分页
+func GetFollowing(c *gin.Context) {
+	listFollows(c, service.ListFollowing)
+}
+
+// listFollows 分页返回 URL 中用户的粉丝或关注的作者
+func listFollows(c *gin.Context, list func(ctx context.Context, userId uint, page, pageSize int) ([]service.FollowUser, service.Pagination, error)) {
+	var uriReq userUri
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, "用户不存在")
+		return
+	}
+	var pageReq struct {
+		Page     int `form:"page"`
+		PageSize int `form:"page_size"`
+	}
+	_ = c.ShouldBindQuery(&pageReq)
+
+	users, pagination, err := list(c.Request.Context(), uriReq.ID, pageReq.Page, pageReq.PageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"users":      users,
+		"pagination": paginationResponse(pagination),
+	})
+}
+
+// GetHomeFeed 获取首页动态
+// 需认证，返回关注的作者发布的文章，使用游标分页：下一页请求带上响应中的 next_cursor，next_cursor 为空表示没有更多文章
+func GetHomeFeed(c *gin.Context) {
+	// 1. 从上下文获取当前用户ID（通过中间件）
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	// 2. 解析游标参数
+	var feedReq struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit"`
+	}
+	_ = c.ShouldBindQuery(&feedReq)
+
+	// 3. 查询关注的作者的文章
+	posts, nextCursor, err := service.HomeFeed(c.Request.Context(), userId, feedReq.Cursor, feedReq.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"posts":       posts,
+		"next_cursor": nextCursor,
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"blog/middleware"
+	"blog/utils"
+	"blog/web"
+	"errors"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeFrontend 返回前端页面和静态资源
+// 注册为 NoRoute，处理未匹配其他路由的 GET/HEAD 请求；不带扩展名的路径视为前端路由，返回 index.html。
+// /api 下的请求和其他方法仍返回统一的 404 响应
+func ServeFrontend(c *gin.Context) {
+	// 1. 只处理前端资源请求
+	urlPath := c.Request.URL.Path
+	if web.Default == nil || (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) ||
+		urlPath == "/api" || strings.HasPrefix(urlPath, "/api/") {
+		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+		return
+	}
+	// 2. 查找文件，不存在且没有扩展名时回退到 index.html
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		name = web.IndexFile
+	}
+	asset, err := web.Default.Open(name)
+	if errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "" {
+		asset, err = web.Default.Open(web.IndexFile)
+	}
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Println("Blog frontend error: ", err)
+		}
+		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+		return
+	}
+	// 3. 按 Accept-Encoding 选择预压缩版本，设置缓存相关响应头
+	body, encoding, etag := asset.Variant(c.GetHeader("Accept-Encoding"))
+	c.Header("Content-Type", asset.ContentType)
+	c.Header("Cache-Control", web.Default.CacheControl(asset.Name))
+	c.Header("ETag", etag)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if asset.Compressed() {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+	}
+	if encoding != "" {
+		c.Header("Content-Encoding", encoding)
+	}
+	// 4. ETag 未变化时返回 304，否则返回内容（HEAD 请求只返回响应头）
+	if middleware.ETagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Header("Content-Length", strconv.Itoa(len(body)))
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	if c.Request.Method == http.MethodGet {
+		c.Writer.Write(body)
+	}
+}
@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/database"
+	"blog/middleware"
+	"blog/models"
+	"blog/service"
+	"blog/utils"
+	"blog/webhook"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 版主处理举报的动作
+const (
+	moderationHide    = "hide"    // 隐藏内容
+	moderationDelete  = "delete"  // 删除内容（文章连同评论进入作者的回收站，恢复后仍保持隐藏）
+	moderationDismiss = "dismiss" // 驳回举报或通过待审核的内容，内容恢复公开
+	moderationBan     = "ban"     // 封禁作者并隐藏内容
+)
+
+// moderationActions 处理动作对应的审计操作类型
+var moderationActions = map[string]string{
+	moderationHide:    audit.ActionModerateHide,
+	moderationDelete:  audit.ActionModerateDelete,
+	moderationDismiss: audit.ActionModerateDismiss,
+	moderationBan:     audit.ActionModerateBan,
+}
+
+// moderationQueueItem 审核队列中的一项，按被举报的内容聚合
+type moderationQueueItem struct {
+	TargetType      string         `json:"target_type"`
+	TargetID        uint           `json:"target_id"`
+	ReportCount     int64          `json:"report_count"`
+	Reasons         map[string]int `json:"reasons"` // 各举报原因的次数
+	FirstReportedAt time.Time      `json:"first_reported_at"`
+	LastReportedAt  time.Time      `json:"last_reported_at"`
+	Target          gin.H          `json:"target"` // 被举报内容的摘要，内容已被彻底删除时为空
+}
+
+// GetModerationQueue 获取审核队列
+// 版主和管理员可用，按被举报的内容聚合待处理的举报，举报数多的排在前面
+func GetModerationQueue(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	var queueReq struct {
+		TargetType string `form:"target_type"`
+		Page       int    `form:"page"`
+		PageSize   int    `form:"page_size"`
+	}
+	_ = c.ShouldBindQuery(&queueReq)
+
+	page := queueReq.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := queueReq.PageSize
+	if pageSize < 1 {
+		pageSize = 20 // 默认每页20条
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 最大每页100条
+	}
+
+	// 1. 按内容聚合待处理的举报
+	grouped := db.Model(&models.Report{}).
+		Select("target_type, target_id, COUNT(*) AS report_count, MAX(id) AS last_report_id").
+		Where("status = ?", models.ReportOpen).
+		Group("target_type, target_id")
+	if queueReq.TargetType != "" {
+		grouped = grouped.Where("target_type = ?", queueReq.TargetType)
+	}
+	var total int64
+	if err := db.Table("(?) AS queue", grouped).Count(&total).Error; err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	var rows []struct {
+		TargetType  string
+		TargetID    uint
+		ReportCount int64
+	}
+	err := grouped.Order("report_count DESC, last_report_id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&rows).Error
+	if err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+
+	// 2. 加载本页内容的举报明细和内容摘要
+	items := make([]*moderationQueueItem, 0, len(rows))
+	byTarget := make(map[string]map[uint]*moderationQueueItem)
+	ids := make(map[string][]uint)
+	for _, row := range rows {
+		item := &moderationQueueItem{
+			TargetType:  row.TargetType,
+			TargetID:    row.TargetID,
+			ReportCount: row.ReportCount,
+			Reasons:     make(map[string]int),
+		}
+		items = append(items, item)
+		if byTarget[row.TargetType] == nil {
+			byTarget[row.TargetType] = make(map[uint]*moderationQueueItem)
+		}
+		byTarget[row.TargetType][row.TargetID] = item
+		ids[row.TargetType] = append(ids[row.TargetType], row.TargetID)
+	}
+	for targetType, targetIDs := range ids {
+		var reports []models.Report
+		err := db.Where("target_type = ? AND target_id IN ? AND status = ?", targetType, targetIDs, models.ReportOpen).
+			Find(&reports).Error
+		if err != nil {
+			utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+			return
+		}
+		for _, report := range reports {
+			item := byTarget[targetType][report.TargetID]
+			item.Reasons[report.Reason]++
+			if item.FirstReportedAt.IsZero() || report.CreatedAt.Before(item.FirstReportedAt) {
+				item.FirstReportedAt = report.CreatedAt
+			}
+			if report.CreatedAt.After(item.LastReportedAt) {
+				item.LastReportedAt = report.CreatedAt
+			}
+		}
+		if err := loadQueueTargets(db, targetType, targetIDs, byTarget[targetType]); err != nil {
+			utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+			return
+		}
+	}
+
+	utils.Success(c, gin.H{
+		"items": items,
+		"pagination": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (int(total) + pageSize - 1) / pageSize,
+		},
+	})
+}
+
+// loadQueueTargets 加载审核队列中被举报内容的摘要（包括作者已删除的内容）
+func loadQueueTargets(db *gorm.DB, targetType string, ids []uint, items map[uint]*moderationQueueItem) error {
+	author := func(tx *gorm.DB) *gorm.DB { return tx.Select("id", "name", "banned_at") }
+	switch targetType {
+	case audit.TargetPost:
+		var posts []models.Post
+		if err := db.Unscoped().Preload("User", author).Where("id IN ?", ids).Find(&posts).Error; err != nil {
+			return err
+		}
+		for _, post := range posts {
+			items[post.ID].Target = gin.H{
+				"title":   post.Title,
+				"content": post.Content,
+				"status":  post.Status,
+				"deleted": post.DeletedAt.Valid,
+				"author":  authorSummary(post.User),
+			}
+		}
+	case audit.TargetComment:
+		var comments []models.Comment
+		if err := db.Unscoped().Preload("User", author).Where("id IN ?", ids).Find(&comments).Error; err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			items[comment.ID].Target = gin.H{
+				"post_id": comment.PostID,
+				"content": comment.Content,
+				"status":  comment.Status,
+				"deleted": comment.DeletedAt.Valid,
+				"author":  authorSummary(comment.User),
+			}
+		}
+	}
+	return nil
+}
+
+// authorSummary 被举报内容作者的摘要
+func authorSummary(user *models.User) gin.H {
+	if user == nil {
+		return nil
+	}
+	return gin.H{
+		"id":     user.ID,
+		"name":   user.Name,
+		"banned": user.BannedAt != nil,
+	}
+}
+
+// GetPendingContent 获取待审核的内容
+// 版主和管理员可用，列出被垃圾内容过滤判定为可疑的文章或评论（target_type 默认为 post），按时间正序分页返回
+// 审核通过使用 dismiss 动作，其余动作与处理举报相同
+func GetPendingContent(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	var pendingReq struct {
+		TargetType string `form:"target_type"`
+		Page       int    `form:"page"`
+		PageSize   int    `form:"page_size"`
+	}
+	_ = c.ShouldBindQuery(&pendingReq)
+	if pendingReq.TargetType == "" {
+		pendingReq.TargetType = audit.TargetPost
+	}
+	if pendingReq.TargetType != audit.TargetPost && pendingReq.TargetType != audit.TargetComment {
+		utils.Error(c, utils.CodeBadRequest, "内容类型必须是 post 或 comment")
+		return
+	}
+
+	page := pendingReq.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := pendingReq.PageSize
+	if pageSize < 1 {
+		pageSize = 20 // 默认每页20条
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 最大每页100条
+	}
+
+	query := db.Model(reportTargetModel(pendingReq.TargetType)).Where("status = ?", models.StatusPending)
+	var total int64
+	query.Count(&total)
+	query = query.Preload("User").Order("id ASC").Offset((page - 1) * pageSize).Limit(pageSize)
+
+	var items interface{}
+	var err error
+	if pendingReq.TargetType == audit.TargetPost {
+		var posts []models.Post
+		err = query.Find(&posts).Error
+		items = posts
+	} else {
+		var comments []models.Comment
+		err = query.Find(&comments).Error
+		items = comments
+	}
+	if err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	utils.Success(c, gin.H{
+		"items": items,
+		"pagination": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (int(total) + pageSize - 1) / pageSize,
+		},
+	})
+}
+
+// GetReports 获取举报明细
+// 版主和管理员可用，支持按内容和状态过滤，按时间倒序分页返回
+func GetReports(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	var reportsReq struct {
+		TargetType string `form:"target_type"`
+		TargetID   uint   `form:"target_id"`
+		Status     string `form:"status"`
+		Page       int    `form:"page"`
+		PageSize   int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&reportsReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+
+	page := reportsReq.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := reportsReq.PageSize
+	if pageSize < 1 {
+		pageSize = 20 // 默认每页20条
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 最大每页100条
+	}
+
+	query := db.Model(&models.Report{})
+	if reportsReq.TargetType != "" {
+		query = query.Where("target_type = ?", reportsReq.TargetType)
+	}
+	if reportsReq.TargetID != 0 {
+		query = query.Where("target_id = ?", reportsReq.TargetID)
+	}
+	if reportsReq.Status != "" {
+		query = query.Where("status = ?", reportsReq.Status)
+	}
+	var total int64
+	query.Count(&total)
+	var reports []models.Report
+	result := query.Preload("Reporter", func(tx *gorm.DB) *gorm.DB { return tx.Select("id", "name") }).
+		Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&reports)
+	if result.Error != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	utils.Success(c, gin.H{
+		"reports": reports,
+		"pagination": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (int(total) + pageSize - 1) / pageSize,
+		},
+	})
+}
+
+// ModerateTarget 处理被举报的内容
+// 版主和管理员可用，执行隐藏、删除、驳回或封禁作者，并关闭该内容所有待处理的举报
+func ModerateTarget(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	// 1. 解析并验证请求体
+	var actionReq struct {
+		TargetType string `json:"target_type" binding:"required"`
+		TargetID   uint   `json:"target_id" binding:"required"`
+		Action     string `json:"action" binding:"required"`
+		Note       string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&actionReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	if actionReq.TargetType != audit.TargetPost && actionReq.TargetType != audit.TargetComment {
+		utils.Error(c, utils.CodeBadRequest, "处理对象类型必须是 post 或 comment")
+		return
+	}
+	auditAction, ok := moderationActions[actionReq.Action]
+	if !ok {
+		utils.Error(c, utils.CodeBadRequest, "处理动作必须是 hide、delete、dismiss 或 ban")
+		return
+	}
+
+	// 2. 查询被举报的内容（驳回时允许内容已被作者删除）
+	query := db
+	if actionReq.Action == moderationDismiss {
+		query = db.Unscoped()
+	}
+	target, err := findReportTarget(query, actionReq.TargetType, actionReq.TargetID)
+	if err != nil {
+		utils.Error(c, utils.CodeNotFound, targetNotFoundMsg(actionReq.TargetType))
+		return
+	}
+	var author models.User
+	if actionReq.Action == moderationBan {
+		if err := db.Select("id", "name", "role").First(&author, target.authorID).Error; err != nil {
+			utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+			return
+		}
+		if author.Role != models.RoleUser {
+			utils.Error(c, utils.CodeForbidden, "不能封禁版主或管理员")
+			return
+		}
+	}
+
+	// 3. 执行处理动作并关闭举报
+	newStatus := models.StatusHidden
+	reportStatus := models.ReportResolved
+	if actionReq.Action == moderationDismiss {
+		newStatus = models.StatusPublished
+		reportStatus = models.ReportDismissed
+	}
+	var resolved int64
+	err = database.Transaction(db, func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(reportTargetModel(actionReq.TargetType)).
+			Where("id = ?", actionReq.TargetID).
+			UpdateColumn("status", newStatus).Error
+		if err != nil {
+			return err
+		}
+		switch actionReq.Action {
+		case moderationDelete:
+			if target.post != nil {
+				err = service.SoftDeletePost(tx, target.post)
+			} else {
+				err = tx.Delete(target.comment).Error
+			}
+		case moderationBan:
+			err = tx.Model(&author).UpdateColumn("banned_at", time.Now()).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", actionReq.TargetType, actionReq.TargetID, models.ReportOpen).
+			Updates(map[string]interface{}{
+				"status":      reportStatus,
+				"resolution":  actionReq.Action,
+				"resolved_by": userId,
+				"resolved_at": now,
+			})
+		resolved = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	if actionReq.Action == moderationBan {
+		service.InvalidateUser(author.ID)
+	}
+	audit.Record(c, audit.Entry{
+		ActorID:    userId,
+		Action:     auditAction,
+		TargetType: actionReq.TargetType,
+		TargetID:   actionReq.TargetID,
+		Before:     gin.H{"status": target.status},
+		After:      gin.H{"status": newStatus},
+		Detail:     strings.TrimSpace(actionReq.Note),
+	})
+	// 4. 触发事件：只有已发布的内容对订阅方可见，审核通过待审核内容时才触发创建事件
+	switch {
+	case actionReq.Action == moderationDelete && target.post != nil && target.status == models.StatusPublished:
+		webhook.Emit(c.Request.Context(), webhook.EventPostDeleted, webhook.Post(target.post))
+	case actionReq.Action == moderationDismiss && target.status == models.StatusPending && !target.deleted():
+		if target.post != nil {
+			target.post.Status = models.StatusPublished
+			webhook.Emit(c.Request.Context(), webhook.EventPostCreated, webhook.Post(target.post))
+		} else {
+			target.comment.Status = models.StatusPublished
+			webhook.Emit(c.Request.Context(), webhook.EventCommentCreated, webhook.Comment(target.comment))
+		}
+	}
+	utils.Success(c, gin.H{
+		"msg":              utils.MsgSuccess,
+		"status":           newStatus,
+		"resolved_reports": resolved,
+	})
+}
+
+// UnbanUser 解除封禁
+// 版主和管理员可用
+func UnbanUser(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	var unbanReq struct {
+		ID uint `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&unbanReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+		return
+	}
+	var user models.User
+	if err := db.Select("id", "name", "banned_at").First(&user, unbanReq.ID).Error; err != nil {
+		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+		return
+	}
+	if user.BannedAt != nil {
+		if err := db.Model(&user).UpdateColumn("banned_at", nil).Error; err != nil {
+			utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+			return
+		}
+		service.InvalidateUser(user.ID)
+		audit.Record(c, audit.Entry{
+			ActorID:    userId,
+			Action:     audit.ActionUnban,
+			TargetType: audit.TargetUser,
+			TargetID:   user.ID,
+		})
+	}
+	utils.Success(c, gin.H{
+		"msg": utils.MsgSuccess,
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/service"
+	"blog/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcStateCookie = "oidc_state"     // 保存 state 的 Cookie，回调时与查询参数核对，防止登录 CSRF
+	oidcCookiePath  = "/api/auth/oidc" // Cookie 只发送给 OIDC 接口
+)
+
+// GetOIDC 获取 OIDC 登录配置
+// 公开接口，前端据此决定是否显示「使用 xxx 登录」按钮
+func GetOIDC(c *gin.Context) {
+	cfg := config.LoadConfig().OIDC
+	utils.Success(c, gin.H{
+		"enabled": cfg.Enabled,
+		"name":    cfg.Name,
+	})
+}
+
+// OIDCLogin 跳转到身份提供方登录
+// 浏览器直接访问该地址；出错时跳转到前端页面，错误信息放在 URL 片段中
+func OIDCLogin(c *gin.Context) {
+	cfg := config.LoadConfig().OIDC
+	authURL, state, err := service.StartOIDCLogin(audit.RequestContext(c))
+	if err != nil {
+		_, message := service.Status(err)
+		redirectOIDCResult(c, url.Values{"error": {message}})
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, int(cfg.StateTTL/time.Second), oidcCookiePath, "",
+		strings.HasPrefix(cfg.RedirectURL, "https://"), true)
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 身份提供方登录后的回调
+// 核对 state、完成登录后跳转到前端页面（oidc.frontend_redirect），结果放在 URL 片段中，不会出现在服务器日志里：
+// 成功时为 token 和 user，需要两步验证时为 two_factor_required、challenge_token 和 expires_at，失败时为 error
+func OIDCCallback(c *gin.Context) {
+	// 1. 核对 state 并清除 Cookie
+	state := c.Query("state")
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", false, true)
+	if providerErr := c.Query("error"); providerErr != "" {
+		redirectOIDCResult(c, url.Values{"error": {"身份提供方拒绝了登录：" + providerErr}})
+		return
+	}
+	if state == "" || cookie != state {
+		redirectOIDCResult(c, url.Values{"error": {"登录已失效，请重新登录"}})
+		return
+	}
+
+	// 2. 用授权码完成登录
+	token, user, err := service.FinishOIDCLogin(audit.RequestContext(c), state, c.Query("code"))
+	var challenge *service.TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		redirectOIDCResult(c, url.Values{
+			"two_factor_required": {"true"},
+			"challenge_token":     {challenge.ChallengeToken},
+			"expires_at":          {challenge.ExpiresAt.Format(time.RFC3339)},
+		})
+		return
+	}
+	if err != nil {
+		_, message := service.Status(err)
+		redirectOIDCResult(c, url.Values{"error": {message}})
+		return
+	}
+
+	// 3. 返回Token和用户信息
+	userInfo, _ := json.Marshal(map[string]interface{}{
+		"id":    user.ID,
+		"name":  user.Name,
+		"email": user.Email,
+		"role":  user.Role,
+	})
+	result := url.Values{
+		"token": {token},
+		"user":  {string(userInfo)},
+	}
+	if user.TOTPEnabledAt == nil && service.TwoFactorRequired(user.Role) {
+		result.Set("two_factor_setup_required", "true")
+	}
+	redirectOIDCResult(c, result)
+}
+
+// redirectOIDCResult 跳转到前端页面，登录结果放在 URL 片段中
+func redirectOIDCResult(c *gin.Context, result url.Values) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Redirect(http.StatusFound, config.LoadConfig().OIDC.FrontendRedirect+"#"+result.Encode())
+}
@@ -1,13 +1,11 @@
 package handlers
 
 import (
-	"blog/database"
+	"blog/audit"
 	"blog/middleware"
-	"blog/models"
+	"blog/service"
 	"blog/utils"
 
-	"strings"
-
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,60 +20,32 @@ func CreatePost(c *gin.Context) {
 		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
 		return
 	}
-	// 2. 解析请求体（标题、内容）
+	// 2. 解析请求体（标题、内容、附件）
 	var createPostReq struct {
-		Title   string `json:"title"  binding:"required"`
-		Content string `json:"content"   binding:"required"`
+		Title         string `json:"title"  binding:"required"`
+		Content       string `json:"content"   binding:"required"`
+		AttachmentIDs []uint `json:"attachment_ids"` // 通过上传接口上传的附件ID
 	}
 	err := c.BindJSON(&createPostReq)
 	if err != nil {
 		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
 		return
 	}
-	// 3. 验证输入
-	createPostReq.Title = strings.TrimSpace(createPostReq.Title)
-	createPostReq.Content = strings.TrimSpace(createPostReq.Content)
-
-	if createPostReq.Title == "" {
-		utils.Error(c, utils.CodeBadRequest, "标题不能为空")
-		return
-	}
-	if len(createPostReq.Title) < 2 {
-		utils.Error(c, utils.CodeBadRequest, "标题长度至少2个字符")
-		return
-	}
-	if len(createPostReq.Title) > 100 {
-		utils.Error(c, utils.CodeBadRequest, "标题长度不能超过100个字符")
-		return
-	}
-
-	if createPostReq.Content == "" {
-		utils.Error(c, utils.CodeBadRequest, "内容不能为空")
-		return
-	}
-	if len(createPostReq.Content) < 10 {
-		utils.Error(c, utils.CodeBadRequest, "内容长度至少10个字符")
-		return
-	}
-	if len(createPostReq.Content) > 10000 {
-		utils.Error(c, utils.CodeBadRequest, "内容长度不能超过10000个字符")
-		return
-	}
-	// 4. 创建文章记录
-	post := &models.Post{
-		UserID:  userId,
-		Title:   createPostReq.Title,
-		Content: createPostReq.Content,
-	}
-	result := database.DB.Create(&post)
-	if result.Error != nil {
-		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+	// 3. 验证输入、垃圾内容过滤并创建文章记录
+	post, err := service.CreatePost(audit.RequestContext(c), userId, service.PostInput{
+		Title:         createPostReq.Title,
+		Content:       createPostReq.Content,
+		AttachmentIDs: createPostReq.AttachmentIDs,
+	})
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	// 5. 返回响应
+	// 4. 返回响应
 	utils.Success(c, gin.H{
 		"post_id": post.ID,
-		"title":   createPostReq.Title,
+		"title":   post.Title,
+		"status":  post.Status,
 	})
 }
 
@@ -83,49 +53,23 @@ func CreatePost(c *gin.Context) {
 // 公开接口，返回所有文章
 func GetPosts(c *gin.Context) {
 	//  获取文章列表逻辑
-	// 1. 查询所有文章（关联用户信息）
+	// 1. 解析分页参数
 	var postReq struct {
 		Page     int `form:"page"`
 		PageSize int `form:"page_size"`
 	}
 	_ = c.ShouldBindQuery(&postReq)
 
-	page := postReq.Page
-	if page < 1 {
-		page = 1
-	}
-	pageSize := postReq.PageSize
-	if pageSize < 1 {
-		pageSize = 10 // 默认每页10条
-	}
-	if pageSize > 50 {
-		pageSize = 50 // 最大每页50条
-	}
-
-	offset := (page - 1) * pageSize
-	var total int64
-	database.DB.Model(&models.Post{}).Count(&total)
-	// 2. 可选：分页、排序
-	var posts []models.Post
-	result := database.DB.Preload("User").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&posts)
-
-	if result.Error != nil {
-		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+	// 2. 查询已发布的文章（关联用户信息）
+	posts, pagination, err := service.ListPosts(c.Request.Context(), postReq.Page, postReq.PageSize)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 	// 3. 返回文章列表
 	utils.Success(c, gin.H{
-		"posts": posts,
-		"pagination": gin.H{
-			"page":       page,
-			"page_size":  pageSize,
-			"total":      total,
-			"total_page": (int(total) + pageSize - 1) / pageSize,
-		},
+		"posts":      posts,
+		"pagination": paginationResponse(pagination),
 	})
 }
 
@@ -135,28 +79,21 @@ func GetPost(c *gin.Context) {
 	// 获取文章详情逻辑
 	// 1. 获取URL参数中的文章ID
 	var postReq struct {
-		ID int `uri:"id" binding:"required"`
+		ID uint `uri:"id" binding:"required"`
 	}
 	err := c.ShouldBindUri(&postReq)
 	if err != nil {
 		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
 		return
 	}
-	// 2. 检查文章是否存在
-	var count int64
-	database.DB.Model(&models.Post{}).Where("id = ? ", postReq.ID).Count(&count)
-	if count == 0 {
-		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
-		return
-	}
-	// 3. 查询文章（关联用户信息）
-	var post models.Post
-	result := database.DB.Preload("User").Where("id = ? ", postReq.ID).First(&post)
-	if result.Error != nil {
-		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
+	// 2. 查询文章（关联用户信息）
+	post, err := service.GetPost(c.Request.Context(), postReq.ID)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	// 4. 返回文章详情
+	// 3. 返回文章详情，ETag 中带有版本号，可直接用作更新时的 If-Match
+	middleware.SetETagVersion(c, post.Version)
 	utils.Success(c, gin.H{
 		"post": post,
 	})
@@ -168,7 +105,7 @@ func UpdatePost(c *gin.Context) {
 	// 更新文章逻辑
 	// 1. 获取文章ID
 	var getReq struct {
-		ID int `uri:"id" binding:"required"`
+		ID uint `uri:"id" binding:"required"`
 	}
 	err := c.ShouldBindUri(&getReq)
 	if err != nil {
@@ -181,74 +118,46 @@ func UpdatePost(c *gin.Context) {
 		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
 		return
 	}
-	// 3. 查询文章并验证是否为作者
-	var post models.Post
-	result := database.DB.Where("id = ? ", getReq.ID).First(&post)
-	if result.Error != nil {
-		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
-		return
-	}
-	if post.UserID != userId {
-		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
-		return
-	}
-
+	// 3. 解析请求体（标题、内容、附件）和期望的版本号
 	var updatePostReq struct {
-		Title   string `json:"title"  binding:"required"`
-		Content string `json:"content"   binding:"required"`
+		Title         string `json:"title"  binding:"required"`
+		Content       string `json:"content"   binding:"required"`
+		AttachmentIDs []uint `json:"attachment_ids"` // 省略时不修改附件，空数组解除所有附件
+		Version       *uint  `json:"version"`        // 乐观锁版本号，也可通过 If-Match 请求头提供
 	}
 	err = c.ShouldBindJSON(&updatePostReq)
 	if err != nil {
 		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
 		return
 	}
-
-	// 在解析请求体后添加验证
-	updatePostReq.Title = strings.TrimSpace(updatePostReq.Title)
-	updatePostReq.Content = strings.TrimSpace(updatePostReq.Content)
-
-	if updatePostReq.Title == "" {
-		utils.Error(c, utils.CodeBadRequest, "标题不能为空")
+	version, fromHeader, ok := expectedVersion(c, updatePostReq.Version)
+	if !ok {
 		return
 	}
-	if len(updatePostReq.Title) < 2 || len(updatePostReq.Title) > 100 {
-		utils.Error(c, utils.CodeBadRequest, "标题长度必须在2-100个字符之间")
-		return
-	}
-
-	if updatePostReq.Content == "" {
-		utils.Error(c, utils.CodeBadRequest, "内容不能为空")
-		return
-	}
-	if len(updatePostReq.Content) < 10 || len(updatePostReq.Content) > 10000 {
-		utils.Error(c, utils.CodeBadRequest, "内容长度必须在10-10000个字符之间")
-		return
-	}
-
-	// 4. 解析请求体（标题、内容）更新文章记录
-	result = database.DB.Model(&models.Post{}).
-		Where("id = ? ", post.ID).
-		Updates(map[string]interface{}{
-			"title":   updatePostReq.Title,
-			"content": updatePostReq.Content,
-		})
-	if result.Error != nil {
-		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+	// 4. 验证作者权限和输入，只有版本号未变化时才会更新成功
+	post, err := service.UpdatePost(audit.RequestContext(c), userId, getReq.ID, service.PostInput{
+		Title:         updatePostReq.Title,
+		Content:       updatePostReq.Content,
+		AttachmentIDs: updatePostReq.AttachmentIDs,
+	}, version)
+	if err != nil {
+		respondUpdateError(c, err, fromHeader)
 		return
 	}
-	// 5. 返回响应
+	// 5. 返回响应，ETag 为新版本号
+	c.Header("ETag", middleware.VersionETag(post.Version))
 	utils.Success(c, gin.H{
-		"msg": utils.MsgSuccess,
+		"msg":     utils.MsgSuccess,
+		"version": post.Version,
 	})
 }
 
 // DeletePost 删除文章
-// 只有文章的作者才能删除自己的文章（需认证+作者权限）
+// 只有文章的作者才能删除自己的文章（需认证+作者权限），删除后的文章和评论进入回收站
 func DeletePost(c *gin.Context) {
-	// TODO: 实现删除文章逻辑
 	// 1. 获取文章ID
 	var getReq struct {
-		ID int `uri:"id" binding:"required"`
+		ID uint `uri:"id" binding:"required"`
 	}
 	err := c.ShouldBindUri(&getReq)
 	if err != nil {
@@ -261,24 +170,12 @@ func DeletePost(c *gin.Context) {
 		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
 		return
 	}
-	// 3. 查询文章并验证是否为作者
-	var post models.Post
-	result := database.DB.Where("id = ? ", getReq.ID).First(&post)
-	if result.Error != nil {
-		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
-		return
-	}
-	if post.UserID != userId {
-		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
-		return
-	}
-	// 4. 删除文章记录
-	result = database.DB.Delete(&post)
-	// 5. 返回响应
-	if result.Error != nil {
-		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
+	// 3. 验证作者权限并软删除文章及其评论
+	if err := service.DeletePost(audit.RequestContext(c), userId, getReq.ID); err != nil {
+		respondError(c, err)
 		return
 	}
+	// 4. 返回响应
 	utils.Success(c, gin.H{
 		"msg": utils.MsgSuccess,
 	})
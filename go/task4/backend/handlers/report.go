@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/database"
+	"blog/middleware"
+	"blog/models"
+	"blog/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// reportReasons 允许的举报原因
+var reportReasons = map[string]bool{
+	"spam":    true, // 垃圾广告
+	"abuse":   true, // 辱骂、骚扰
+	"illegal": true, // 违法内容
+	"other":   true, // 其他
+}
+
+// CreateReport 举报文章或评论
+// 需认证，同一用户对同一内容只能有一条待处理的举报；不同用户的举报数达到阈值时自动隐藏该内容
+func CreateReport(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	// 1. 解析并验证请求体
+	var reportReq struct {
+		TargetType string `json:"target_type" binding:"required"`
+		TargetID   uint   `json:"target_id" binding:"required"`
+		Reason     string `json:"reason" binding:"required"`
+		Detail     string `json:"detail"`
+	}
+	if err := c.ShouldBindJSON(&reportReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	if reportReq.TargetType != audit.TargetPost && reportReq.TargetType != audit.TargetComment {
+		utils.Error(c, utils.CodeBadRequest, "举报对象类型必须是 post 或 comment")
+		return
+	}
+	if !reportReasons[reportReq.Reason] {
+		utils.Error(c, utils.CodeBadRequest, "举报原因必须是 spam、abuse、illegal 或 other")
+		return
+	}
+	reportReq.Detail = strings.TrimSpace(reportReq.Detail)
+	if utf8.RuneCountInString(reportReq.Detail) > 500 {
+		utils.Error(c, utils.CodeBadRequest, "举报说明不能超过500个字符")
+		return
+	}
+
+	// 2. 被举报的内容必须存在且公开可见，不能举报自己的内容
+	target, err := findReportTarget(db.Where("status = ?", models.StatusPublished), reportReq.TargetType, reportReq.TargetID)
+	if err != nil {
+		utils.Error(c, utils.CodeNotFound, targetNotFoundMsg(reportReq.TargetType))
+		return
+	}
+	if target.authorID == userId {
+		utils.Error(c, utils.CodeBadRequest, "不能举报自己的内容")
+		return
+	}
+
+	// 3. 同一用户对同一内容只保留一条待处理的举报
+	var count int64
+	db.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+			userId, reportReq.TargetType, reportReq.TargetID, models.ReportOpen).
+		Count(&count)
+	if count > 0 {
+		utils.Error(c, utils.CodeConflict, "你已举报过该内容，请等待处理")
+		return
+	}
+
+	// 4. 创建举报记录
+	report := models.Report{
+		ReporterID: userId,
+		TargetType: reportReq.TargetType,
+		TargetID:   reportReq.TargetID,
+		Reason:     reportReq.Reason,
+		Detail:     reportReq.Detail,
+		Status:     models.ReportOpen,
+	}
+	if err := db.Create(&report).Error; err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionReportCreate,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Detail:     report.Reason,
+	})
+
+	// 5. 举报数达到阈值时自动隐藏
+	hidden, err := autoHideReported(c, db, report.TargetType, report.TargetID)
+	if err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	utils.Success(c, gin.H{
+		"msg":       utils.MsgSuccess,
+		"report_id": report.ID,
+		"hidden":    hidden,
+	})
+}
+
+// autoHideReported 待处理举报数达到 moderation.report_threshold 时隐藏内容
+// 返回内容是否在本次被隐藏
+func autoHideReported(c *gin.Context, db *gorm.DB, targetType string, targetID uint) (bool, error) {
+	threshold := config.LoadConfig().Moderation.ReportThreshold
+	if threshold <= 0 {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportOpen).
+		Count(&count).Error
+	if err != nil || count < int64(threshold) {
+		return false, err
+	}
+	// 只隐藏仍处于发布状态的内容，避免重复记录
+	result := db.Model(reportTargetModel(targetType)).
+		Where("id = ? AND status = ?", targetID, models.StatusPublished).
+		UpdateColumn("status", models.StatusHidden)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.ActionAutoHide,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     gin.H{"status": models.StatusPublished},
+		After:      gin.H{"status": models.StatusHidden},
+		Detail:     fmt.Sprintf("%d open reports", count),
+	})
+	return true, nil
+}
+
+// reportTarget 被举报的文章或评论
+type reportTarget struct {
+	post     *models.Post
+	comment  *models.Comment
+	authorID uint
+	status   string
+}
+
+// findReportTarget 查询被举报的文章或评论，db 可以附加额外的查询条件
+func findReportTarget(db *gorm.DB, targetType string, targetID uint) (reportTarget, error) {
+	switch targetType {
+	case audit.TargetPost:
+		var post models.Post
+		if err := db.First(&post, targetID).Error; err != nil {
+			return reportTarget{}, err
+		}
+		return reportTarget{post: &post, authorID: post.UserID, status: post.Status}, nil
+	case audit.TargetComment:
+		var comment models.Comment
+		if err := db.First(&comment, targetID).Error; err != nil {
+			return reportTarget{}, err
+		}
+		return reportTarget{comment: &comment, authorID: comment.UserID, status: comment.Status}, nil
+	default:
+		return reportTarget{}, errors.New("unsupported target type: " + targetType)
+	}
+}
+
+// deleted 内容是否已被删除（驳回举报时允许查询已删除的内容）
+func (t reportTarget) deleted() bool {
+	if t.post != nil {
+		return t.post.DeletedAt.Valid
+	}
+	return t.comment != nil && t.comment.DeletedAt.Valid
+}
+
+// reportTargetModel 返回举报对象类型对应的模型，用于构建更新语句
+func reportTargetModel(targetType string) interface{} {
+	if targetType == audit.TargetComment {
+		return &models.Comment{}
+	}
+	return &models.Post{}
+}
+
+// targetNotFoundMsg 返回举报对象类型对应的不存在提示
+func targetNotFoundMsg(targetType string) string {
+	if targetType == audit.TargetComment {
+		return utils.MsgCommentNotFound
+	}
+	return utils.MsgPostNotFound
+}
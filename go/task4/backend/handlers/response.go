@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"blog/service"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError 将 service 返回的错误写入统一错误响应
+func respondError(c *gin.Context, err error) {
+	code, message := service.Status(err)
+	utils.Error(c, code, message)
+}
+
+// paginationResponse 分页信息的响应格式
+func paginationResponse(p service.Pagination) gin.H {
+	return gin.H{
+		"page":       p.Page,
+		"page_size":  p.PageSize,
+		"total":      p.Total,
+		"total_page": p.TotalPage(),
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/middleware"
+	"blog/service"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions 获取当前用户的登录会话
+// 需认证，返回未过期的会话（设备、IP、登录时间、最近使用时间），current 标记发起请求的会话
+func GetSessions(c *gin.Context) {
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	sessions, err := service.ListSessions(c.Request.Context(), userId, c.Request.Header.Get("Authorization"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"sessions": sessions,
+	})
+}
+
+// DeleteSession 退出登录会话（远程退出）
+// 需认证，只能退出自己的会话，退出后使用该会话 Token 的请求返回 401；也可以退出当前会话
+func DeleteSession(c *gin.Context) {
+	var uriReq struct {
+		ID uint `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, "会话不存在")
+		return
+	}
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	if err := service.DeleteSession(audit.RequestContext(c), userId, uriReq.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"msg": utils.MsgSuccess,
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/middleware"
+	"blog/models"
+	"blog/service"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateToken 创建个人访问令牌
+// 需认证（只接受登录的 JWT），令牌明文只在响应中返回一次，之后无法再次查看
+func CreateToken(c *gin.Context) {
+	// 1. 从上下文获取当前用户ID（通过中间件）
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	// 2. 绑定请求参数
+	var tokenReq struct {
+		Name          string   `json:"name" binding:"required"`
+		Scopes        []string `json:"scopes" binding:"required"`
+		ExpiresInDays int      `json:"expires_in_days"` // 有效天数，0 表示永不过期
+	}
+	if err := c.ShouldBindJSON(&tokenReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	// 3. 校验参数并生成令牌
+	secret, token, err := service.CreateToken(audit.RequestContext(c), userId, service.CreateTokenInput{
+		Name:          tokenReq.Name,
+		Scopes:        tokenReq.Scopes,
+		ExpiresInDays: tokenReq.ExpiresInDays,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// 4. 返回令牌信息和令牌明文
+	utils.Success(c, gin.H{
+		"token":  token,
+		"secret": secret,
+	})
+}
+
+// GetTokens 获取当前用户的个人访问令牌
+// 只接受登录返回的 JWT，不返回令牌明文，只返回开头几位用于辨认
+func GetTokens(c *gin.Context) {
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	tokens, err := service.ListTokens(c.Request.Context(), userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"tokens": tokens,
+		"scopes": models.Scopes,
+	})
+}
+
+// DeleteToken 撤销个人访问令牌
+// 需认证，只能撤销自己的令牌，撤销后使用该令牌的请求立即返回 401
+func DeleteToken(c *gin.Context) {
+	var uriReq struct {
+		ID uint `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, "令牌不存在")
+		return
+	}
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	if err := service.DeleteToken(audit.RequestContext(c), userId, uriReq.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"msg": utils.MsgSuccess,
+	})
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/database"
+	"blog/middleware"
+	"blog/models"
+	"blog/service"
+	"blog/utils"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// trashedPost 回收站中的文章
+// models.Post 不向前端返回 deleted_at，这里单独返回删除时间和预计彻底删除的时间
+type trashedPost struct {
+	models.Post
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"` // 保留时间为 0（永久保留）时不返回
+}
+
+// GetTrashPosts 获取当前用户回收站中的文章
+// 需认证，按删除时间倒序分页返回
+func GetTrashPosts(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	var trashReq struct {
+		Page     int `form:"page"`
+		PageSize int `form:"page_size"`
+	}
+	_ = c.ShouldBindQuery(&trashReq)
+
+	page := trashReq.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := trashReq.PageSize
+	if pageSize < 1 {
+		pageSize = 10 // 默认每页10条
+	}
+	if pageSize > 50 {
+		pageSize = 50 // 最大每页50条
+	}
+
+	query := db.Unscoped().Model(&models.Post{}).Where("user_id = ? AND deleted_at IS NOT NULL", userId)
+	var total int64
+	query.Count(&total)
+	var posts []models.Post
+	result := query.Order("deleted_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&posts)
+	if result.Error != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+
+	retention := config.LoadConfig().Trash.Retention
+	items := make([]trashedPost, 0, len(posts))
+	for _, post := range posts {
+		item := trashedPost{Post: post, DeletedAt: post.DeletedAt.Time}
+		if retention > 0 {
+			purgeAt := post.DeletedAt.Time.Add(retention)
+			item.PurgeAt = &purgeAt
+		}
+		items = append(items, item)
+	}
+	utils.Success(c, gin.H{
+		"posts": items,
+		"pagination": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (int(total) + pageSize - 1) / pageSize,
+		},
+	})
+}
+
+// RestorePost 从回收站恢复文章
+// 只有作者才能恢复，与文章一同删除的评论也会被恢复
+func RestorePost(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	post, ok := findTrashedPost(c, db)
+	if !ok {
+		return
+	}
+	var restored int64
+	err := database.Transaction(db, func(tx *gorm.DB) error {
+		// 删除文章时评论的删除时间与文章相同，在此之前单独删除的评论不恢复
+		result := tx.Unscoped().Model(&models.Comment{}).
+			Where("post_id = ? AND deleted_at >= ?", post.ID, post.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		restored = result.RowsAffected
+		return tx.Unscoped().Model(&post).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		ActorID:    post.UserID,
+		Action:     audit.ActionPostRestore,
+		TargetType: audit.TargetPost,
+		TargetID:   post.ID,
+		After:      service.PostSnapshot(post),
+		Detail:     fmt.Sprintf("restored %d comments", restored),
+	})
+	utils.Success(c, gin.H{
+		"msg":               utils.MsgSuccess,
+		"post_id":           post.ID,
+		"restored_comments": restored,
+	})
+}
+
+// PurgePost 彻底删除回收站中的文章
+// 只有作者才能删除，文章下的所有评论（以及首页动态收件箱中的条目）一并删除，操作不可恢复
+func PurgePost(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	post, ok := findTrashedPost(c, db)
+	if !ok {
+		return
+	}
+	err := database.Transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("post_id = ?", post.ID).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.TimelineItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&post).Error
+	})
+	if err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		ActorID:    post.UserID,
+		Action:     audit.ActionPostPurge,
+		TargetType: audit.TargetPost,
+		TargetID:   post.ID,
+		Before:     service.PostSnapshot(post),
+	})
+	utils.Success(c, gin.H{
+		"msg": utils.MsgSuccess,
+	})
+}
+
+// findTrashedPost 根据 URL 中的文章ID查询回收站中属于当前用户的文章
+// 查询失败时已写入错误响应，调用方直接返回即可
+func findTrashedPost(c *gin.Context, db *gorm.DB) (models.Post, bool) {
+	var post models.Post
+	var trashReq struct {
+		ID int `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&trashReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
+		return post, false
+	}
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return post, false
+	}
+	result := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", trashReq.ID).First(&post)
+	if result.Error != nil {
+		utils.Error(c, utils.CodeNotFound, utils.MsgPostNotFound)
+		return post, false
+	}
+	if post.UserID != userId {
+		denyNotOwner(c, userId, audit.TargetPost, post.ID)
+		return post, false
+	}
+	return post, true
+}
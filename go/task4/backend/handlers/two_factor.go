@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/middleware"
+	"blog/service"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTwoFactor 获取当前用户的两步验证状态
+// 需认证，返回是否已启用、剩余恢复码个数以及当前角色是否必须启用
+func GetTwoFactor(c *gin.Context) {
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	status, err := service.GetTwoFactorStatus(c.Request.Context(), userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, status)
+}
+
+// SetupTwoFactor 生成两步验证密钥
+// 需认证，返回密钥和 otpauth:// 地址（前端生成二维码供身份验证器扫描），调用 EnableTwoFactor 验证后才生效
+func SetupTwoFactor(c *gin.Context) {
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	secret, uri, err := service.SetupTwoFactor(audit.RequestContext(c), userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"secret": secret,
+		"uri":    uri,
+	})
+}
+
+// EnableTwoFactor 启用两步验证
+// 需认证，提交身份验证器中的验证码，验证通过后启用并返回恢复码（只返回这一次）
+func EnableTwoFactor(c *gin.Context) {
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	var enableReq struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&enableReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	codes, err := service.EnableTwoFactor(audit.RequestContext(c), userId, enableReq.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+// 需认证，需要提交密码以及验证码或恢复码
+func DisableTwoFactor(c *gin.Context) {
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	var disableReq struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&disableReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	if err := service.DisableTwoFactor(audit.RequestContext(c), userId, disableReq.Password, disableReq.Code); err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"msg": utils.MsgSuccess,
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// 需认证，提交验证码或恢复码，旧的恢复码全部失效，返回新的恢复码（只返回这一次）
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	var regenerateReq struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&regenerateReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	codes, err := service.RegenerateRecoveryCodes(audit.RequestContext(c), userId, regenerateReq.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.Success(c, gin.H{
+		"recovery_codes": codes,
+	})
+}
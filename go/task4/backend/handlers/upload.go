@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/media"
+	"blog/middleware"
+	"blog/service"
+	"blog/storage"
+	"blog/utils"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadFile 上传附件
+// 已认证的用户通过 multipart/form-data 的 file 字段上传图片或文件，返回附件信息；
+// 附件在创建或更新文章时通过 attachment_ids 关联到文章
+func UploadFile(c *gin.Context) {
+	// 1. 从上下文获取当前用户ID（通过中间件）
+	userId, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+		return
+	}
+	// 2. 限制请求体大小（为 multipart 头部留出余量），读取上传的文件
+	maxSize := config.LoadConfig().Upload.MaxSize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxSize)+64<<10)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.Error(c, utils.CodePayloadTooLarge, "上传的文件过大")
+			return
+		}
+		utils.Error(c, utils.CodeBadRequest, "请通过 file 字段上传文件")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, int64(maxSize)+1))
+	if err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	// 3. 校验类型、大小和图片尺寸，生成缩略图并存储
+	attachment, err := service.Upload(audit.RequestContext(c), userId, fileHeader.Filename, data)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// 4. 返回附件信息
+	utils.Success(c, gin.H{
+		"attachment": attachment,
+	})
+}
+
+// ServeUpload 读取上传的文件
+// 文件按内容寻址，内容不会改变，因此允许长期缓存；非图片文件以下载方式返回，避免被浏览器直接渲染
+func ServeUpload(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	etag := `"` + strings.TrimSuffix(path.Base(key), path.Ext(key)) + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+	reader, err := storage.Default.Open(c.Request.Context(), key)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) && !errors.Is(err, storage.ErrInvalidKey) {
+			log.Println("Blog storage error: ", err)
+		}
+		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+		return
+	}
+	defer reader.Close()
+
+	contentType := media.TypeByExtension(path.Ext(key))
+	headers := map[string]string{
+		"ETag":                   etag,
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		headers["Content-Disposition"] = "attachment"
+	}
+	c.DataFromReader(http.StatusOK, -1, contentType, reader, headers)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"blog/middleware"
+	"blog/service"
+	"blog/utils"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// expectedVersion 解析客户端期望修改的版本号
+// 优先读取 If-Match 请求头（如 "3"、"v3"、获取文章时返回的 ETag，"*" 表示不校验版本，返回 nil），其次读取请求体中的 version 字段；
+// 都未提供或格式错误时写入错误响应并返回 ok=false
+func expectedVersion(c *gin.Context, bodyVersion *uint) (version *uint, fromHeader bool, ok bool) {
+	if ifMatch := strings.TrimSpace(c.GetHeader("If-Match")); ifMatch != "" {
+		if ifMatch == "*" {
+			return nil, true, true
+		}
+		expected, ok := middleware.ParseVersionETag(ifMatch)
+		if !ok {
+			utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+			return nil, true, false
+		}
+		return &expected, true, true
+	}
+	if bodyVersion != nil {
+		return bodyVersion, false, true
+	}
+	utils.Error(c, utils.CodePreconditionRequired, utils.MsgVersionRequired)
+	return nil, false, false
+}
+
+// respondUpdateError 写入更新接口的错误响应
+// 版本冲突时附带当前版本号：通过 If-Match 提供版本时返回 412，通过请求体提供时返回 409
+func respondUpdateError(c *gin.Context, err error, fromHeader bool) {
+	var conflict *service.VersionConflictError
+	if !errors.As(err, &conflict) {
+		respondError(c, err)
+		return
+	}
+	code := utils.CodeConflict
+	if fromHeader {
+		code = utils.CodePreconditionFailed
+	}
+	utils.ErrorWithData(c, code, utils.MsgVersionConflict, gin.H{
+		"current_version": conflict.Current,
+	})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"blog/audit"
+	"blog/database"
+	"blog/middleware"
+	"blog/models"
+	"blog/utils"
+	"blog/webhook"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateWebhook 创建事件订阅
+// 仅管理员可用；未指定 secret 时随机生成，secret 只在创建时返回一次
+func CreateWebhook(c *gin.Context) {
+	// 1. 从上下文获取当前用户ID（通过中间件）
+	userId, _ := middleware.GetUserFromContext(c)
+	// 2. 绑定并校验请求参数
+	var webhookReq struct {
+		URL    string   `json:"url" binding:"required"`
+		Events []string `json:"events" binding:"required"`
+		Secret string   `json:"secret"`
+	}
+	if err := c.ShouldBindJSON(&webhookReq); err != nil {
+		utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+		return
+	}
+	u, err := url.Parse(strings.TrimSpace(webhookReq.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(webhookReq.URL) > 2048 {
+		utils.Error(c, utils.CodeBadRequest, "url 必须是 http 或 https 地址")
+		return
+	}
+	events := uniqueStrings(webhookReq.Events)
+	if len(events) == 0 {
+		utils.Error(c, utils.CodeBadRequest, "events 不能为空")
+		return
+	}
+	for _, event := range events {
+		if !webhook.ValidEvent(event) {
+			utils.Error(c, utils.CodeBadRequest, fmt.Sprintf("不支持的事件 %q，可选：%s", event, strings.Join(webhook.Events, "、")))
+			return
+		}
+	}
+	secret := webhookReq.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+			return
+		}
+		secret = hex.EncodeToString(buf)
+	}
+	if len(secret) < 16 || len(secret) > 100 {
+		utils.Error(c, utils.CodeBadRequest, "secret 长度必须在16到100个字符之间")
+		return
+	}
+	// 3. 保存订阅
+	hook := models.Webhook{
+		URL:       u.String(),
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+		CreatedBy: userId,
+	}
+	if err := database.DB.WithContext(c.Request.Context()).Create(&hook).Error; err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionWebhookCreate,
+		TargetType: audit.TargetWebhook,
+		TargetID:   hook.ID,
+		After:      gin.H{"url": hook.URL, "events": hook.Events},
+	})
+	// 4. 返回订阅信息和签名密钥
+	utils.Success(c, gin.H{
+		"webhook": hook,
+		"secret":  secret,
+	})
+}
+
+// GetWebhooks 获取所有事件订阅
+// 仅管理员可用，不返回签名密钥
+func GetWebhooks(c *gin.Context) {
+	var hooks []models.Webhook
+	if err := database.DB.WithContext(c.Request.Context()).Order("id ASC").Find(&hooks).Error; err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	utils.Success(c, gin.H{
+		"webhooks": hooks,
+		"events":   webhook.Events,
+	})
+}
+
+// DeleteWebhook 删除事件订阅
+// 仅管理员可用，订阅的投递记录一并删除，尚未完成的投递不再进行
+func DeleteWebhook(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	userId, _ := middleware.GetUserFromContext(c)
+	hook, ok := findWebhook(c, db)
+	if !ok {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&models.WebhookDelivery{}).Select("id").Where("webhook_id = ?", hook.ID)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&models.WebhookAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(hook).Error
+	})
+	if err != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionWebhookDelete,
+		TargetType: audit.TargetWebhook,
+		TargetID:   hook.ID,
+		Before:     gin.H{"url": hook.URL, "events": hook.Events},
+	})
+	utils.Success(c, gin.H{
+		"msg": utils.MsgSuccess,
+	})
+}
+
+// GetWebhookDeliveries 查询事件订阅的投递记录
+// 仅管理员可用，按时间倒序分页返回，每条记录包含所有投递请求的状态码、响应和耗时；支持按 status 过滤
+func GetWebhookDeliveries(c *gin.Context) {
+	db := database.DB.WithContext(c.Request.Context())
+	hook, ok := findWebhook(c, db)
+	if !ok {
+		return
+	}
+	var pageReq struct {
+		Page     int    `form:"page"`
+		PageSize int    `form:"page_size"`
+		Status   string `form:"status"`
+	}
+	_ = c.ShouldBindQuery(&pageReq)
+
+	page := pageReq.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := pageReq.PageSize
+	if pageSize < 1 {
+		pageSize = 20 // 默认每页20条
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 最大每页100条
+	}
+	query := db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+	if pageReq.Status != "" {
+		query = query.Where("status = ?", pageReq.Status)
+	}
+
+	var total int64
+	query.Count(&total)
+	var deliveries []models.WebhookDelivery
+	result := query.Preload("AttemptLog", func(tx *gorm.DB) *gorm.DB { return tx.Order("id ASC") }).
+		Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries)
+	if result.Error != nil {
+		utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+		return
+	}
+	utils.Success(c, gin.H{
+		"deliveries": deliveries,
+		"pagination": gin.H{
+			"page":       page,
+			"page_size":  pageSize,
+			"total":      total,
+			"total_page": (int(total) + pageSize - 1) / pageSize,
+		},
+	})
+}
+
+// findWebhook 根据路径参数 id 查询事件订阅，不存在时返回 404
+func findWebhook(c *gin.Context, db *gorm.DB) (*models.Webhook, bool) {
+	var uriReq struct {
+		ID uint `uri:"id" binding:"required"`
+	}
+	if err := c.ShouldBindUri(&uriReq); err != nil {
+		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+		return nil, false
+	}
+	var hook models.Webhook
+	if err := db.First(&hook, uriReq.ID).Error; err != nil {
+		utils.Error(c, utils.CodeNotFound, utils.MsgNotFound)
+		return nil, false
+	}
+	return &hook, true
+}
+
+// uniqueStrings 去除空字符串和重复项，保持原有顺序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
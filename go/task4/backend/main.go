@@ -1,40 +1,183 @@
 package main
 
 import (
+	"blog/cache"
 	"blog/config"
 	"blog/database"
+	"blog/filter"
+	"blog/openapi"
 	"blog/routes"
+	"blog/rpc"
+	"blog/storage"
+	"blog/timeline"
+	"blog/tracing"
+	"blog/web"
+	"blog/webhook"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 // main 是程序入口
-// 功能：初始化数据库、注册路由、启动服务器
+// 功能：解析子命令；默认启动博客服务器，`config print` 打印当前生效的配置，`user set-role` 修改用户角色，`user reset-2fa` 重置两步验证，`openapi print|check` 输出或检查 API 文档，`export`/`import` 导出或导入文章，`oidc mock` 启动模拟的 OIDC 提供方，`timeline rebuild` 重新生成首页动态收件箱
 func main() {
+	args := os.Args[1:]
+	var err error
+	switch {
+	case len(args) > 0 && args[0] == "config":
+		err = runConfigCommand(args[1:])
+	case len(args) > 0 && args[0] == "user":
+		err = runUserCommand(args[1:])
+	case len(args) > 0 && args[0] == "openapi":
+		err = runOpenAPICommand(args[1:])
+	case len(args) > 0 && args[0] == "export":
+		err = runExportCommand(args[1:])
+	case len(args) > 0 && args[0] == "import":
+		err = runImportCommand(args[1:])
+	case len(args) > 0 && args[0] == "oidc":
+		err = runOIDCCommand(args[1:])
+	case len(args) > 0 && args[0] == "timeline":
+		err = runTimelineCommand(args[1:])
+	default:
+		err = runServer(args)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Blog error:", err)
+		os.Exit(1)
+	}
+}
+
+// runServer 启动博客服务器
+// 功能：初始化数据库、注册路由、启动服务器
+func runServer(args []string) error {
 	log.Println("Blog server starting...")
-	// 初始化配置
-	cfg := config.LoadConfig()
+	// 初始化配置（配置文件 → 环境变量 → 命令行参数）
+	cfg, err := config.Init(args)
+	if err != nil {
+		return err
+	}
+	if cfg.Log.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	// 初始化链路追踪
+	shutdownTracer, err := tracing.InitTracer(&cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("tracing init: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracer(ctx); err != nil {
+			log.Println("Blog tracing shutdown error: ", err)
+		}
+	}()
 	//初始化数据库连接
-	err := database.InitDB(&cfg.Database)
+	err = database.InitDB(&cfg.Database)
 	if err != nil {
-		log.Fatal("Blog database init error: ", err)
-
+		return fmt.Errorf("database init: %w", err)
 	}
 	// 自动迁移表结构
 	err = database.InitTable()
 	if err != nil {
-		log.Fatal("Blog database migrate error: ", err)
-
+		return fmt.Errorf("database migrate: %w", err)
+	}
+	// 初始化公开接口的响应缓存
+	cache.Init(&cfg.Cache)
+	// 初始化垃圾内容过滤规则
+	filter.Init(&cfg.Filter)
+	// 选择首页动态的分发策略
+	timeline.Init(&cfg.Timeline)
+	// 初始化上传文件的存储后端
+	if err := storage.Init(&cfg.Upload); err != nil {
+		return fmt.Errorf("storage init: %w", err)
+	}
+	// 加载前端资源（嵌入的资源或开发模式下的磁盘目录）
+	if err := web.Init(&cfg.Frontend); err != nil {
+		return fmt.Errorf("frontend init: %w", err)
 	}
 
-	// 注册路由
-	router := gin.Default()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	// 启动回收站清理任务，随服务器一同退出
+	database.StartTrashPurge(ctx, &cfg.Trash)
+	// 启动过期幂等键的清理任务
+	database.StartIdempotencyPurge(ctx, &cfg.Idempotency)
+	// 启动事件通知的后台投递任务
+	webhook.StartWorker(ctx, &cfg.Webhook)
+
+	// 注册路由（Recovery 和日志中间件由 SetupRoutes 统一注册）
+	router := gin.New()
 	routes.SetupRoutes(router)
+	// 路由与 API 文档不一致时只记录警告，不影响启动
+	if err := openapi.Verify(router.Routes()); err != nil {
+		log.Println("Blog warning:", err)
+	}
 	//  启动 HTTP 服务器
-	err = router.Run(cfg.Server.Host + ":" + cfg.Server.Port)
-	if err != nil {
-		log.Fatal("Blog server start error:", err)
+	server := &http.Server{
+		Addr:    cfg.Server.Host + ":" + cfg.Server.Port,
+		Handler: router,
+	}
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	// 启动 gRPC 服务器（独立端口，与 HTTP 接口共用业务逻辑）
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		host := cfg.GRPC.Host
+		if host == "" {
+			host = cfg.Server.Host
+		}
+		listener, err := net.Listen("tcp", host+":"+cfg.GRPC.Port)
+		if err != nil {
+			return fmt.Errorf("grpc listen: %w", err)
+		}
+		grpcServer = rpc.NewServer()
+		go func() {
+			serverErr <- grpcServer.Serve(listener)
+		}()
+		log.Printf("Blog gRPC server listening on %s", listener.Addr())
+	}
+
+	// 等待退出信号，优雅关闭服务器（确保剩余的 Span 被导出）
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("server start: %w", err)
+	case <-ctx.Done():
+	}
+	log.Println("Blog server shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+	return server.Shutdown(shutdownCtx)
+}
 
+// stopGRPC 优雅关闭 gRPC 服务器，超时后强制断开剩余连接
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
 	}
 }
@@ -0,0 +1,103 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+)
+
+// ErrUnsupportedImage 无法解码的图片（格式不支持或文件已损坏）
+var ErrUnsupportedImage = errors.New("media: unsupported or corrupt image")
+
+// extensions 支持的 MIME 类型及其扩展名
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+	"application/zip": ".zip",
+}
+
+// TypeByExtension 返回扩展名对应的 MIME 类型，不支持的扩展名返回 application/octet-stream
+func TypeByExtension(ext string) string {
+	for contentType, e := range extensions {
+		if e == ext {
+			if contentType == "text/plain" {
+				return "text/plain; charset=utf-8"
+			}
+			return contentType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// Info 根据文件内容识别的类型信息
+type Info struct {
+	ContentType string // 不含参数的 MIME 类型，如 image/png
+	Ext         string // 扩展名，类型不支持时为空
+	Image       bool   // 是否为可解码的图片类型
+	Width       int    // 图片宽度
+	Height      int    // 图片高度
+}
+
+// Inspect 根据文件内容识别类型，不信任文件名和客户端声明的类型
+// 图片只读取头部信息获取尺寸，不解码像素，避免超大图片占用内存
+func Inspect(data []byte) (Info, error) {
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	info := Info{ContentType: contentType, Ext: extensions[contentType]}
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return info, ErrUnsupportedImage
+		}
+		info.Image, info.Width, info.Height = true, config.Width, config.Height
+	}
+	return info, nil
+}
+
+// Thumbnail 生成最大边长不超过 size 的缩略图，小图保持原尺寸
+// JPEG 输出 JPEG，其他格式输出 PNG 以保留透明度；返回缩略图内容、MIME 类型和扩展名
+func Thumbnail(data []byte, size int) ([]byte, string, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", "", ErrUnsupportedImage
+	}
+	bounds := src.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), size)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/jpeg", ".jpg", nil
+	}
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/png", ".png", nil
+}
+
+// fit 按比例缩放到最大边长不超过 size，至少 1 像素
+func fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
@@ -0,0 +1,95 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// encodePNG 生成指定尺寸的 PNG
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader 只包含签名和 IHDR 的 PNG，声明的尺寸可以很大而没有像素数据
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 6 // 8 位 RGBA
+	chunk := append([]byte("IHDR"), ihdr...)
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestInspectImage(t *testing.T) {
+	info, err := Inspect(encodePNG(t, 40, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ContentType != "image/png" || info.Ext != ".png" || !info.Image || info.Width != 40 || info.Height != 30 {
+		t.Errorf("Inspect(png) = %+v", info)
+	}
+}
+
+// TestInspectLargeImage 超大图片只读取头部的尺寸，由调用方按配置拒绝，不会解码像素
+func TestInspectLargeImage(t *testing.T) {
+	info, err := Inspect(pngHeader(100000, 80000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Image || info.Width != 100000 || info.Height != 80000 {
+		t.Errorf("Inspect(large png header) = %+v", info)
+	}
+}
+
+func TestInspectRejectsCorruptImage(t *testing.T) {
+	data := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 32)...)
+	if _, err := Inspect(data); !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("Inspect(corrupt png) error = %v, want ErrUnsupportedImage", err)
+	}
+}
+
+// TestInspectType 类型只根据内容识别；不支持的类型 Ext 为空，由调用方拒绝
+func TestInspectType(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		ext         string
+	}{
+		{"text", []byte("hello, world\n"), "text/plain", ".txt"},
+		{"pdf", []byte("%PDF-1.7\n1 0 obj\n"), "application/pdf", ".pdf"},
+		{"zip", []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00"), "application/zip", ".zip"},
+		{"html", []byte("<!DOCTYPE html><script>alert(1)</script>"), "text/html", ""},
+		{"executable", []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00"), "application/octet-stream", ""},
+		{"svg named as png", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), "text/xml", ""},
+	}
+	for _, tt := range tests {
+		info, err := Inspect(tt.data)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if info.ContentType != tt.contentType || info.Ext != tt.ext || info.Image {
+			t.Errorf("%s: Inspect = %+v, want %s %q", tt.name, info, tt.contentType, tt.ext)
+		}
+	}
+}
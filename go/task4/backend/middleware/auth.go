@@ -1,46 +1,53 @@
 package middleware
 
 import (
+	"blog/audit"
+	"blog/models"
+	"blog/service"
 	"blog/utils"
-	"strings"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthMiddleware JWT验证中间件
-// 验证请求中的JWT Token，提取用户信息并放入上下文
-func AuthMiddleware() gin.HandlerFunc {
+// 验证请求中的JWT Token或个人访问令牌，提取用户信息并放入上下文。
+// 使用个人访问令牌时检查权限范围：指定了 scopes 时任何请求方法都需要其中任一项；
+// 未指定时 GET 请求需要 read，写接口不接受个人访问令牌
+func AuthMiddleware(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 实现JWT验证逻辑
-
-		// 从请求头获取Token（Authorization: Bearer <token>）
-		tokenString := c.Request.Header.Get("Authorization")
-		if tokenString == "" {
-			utils.Error(c, utils.CodeUnauthorized, utils.MsgUnauthorized)
-			c.Abort()
-			return
-		}
-		// 验证Token格式
-		parts := strings.SplitN(tokenString, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.Error(c, utils.CodeUnauthorized, utils.MsgUnauthorized)
-			c.Abort()
-			return
+		// 从请求头获取Token（Authorization: Bearer <token>），校验Token有效性以及用户是否存在、是否被封禁
+		ctx := audit.RequestContext(c)
+		user, token, err := service.Authenticate(ctx, c.Request.Header.Get("Authorization"))
+		if err == nil && token != nil && !tokenAllowed(c.Request.Method, token, scopes) {
+			err = service.DenyScope(ctx, user, token)
 		}
-		// 验证Token有效性,解析Token获取用户ID
-		userId, err := utils.ValidateToken(parts[1])
 		if err != nil {
-			utils.Error(c, utils.CodeUnauthorized, utils.MsgUnauthorized)
+			code, message := service.Status(err)
+			utils.Error(c, code, message)
 			c.Abort()
 			return
 		}
-		//将用户ID存入上下文
-		c.Set("user_id", userId)
+		//将用户ID和角色存入上下文
+		c.Set("user_id", user.ID)
+		c.Set("user_role", user.Role)
+		c.Set("two_factor", user.TOTPEnabledAt != nil)
 
 		c.Next()
 	}
 }
 
+// LoginOnly 作为 AuthMiddleware 的权限范围时，接口（包括 GET）只接受登录的 JWT，不接受任何个人访问令牌
+const LoginOnly = "login"
+
+// tokenAllowed 判断个人访问令牌是否有权限访问接口
+func tokenAllowed(method string, token *models.PersonalAccessToken, scopes []string) bool {
+	if len(scopes) == 0 && (method == http.MethodGet || method == http.MethodHead) {
+		return token.HasScope(models.ScopeRead)
+	}
+	return token.HasScope(scopes...)
+}
+
 // GetUserFromContext 从上下文获取用户ID
 // 从Gin上下文中提取当前登录用户的ID
 func GetUserFromContext(c *gin.Context) (uint, bool) {
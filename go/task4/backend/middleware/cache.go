@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"blog/cache"
+	"blog/config"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter 缓冲响应体，等处理完成后再计算 ETag 并统一写出
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader 记录状态码，不立即写出
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 延迟到缓冲写出时再发送响应头
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+// Write 写入缓冲区
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteString 写入缓冲区
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Status 返回记录的状态码
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+// Size 返回已缓冲的字节数
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+// Written 是否已有响应内容
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// CacheMiddleware 公开读接口的响应缓存中间件
+// 为 GET 响应生成强 ETag 并处理 If-None-Match（命中返回 304），设置 Cache-Control 以便 CDN 缓存；
+// 启用进程内缓存时，成功响应按请求 URI 缓存，tags 为响应依赖的数据标签，相关数据写入后缓存失效
+func CacheMiddleware(tags ...string) gin.HandlerFunc {
+	cfg := config.LoadConfig().Cache
+	cacheControl := fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d",
+		int(cfg.MaxAge.Seconds()), int(cfg.StaleWhileRevalidate.Seconds()))
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		key := c.Request.URL.RequestURI()
+
+		// 1. 命中进程内缓存直接返回
+		if cache.Responses != nil {
+			if resp, ok := cache.Responses.Get(key); ok {
+				c.Header("X-Cache", "HIT")
+				writeCachedResponse(c, resp, cacheControl)
+				c.Abort()
+				return
+			}
+		}
+
+		// 2. 记录缓存代数后缓冲处理结果
+		gen := cache.Generation()
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		// 非 200 响应（如 404、500）原样返回，不缓存
+		if writer.status != http.StatusOK {
+			c.Status(writer.status)
+			_, _ = original.Write(writer.body.Bytes())
+			return
+		}
+
+		// 3. 计算强 ETag 并写入缓存，带版本号的资源在 ETag 中编码版本号
+		sum := sha256.Sum256(writer.body.Bytes())
+		etag := hex.EncodeToString(sum[:16])
+		if version, ok := c.Get("etag_version"); ok {
+			etag = fmt.Sprintf("v%d-%s", version, etag)
+		}
+		resp := cache.Response{
+			Status:       writer.status,
+			ContentType:  original.Header().Get("Content-Type"),
+			ETag:         `"` + etag + `"`,
+			LastModified: original.Header().Get("Last-Modified"),
+			Body:         writer.body.Bytes(),
+		}
+		if cache.Responses != nil {
+			c.Header("X-Cache", "MISS")
+			cache.Store(key, resp, gen, tags...)
+		}
+		writeCachedResponse(c, resp, cacheControl)
+	}
+}
+
+// SetETagVersion 记录响应资源的版本号
+// 缓存中间件生成 "v<版本号>-<内容哈希>" 形式的 ETag，客户端可以直接将其作为更新时的 If-Match
+func SetETagVersion(c *gin.Context, version uint) {
+	c.Set("etag_version", version)
+}
+
+// VersionETag 返回只包含版本号的 ETag，如 "v3"，用于更新接口的响应
+func VersionETag(version uint) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// ParseVersionETag 从 If-Match 中解析版本号
+// 支持 "3"、"v3" 以及缓存中间件生成的 "v3-<内容哈希>"，可带 W/ 前缀
+func ParseVersionETag(etag string) (uint, bool) {
+	value := strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), `"`)
+	if rest, ok := strings.CutPrefix(value, "v"); ok {
+		value, _, _ = strings.Cut(rest, "-")
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(v), true
+}
+
+// writeCachedResponse 写出响应，If-None-Match 与 ETag 匹配时返回 304
+// 没有 If-None-Match 时，If-Modified-Since 不早于 Last-Modified 也返回 304
+func writeCachedResponse(c *gin.Context, resp cache.Response, cacheControl string) {
+	c.Header("ETag", resp.ETag)
+	c.Header("Cache-Control", cacheControl)
+	if resp.LastModified != "" {
+		c.Header("Last-Modified", resp.LastModified)
+	}
+	ifNoneMatch := c.GetHeader("If-None-Match")
+	if ETagMatches(ifNoneMatch, resp.ETag) ||
+		(ifNoneMatch == "" && notModifiedSince(c.GetHeader("If-Modified-Since"), resp.LastModified)) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(resp.Status, resp.ContentType, resp.Body)
+}
+
+// ETagMatches 判断 If-None-Match 是否与 ETag 匹配（支持多个 ETag 和 "*"）
+func ETagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		// If-None-Match 使用弱比较，忽略 W/ 前缀
+		candidate = strings.TrimPrefix(candidate, "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedSince 判断 If-Modified-Since 是否不早于 Last-Modified
+func notModifiedSince(ifModifiedSince, lastModified string) bool {
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	return err == nil && !modified.After(since)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"blog/config"
+	"blog/database"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lastWriteCookie 记录客户端最近一次写操作时间的 Cookie 名称
+const lastWriteCookie = "blog_last_write"
+
+// ReadAfterWriteMiddleware 写后读一致性中间件
+// 配置了只读副本时，客户端写入后在 read_after_write_window 内的读请求改为读主库，避免因复制延迟读到旧数据
+func ReadAfterWriteMiddleware() gin.HandlerFunc {
+	window := config.LoadConfig().Database.ReadAfterWriteWindow
+	return func(c *gin.Context) {
+		if window <= 0 || !database.HasReplicas() {
+			c.Next()
+			return
+		}
+
+		// 1. 最近写过的客户端，读请求走主库
+		if value, err := c.Cookie(lastWriteCookie); err == nil {
+			if ts, err := strconv.ParseInt(value, 10, 64); err == nil && time.Since(time.Unix(ts, 0)) < window {
+				c.Request = c.Request.WithContext(database.WithPrimary(c.Request.Context()))
+			}
+		}
+
+		// 2. 写请求记录写入时间（必须在响应写出前设置 Cookie）
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			maxAge := int(math.Ceil(window.Seconds()))
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(lastWriteCookie, strconv.FormatInt(time.Now().Unix(), 10), maxAge, "/", "", false, true)
+		}
+		c.Next()
+	}
+}
@@ -1,39 +1,223 @@
 package middleware
 
 import (
+	"blog/config"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
+// corsPolicy 编译后的跨域策略
+type corsPolicy struct {
+	allowAll         bool              // 是否允许所有来源（"*"）
+	exactOrigins     map[string]bool   // 精确匹配的来源
+	wildcardOrigins  []wildcardOrigin  // 子域名通配的来源
+	allowedMethods   map[string]bool   // 允许的请求方法
+	allowedHeaders   map[string]bool   // 允许的请求头（小写）
+	methodsHeader    string            // Access-Control-Allow-Methods 响应头
+	headersHeader    string            // Access-Control-Allow-Headers 响应头
+	exposeHeader     string            // Access-Control-Expose-Headers 响应头
+	allowCredentials bool              // 是否允许携带凭证
+	maxAge           string            // Access-Control-Max-Age 响应头（秒）
+	routes           []corsRoutePolicy // 按路径前缀覆盖的策略（按前缀长度降序）
+}
+
+// corsRoutePolicy 路径前缀对应的策略
+type corsRoutePolicy struct {
+	prefix string
+	policy *corsPolicy
+}
+
+// wildcardOrigin 子域名通配来源，如 https://*.example.com 匹配 https://a.example.com
+type wildcardOrigin struct {
+	scheme string
+	suffix string // ".example.com"
+	port   string
+}
+
 // CORSMiddleware CORS跨域中间件
 // 处理跨域请求，设置响应头
 func CORSMiddleware() gin.HandlerFunc {
+	// 从配置读取跨域策略
+	policy := newCORSPolicy(config.LoadConfig().CORS)
 	return func(c *gin.Context) {
-		// TODO: 实现CORS逻辑
-		// 1. 设置 Access-Control-Allow-Origin
-		c.Header("Access-Control-Allow-Origin", "*")
+		policy.forPath(c.Request.URL.Path).handle(c)
+	}
+}
+
+// newCORSPolicy 根据配置编译跨域策略及其路径覆盖
+func newCORSPolicy(cfg config.CORSConfig) *corsPolicy {
+	p := compileCORSPolicy(cfg.AllowedOrigins, cfg.AllowedMethods, cfg.AllowCredentials, cfg)
+	for _, route := range cfg.Routes {
+		origins, methods, credentials := cfg.AllowedOrigins, cfg.AllowedMethods, cfg.AllowCredentials
+		if len(route.AllowedOrigins) > 0 {
+			origins = route.AllowedOrigins
+		}
+		if len(route.AllowedMethods) > 0 {
+			methods = route.AllowedMethods
+		}
+		if route.AllowCredentials != nil {
+			credentials = *route.AllowCredentials
+		}
+		p.routes = append(p.routes, corsRoutePolicy{
+			prefix: route.PathPrefix,
+			policy: compileCORSPolicy(origins, methods, credentials, cfg),
+		})
+	}
+	// 最长前缀优先匹配
+	sort.SliceStable(p.routes, func(i, j int) bool {
+		return len(p.routes[i].prefix) > len(p.routes[j].prefix)
+	})
+	return p
+}
+
+// compileCORSPolicy 将来源、方法等配置预处理为便于匹配的结构
+func compileCORSPolicy(origins, methods []string, credentials bool, cfg config.CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		exactOrigins:     make(map[string]bool),
+		allowedMethods:   make(map[string]bool),
+		allowedHeaders:   make(map[string]bool),
+		allowCredentials: credentials,
+		maxAge:           strconv.Itoa(int(cfg.MaxAge.Seconds())),
+		exposeHeader:     strings.Join(cfg.ExposedHeaders, ", "),
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			p.allowAll = true
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(u.Hostname(), "*.") {
+			p.wildcardOrigins = append(p.wildcardOrigins, wildcardOrigin{
+				scheme: strings.ToLower(u.Scheme),
+				suffix: strings.ToLower(strings.TrimPrefix(u.Hostname(), "*")),
+				port:   u.Port(),
+			})
+			continue
+		}
+		p.exactOrigins[strings.ToLower(origin)] = true
+	}
+	for _, method := range methods {
+		p.allowedMethods[strings.ToUpper(method)] = true
+	}
+	for _, header := range cfg.AllowedHeaders {
+		p.allowedHeaders[strings.ToLower(header)] = true
+	}
+	p.methodsHeader = strings.Join(methods, ", ")
+	p.headersHeader = strings.Join(cfg.AllowedHeaders, ", ")
+	return p
+}
+
+// forPath 返回路径对应的策略，没有匹配的覆盖时使用全局策略
+func (p *corsPolicy) forPath(path string) *corsPolicy {
+	for _, route := range p.routes {
+		// 按路径段匹配，避免 "/api/auth" 误匹配 "/api/authors"
+		if rest, ok := strings.CutPrefix(path, route.prefix); ok &&
+			(rest == "" || rest[0] == '/' || strings.HasSuffix(route.prefix, "/")) {
+			return route.policy
+		}
+	}
+	return p
+}
 
-		// 生产环境：从配置读取允许的域名
-		//origin := c.Request.Header.Get("Origin")
-		//allowedOrigins := []string{"http://localhost:8080", "https://yourdomain.com"}
-		//for _, allowedOrigin := range allowedOrigins {
-		//	if origin == allowedOrigin {
-		//		c.Header("Access-Control-Allow-Origin", origin)
-		//		break
-		//	}
-		//}
-
-		// 2. 设置 Access-Control-Allow-Methods
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-		// 3. 设置 Access-Control-Allow-Headers
-		c.Header("Access-Control-Allow-Headers",
-			"Origin, X-Requested-With, Content-Type, Accept, Authorization")
-		//c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Max-Age", "86400")
-		// 4. 处理 OPTIONS 预检请求
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+// allowOrigin 判断来源是否被允许
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.exactOrigins[origin] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	for _, w := range p.wildcardOrigins {
+		// 只匹配子域名，不匹配裸域名本身
+		if u.Scheme == w.scheme && u.Port() == w.port &&
+			strings.HasSuffix(u.Hostname(), w.suffix) && len(u.Hostname()) > len(w.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHeaders 判断预检请求中声明的请求头是否都被允许
+func (p *corsPolicy) allowHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if header != "" && !p.allowedHeaders[header] {
+			return false
+		}
+	}
+	return true
+}
+
+// handle 按策略处理跨域请求
+func (p *corsPolicy) handle(c *gin.Context) {
+	origin := c.Request.Header.Get("Origin")
+	preflight := c.Request.Method == http.MethodOptions &&
+		c.Request.Header.Get("Access-Control-Request-Method") != ""
+
+	// 响应内容随 Origin 变化，告知缓存（CDN、浏览器）按 Origin 区分
+	if !p.allowAll || p.allowCredentials {
+		c.Writer.Header().Add("Vary", "Origin")
+	}
+	if preflight {
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	// 非跨域请求直接放行
+	if origin == "" {
+		c.Next()
+		return
+	}
+
+	// 1. 校验来源：不允许的来源不设置 CORS 响应头，预检请求直接拒绝
+	if !p.allowOrigin(origin) {
+		if preflight {
+			c.AbortWithStatus(http.StatusForbidden)
 			return
 		}
 		c.Next()
+		return
+	}
+
+	// 2. 设置 Access-Control-Allow-Origin（携带凭证时必须回显具体来源）
+	if p.allowAll && !p.allowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+
+	// 3. 处理 OPTIONS 预检请求：校验请求方法和请求头
+	if preflight {
+		method := strings.ToUpper(c.Request.Header.Get("Access-Control-Request-Method"))
+		if !p.allowedMethods[method] || !p.allowHeaders(c.Request.Header.Get("Access-Control-Request-Headers")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", p.methodsHeader)
+		c.Header("Access-Control-Allow-Headers", p.headersHeader)
+		c.Header("Access-Control-Max-Age", p.maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	if p.exposeHeader != "" {
+		c.Header("Access-Control-Expose-Headers", p.exposeHeader)
 	}
+	c.Next()
 }
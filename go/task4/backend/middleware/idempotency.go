@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"blog/config"
+	"blog/database"
+	"blog/models"
+	"blog/utils"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// IdempotencyKeyHeader 幂等键请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 响应为重放的首次响应时设置为 true
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255      // 幂等键的最大长度
+	maxIdempotentRequest    = 32 << 20 // 计算指纹时允许的最大请求体
+	maxIdempotentResponse   = 1 << 20  // 保存的最大响应体，更大的响应不保存，重试时重新执行
+)
+
+// errIdempotencyKeyContended 幂等键被反复占用和释放，未能完成占用
+var errIdempotencyKeyContended = errors.New("idempotency key is contended")
+
+// recordingWriter 写出响应的同时保留一份响应体，用于保存首次响应
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write 写出并记录响应体
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写出并记录响应体
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware 幂等键中间件，需放在 AuthMiddleware 之后
+// POST/PUT/DELETE 请求携带 Idempotency-Key 时，同一用户相同键的首次响应保存 ttl 时间：
+// 相同请求的重试直接返回保存的响应（Idempotent-Replayed: true），首次请求未完成时返回 409，
+// 键被用于方法、路径或请求体不同的请求时返回 422；5xx 响应不保存，重试时重新执行
+func IdempotencyMiddleware() gin.HandlerFunc {
+	cfg := config.LoadConfig().Idempotency
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userId, ok := GetUserFromContext(c)
+		if !cfg.Enabled || key == "" || !ok || !idempotentMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			utils.Error(c, utils.CodeBadRequest, utils.MsgIdempotencyKeyInvalid)
+			c.Abort()
+			return
+		}
+
+		// 1. 读取请求体计算请求指纹，再放回供处理函数读取
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentRequest+1))
+		if err != nil {
+			utils.Error(c, utils.CodeBadRequest, utils.MsgBadRequest)
+			c.Abort()
+			return
+		}
+		if len(body) > maxIdempotentRequest {
+			utils.Error(c, utils.CodePayloadTooLarge, "请求体过大")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request, body)
+
+		// 2. 占用幂等键；已被占用时按保存的记录处理
+		// 首次请求的客户端可能已超时断开，保存结果不使用请求的 context
+		db := database.DB.WithContext(context.WithoutCancel(c.Request.Context()))
+		record, created, err := claimIdempotencyKey(db, userId, key, fingerprint, cfg.TTL)
+		if err != nil {
+			log.Println("Blog idempotency error: ", err)
+			utils.Error(c, utils.CodeInternalError, utils.MsgInternalError)
+			c.Abort()
+			return
+		}
+		if !created {
+			switch {
+			case record.Fingerprint != fingerprint:
+				utils.Error(c, utils.CodeUnprocessableEntity, utils.MsgIdempotencyKeyReused)
+			case record.StatusCode == 0:
+				utils.Error(c, utils.CodeConflict, utils.MsgIdempotencyKeyInProgress)
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		// 3. 执行请求并保存响应；处理失败（5xx、panic）时释放幂等键，允许重试
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		saved := false
+		defer func() {
+			if !saved {
+				if err := db.Delete(record).Error; err != nil {
+					log.Println("Blog idempotency error: ", err)
+				}
+			}
+		}()
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || writer.body.Len() > maxIdempotentResponse {
+			return
+		}
+		err = db.Model(record).Updates(map[string]interface{}{
+			"status_code":  status,
+			"content_type": writer.Header().Get("Content-Type"),
+			"body":         writer.body.Bytes(),
+		}).Error
+		if err != nil {
+			log.Println("Blog idempotency error: ", err)
+			return
+		}
+		saved = true
+	}
+}
+
+// claimIdempotencyKey 为用户占用幂等键，created 表示本次请求是首次请求
+// 键已存在时返回已有的记录；已有记录过期时删除后重新占用
+func claimIdempotencyKey(db *gorm.DB, userId uint, key, fingerprint string, ttl time.Duration) (*models.IdempotencyKey, bool, error) {
+	for i := 0; i < 2; i++ {
+		now := time.Now()
+		record := &models.IdempotencyKey{
+			UserID:      userId,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(ttl),
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return record, true, nil
+		}
+
+		var existing models.IdempotencyKey
+		err := db.Where(&models.IdempotencyKey{UserID: userId, Key: key}).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // 刚被释放，重新占用
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if existing.ExpiresAt.After(now) {
+			return &existing, false, nil
+		}
+		if err := db.Where("id = ? AND expires_at < ?", existing.ID, now).Delete(&models.IdempotencyKey{}).Error; err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, errIdempotencyKeyContended
+}
+
+// idempotentMethod 判断请求方法是否支持幂等键
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// validIdempotencyKey 校验幂等键：1 到 255 个可见 ASCII 字符
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestFingerprint 计算请求指纹：请求方法、路径（含查询参数）和请求体的 SHA-256
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
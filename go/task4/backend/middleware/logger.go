@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"blog/config"
 	"log"
 	"time"
 
@@ -10,6 +11,14 @@ import (
 // LoggerMiddleware 请求日志中间件
 // 记录每个HTTP请求的信息和响应时间
 func LoggerMiddleware() gin.HandlerFunc {
+	// 根据日志级别决定记录哪些请求：warn 只记录 4xx/5xx，error 只记录 5xx
+	minStatus := 0
+	switch config.LoadConfig().Log.Level {
+	case "warn":
+		minStatus = 400
+	case "error":
+		minStatus = 500
+	}
 	return func(c *gin.Context) {
 		// TODO: 实现日志记录逻辑
 		// 1. 记录请求开始时间
@@ -23,6 +32,9 @@ func LoggerMiddleware() gin.HandlerFunc {
 		// 4. 记录响应状态码、响应时间
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
+		if statusCode < minStatus {
+			return
+		}
 		//打印日志
 		log.Printf("[GIN] %3d | %13v | %15s | %-7s %s",
 			statusCode, latency, clientIP, method, path)
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryMiddleware 异常恢复中间件
+// 捕获处理函数中的 panic，记录堆栈并返回 500；http.ErrAbortHandler 继续抛给 net/http，
+// 由其直接断开连接而不记录堆栈，供响应已经开始输出、无法再返回错误的流式接口中止响应
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			log.Printf("[Recovery] panic recovered: %v\n%s", err, debug.Stack())
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"blog/audit"
+	"blog/service"
+	"blog/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole 角色校验中间件
+// 必须在 AuthMiddleware 之后使用，当前用户的角色不在 roles 中时返回 403 并记录审计日志；
+// 角色在 two_factor.required_roles 中而用户未启用两步验证时同样返回 403
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+	return func(c *gin.Context) {
+		userId, exists := GetUserFromContext(c)
+		if !exists {
+			utils.Error(c, utils.CodeUnauthorized, utils.MsgUnauthorized)
+			c.Abort()
+			return
+		}
+		role := c.GetString("user_role")
+		if !allowed[role] {
+			audit.Record(c, audit.Entry{
+				ActorID: userId,
+				Action:  audit.ActionForbidden,
+				Detail:  "role " + role + ": " + c.Request.Method + " " + c.Request.URL.Path,
+			})
+			utils.Error(c, utils.CodeForbidden, utils.MsgNoPermission)
+			c.Abort()
+			return
+		}
+		if service.TwoFactorRequired(role) && !c.GetBool("two_factor") {
+			audit.Record(c, audit.Entry{
+				ActorID: userId,
+				Action:  audit.ActionForbidden,
+				Detail:  "2fa required: " + c.Request.Method + " " + c.Request.URL.Path,
+			})
+			utils.Error(c, utils.CodeForbidden, "当前角色需要先启用两步验证")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
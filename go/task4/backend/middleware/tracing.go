@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"blog/config"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// TracingMiddleware 链路追踪中间件
+// 为每个请求创建服务端 Span，并从请求头（traceparent）中提取上游的 Trace 上下文
+func TracingMiddleware() gin.HandlerFunc {
+	cfg := config.LoadConfig()
+	return otelgin.Middleware(cfg.Tracing.ServiceName)
+}
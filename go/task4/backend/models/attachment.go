@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attachment 附件模型
+// 用户上传的图片或文件，文件内容按 SHA-256 存储在对象存储中；上传后通过创建或更新文章关联到文章
+type Attachment struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time `json:"created_at"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"` // 上传者
+	PostID       *uint     `json:"post_id" gorm:"index"`          // 关联的文章，未关联时为空
+	Filename     string    `json:"filename" gorm:"size:255"`      // 上传时的原始文件名，只用于展示
+	ContentType  string    `json:"content_type" gorm:"size:100;not null"`
+	Size         int64     `json:"size" gorm:"not null"`
+	Hash         string    `json:"hash" gorm:"size:64;not null;index"` // 文件内容的 SHA-256
+	Key          string    `json:"-" gorm:"size:255;not null"`         // 对象存储中的 key
+	Width        int       `json:"width,omitempty"`                    // 图片宽度
+	Height       int       `json:"height,omitempty"`                   // 图片高度
+	ThumbnailKey string    `json:"-" gorm:"size:255"`                  // 缩略图的 key，非图片为空
+
+	URL          string `json:"url" gorm:"-"`                     // 访问地址
+	ThumbnailURL string `json:"thumbnail_url,omitempty" gorm:"-"` // 缩略图访问地址
+}
+
+func (a *Attachment) TableName() string {
+	return "zen_attachment"
+}
+
+// AfterFind 查询后钩子
+// 根据 key 生成访问地址
+func (a *Attachment) AfterFind(tx *gorm.DB) error {
+	a.SetURLs()
+	return nil
+}
+
+// SetURLs 根据 key 生成访问地址（文件通过 /uploads/<key> 访问）
+func (a *Attachment) SetURLs() {
+	a.URL = "/uploads/" + a.Key
+	if a.ThumbnailKey != "" {
+		a.ThumbnailURL = "/uploads/" + a.ThumbnailKey
+	}
+}
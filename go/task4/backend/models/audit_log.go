@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogImmutable 审计日志只允许追加，不允许修改或删除
+var ErrAuditLogImmutable = errors.New("audit log is append-only")
+
+// AuditLog 审计日志模型
+// 记录登录、内容变更和鉴权失败等安全相关操作，只追加不修改
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+	ActorID    *uint     `json:"actor_id" gorm:"index"`                // 操作者ID，未登录或认证失败时为空
+	ActorName  string    `json:"actor_name" gorm:"size:50"`            // 操作者用户名（登录失败时为尝试登录的用户名）
+	Action     string    `json:"action" gorm:"size:50;not null;index"` // 操作类型，如 post.update
+	TargetType string    `json:"target_type" gorm:"size:20"`           // 操作对象类型: user、post、comment
+	TargetID   *uint     `json:"target_id"`                            // 操作对象ID
+	IP         string    `json:"ip" gorm:"size:45"`
+	UserAgent  string    `json:"user_agent" gorm:"size:255"`
+	Detail     string    `json:"detail,omitempty" gorm:"size:255"`  // 补充说明，如失败原因、请求路径
+	Before     JSONText  `json:"before,omitempty" gorm:"type:text"` // 操作前的快照
+	After      JSONText  `json:"after,omitempty" gorm:"type:text"`  // 操作后的快照
+}
+
+func (a *AuditLog) TableName() string {
+	return "zen_audit_log"
+}
+
+// BeforeUpdate 更新前钩子
+// 审计日志不允许修改
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete 删除前钩子
+// 审计日志不允许删除
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// JSONText 以文本形式存储的 JSON
+// 数据库中保存为字符串，序列化为 JSON 时原样输出而不是转义后的字符串
+type JSONText string
+
+// MarshalJSON 原样输出 JSON，空值输出 null
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// UnmarshalJSON 保存原始 JSON 文本
+func (j *JSONText) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*j = ""
+		return nil
+	}
+	*j = JSONText(data)
+	return nil
+}
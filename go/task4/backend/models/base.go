@@ -6,6 +6,13 @@ import (
 	"gorm.io/gorm"
 )
 
+// 文章、评论的状态
+const (
+	StatusPublished = "published" // 已发布，公开可见
+	StatusHidden    = "hidden"    // 已被版主或举报自动隐藏
+	StatusPending   = "pending"   // 被垃圾内容过滤判定为可疑，等待版主审核
+)
+
 type BaseModel struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	CreatedAt time.Time      `json:"created_at"`
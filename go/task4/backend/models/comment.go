@@ -11,11 +11,15 @@ import (
 type Comment struct {
 	BaseModel
 	// TODO: 定义字段
-	Content string `json:"content" gorm:"not null"`
+	Content string `json:"content" gorm:"type:text;not null"`
 	UserID  uint   `json:"user_id" gorm:"not null;index"`
+	Version uint   `json:"version" gorm:"not null;default:1"`                      // 乐观锁版本号，每次更新加 1
+	Status  string `json:"status" gorm:"size:20;not null;default:published;index"` // 状态: published、hidden、pending
 	User    *User  `json:"user" gorm:"foreignKey:UserID;references:ID"`
 	PostID  uint   `json:"post_id" gorm:"not null;index"`
 	Post    *Post  `json:"post" gorm:"foreignKey:PostID;references:ID"`
+
+	FlagReason string `json:"flag_reason,omitempty" gorm:"size:255"` // 被垃圾内容过滤判定为可疑的原因
 }
 
 func (c *Comment) TableName() string {
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Follow 关注关系：FollowerID 关注了 FolloweeID
+type Follow struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+	FollowerID uint      `json:"follower_id" gorm:"not null;uniqueIndex:idx_follow_pair"`
+	FolloweeID uint      `json:"followee_id" gorm:"not null;uniqueIndex:idx_follow_pair;index"`
+}
+
+func (f *Follow) TableName() string {
+	return "zen_follow"
+}
+
+// TimelineItem 首页动态收件箱中的一条文章，只在写扩散（timeline.strategy: write）时使用
+// 文章被隐藏或删除后条目保留，读取时与文章表关联过滤；文章被彻底删除时一并删除
+type TimelineItem struct {
+	ID       uint `json:"id" gorm:"primaryKey"`
+	UserID   uint `json:"user_id" gorm:"not null;uniqueIndex:idx_timeline_user_post"` // 收件箱所属用户（粉丝）
+	PostID   uint `json:"post_id" gorm:"not null;uniqueIndex:idx_timeline_user_post;index"`
+	AuthorID uint `json:"author_id" gorm:"not null;index"` // 文章作者，取消关注时据此删除
+}
+
+func (t *TimelineItem) TableName() string {
+	return "zen_timeline_item"
+}
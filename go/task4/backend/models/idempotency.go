@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyKey 幂等键及其首次响应
+// 同一用户的同一个键只执行一次写请求，StatusCode 为 0 表示首次请求仍在处理中
+type IdempotencyKey struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time `json:"created_at"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_user_key,priority:1"`
+	Key         string    `json:"key" gorm:"size:255;not null;uniqueIndex:idx_idempotency_user_key,priority:2"`
+	Fingerprint string    `json:"fingerprint" gorm:"size:64;not null"` // 请求方法、路径和请求体的 SHA-256
+	StatusCode  int       `json:"status_code" gorm:"not null;default:0"`
+	ContentType string    `json:"content_type" gorm:"size:100"`
+	Body        []byte    `json:"-"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+func (k *IdempotencyKey) TableName() string {
+	return "zen_idempotency_key"
+}
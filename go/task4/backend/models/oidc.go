@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// UserIdentity 用户在外部身份提供方（OIDC）的身份
+// 同一提供方的 sub 唯一对应一个用户；首次登录时按已验证的邮箱关联已有用户或自动创建用户
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Issuer    string    `json:"issuer" gorm:"size:255;not null;uniqueIndex:idx_identity_issuer_subject"`  // 提供方标识（iss）
+	Subject   string    `json:"subject" gorm:"size:255;not null;uniqueIndex:idx_identity_issuer_subject"` // 用户在提供方的唯一标识（sub）
+	Email     string    `json:"email" gorm:"size:100"`                                                    // 关联时提供方返回的邮箱
+}
+
+func (i *UserIdentity) TableName() string {
+	return "zen_user_identity"
+}
+
+// OIDCLoginState 进行中的 OIDC 登录
+// 跳转到提供方前创建，回调时按 state 取出并删除；只保存 state 的 SHA-256
+type OIDCLoginState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time `json:"created_at"`
+	StateHash    string    `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Nonce        string    `json:"-" gorm:"size:64;not null"`  // 写入 ID Token 的随机数，防止重放
+	CodeVerifier string    `json:"-" gorm:"size:128;not null"` // PKCE 的 code_verifier
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+func (s *OIDCLoginState) TableName() string {
+	return "zen_oidc_login_state"
+}
@@ -9,12 +9,18 @@ import (
 type Post struct {
 	BaseModel
 	// TODO: 定义字段
-	Title   string `json:"title" gorm:"not null"`
+	Title   string `json:"title" gorm:"size:255;not null"`
 	Content string `json:"content" gorm:"type:text;not null"`
 	UserID  uint   `json:"user_id" gorm:"not null;index"`
+	Version uint   `json:"version" gorm:"not null;default:1"`                      // 乐观锁版本号，每次更新加 1
+	Status  string `json:"status" gorm:"size:20;not null;default:published;index"` // 状态: published、hidden、pending
 	User    *User  `json:"user" gorm:"foreignKey:UserID;references:ID"`
 
-	Comments []Comment `json:"comments" gorm:"foreignKey:PostID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	FlagReason string `json:"flag_reason,omitempty" gorm:"size:255"` // 被垃圾内容过滤判定为可疑的原因
+
+	Comments []Comment `json:"comments" gorm:"foreignKey:PostID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// 附件（文章被彻底删除时解除关联，文件内容可能被其他附件引用，不随之删除）
+	Attachments []Attachment `json:"attachments,omitempty" gorm:"foreignKey:PostID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
 
 func (p *Post) TableName() string {
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// 举报状态
+const (
+	ReportOpen      = "open"      // 待处理
+	ReportResolved  = "resolved"  // 已处理（隐藏、删除或封禁）
+	ReportDismissed = "dismissed" // 已驳回
+)
+
+// Report 举报模型
+// 读者对文章或评论的举报，版主处理后记录处理结果
+type Report struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ReporterID uint       `json:"reporter_id" gorm:"not null;index"`
+	TargetType string     `json:"target_type" gorm:"size:20;not null;index:idx_report_target"` // 举报对象类型: post、comment
+	TargetID   uint       `json:"target_id" gorm:"not null;index:idx_report_target"`
+	Reason     string     `json:"reason" gorm:"size:20;not null"` // 举报原因: spam、abuse、illegal、other
+	Detail     string     `json:"detail,omitempty" gorm:"size:500"`
+	Status     string     `json:"status" gorm:"size:20;not null;default:open;index"` // 状态: open、resolved、dismissed
+	Resolution string     `json:"resolution,omitempty" gorm:"size:20"`               // 处理动作: hide、delete、dismiss、ban
+	ResolvedBy *uint      `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Reporter   *User      `json:"reporter,omitempty" gorm:"foreignKey:ReporterID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (r *Report) TableName() string {
+	return "zen_report"
+}
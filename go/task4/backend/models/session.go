@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Session 登录会话
+// 每次登录（密码、两步验证、OIDC）创建一个会话，JWT 的 jti 指向该会话；删除会话即远程退出，只保存 jti 的 SHA-256
+type Session struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	TokenHash  string    `json:"-" gorm:"size:64;not null;uniqueIndex"` // jti 的 SHA-256（十六进制）
+	Method     string    `json:"method" gorm:"size:20;not null"`        // 登录方式：password、2fa、oidc
+	Device     string    `json:"device" gorm:"size:100"`                // 由 User-Agent 识别出的浏览器和操作系统，如 "Chrome on Windows"
+	UserAgent  string    `json:"user_agent" gorm:"size:255"`
+	IP         string    `json:"ip" gorm:"size:45"` // 最近使用的客户端 IP
+	LastSeenAt time.Time `json:"last_seen_at"`      // 最近使用时间
+	ExpiresAt  time.Time `json:"expires_at" gorm:"not null;index"`
+	Current    bool      `json:"current" gorm:"-"` // 是否为发起请求的会话，只在列表中返回
+}
+
+func (s *Session) TableName() string {
+	return "zen_session"
+}
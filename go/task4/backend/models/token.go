@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// 个人访问令牌的权限范围
+const (
+	ScopeRead          = "read"           // 调用需要认证的读接口
+	ScopeWritePosts    = "write:posts"    // 创建、更新、删除文章，上传附件，管理回收站
+	ScopeWriteComments = "write:comments" // 发表、编辑评论
+	ScopeModerate      = "moderate"       // 调用审核接口（需要版主或管理员角色）
+	ScopeAdmin         = "admin"          // 调用管理员接口（需要管理员角色）
+)
+
+// Scopes 全部权限范围
+var Scopes = []string{ScopeRead, ScopeWritePosts, ScopeWriteComments, ScopeModerate, ScopeAdmin}
+
+// TokenPrefix 个人访问令牌的前缀，用于区分 JWT 和便于密钥扫描工具识别
+const TokenPrefix = "blog_pat_"
+
+// PersonalAccessToken 个人访问令牌
+// 供脚本和机器人代替登录获取的 JWT 使用；只保存令牌的 SHA-256，明文只在创建时返回一次
+type PersonalAccessToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" gorm:"size:20;not null"`                  // 令牌开头几位，用于辨认令牌
+	TokenHash  string     `json:"-" gorm:"size:64;not null;uniqueIndex"`           // 令牌的 SHA-256（十六进制）
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;size:255;not null"` // 权限范围
+	ExpiresAt  *time.Time `json:"expires_at"`                                      // 过期时间，为空表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at"`                                    // 最近使用时间
+	LastUsedIP string     `json:"last_used_ip" gorm:"size:45"`                     // 最近使用的客户端 IP
+}
+
+func (t *PersonalAccessToken) TableName() string {
+	return "zen_personal_access_token"
+}
+
+// HasScope 判断令牌是否拥有 scopes 中的任一权限范围
+func (t *PersonalAccessToken) HasScope(scopes ...string) bool {
+	for _, have := range t.Scopes {
+		for _, want := range scopes {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Expired 判断令牌是否已过期
+func (t *PersonalAccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// RecoveryCode 两步验证的恢复码
+// 无法使用身份验证器时代替验证码登录，每个只能使用一次；只保存 SHA-256
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"used_at"` // 使用时间，为空表示未使用
+}
+
+func (r *RecoveryCode) TableName() string {
+	return "zen_recovery_code"
+}
+
+// TwoFactorChallenge 两步验证登录的挑战
+// 密码验证通过后创建，客户端在有效期内携带挑战令牌提交验证码；只保存令牌的 SHA-256
+type TwoFactorChallenge struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	TokenHash string    `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	Attempts  int       `json:"attempts" gorm:"not null;default:0"` // 已提交的错误验证码次数
+}
+
+func (c *TwoFactorChallenge) TableName() string {
+	return "zen_two_factor_challenge"
+}
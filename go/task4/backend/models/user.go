@@ -3,23 +3,39 @@ package models
 import (
 	"blog/utils"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// 用户角色
+const (
+	RoleUser      = "user"      // 普通用户
+	RoleModerator = "moderator" // 版主
+	RoleAdmin     = "admin"     // 管理员
+)
+
 // User 用户模型
 // 字段：id, username, password, email, timestamps
 type User struct {
 	BaseModel
 	// TODO: 定义字段
-	Name     string `json:"name" gorm:"uniqueIndex;not null"`
-	Password string `json:"-" gorm:"not null;"`
-	Email    string `json:"email" gorm:"uniqueIndex"`
+	// 唯一索引列显式指定长度，MySQL 无法对不定长的 longtext 建索引
+	Name     string     `json:"name" gorm:"size:50;uniqueIndex;not null"`
+	Password string     `json:"-" gorm:"size:255;not null;"`
+	Email    string     `json:"email" gorm:"size:100;uniqueIndex"`
+	Role     string     `json:"role" gorm:"size:20;not null;default:user"` // 角色: user、moderator、admin
+	BannedAt *time.Time `json:"banned_at,omitempty"`                       // 被封禁的时间，为空表示未封禁
+
+	// 两步验证（TOTP）：TOTPSecret 不为空而 TOTPEnabledAt 为空表示已生成密钥、等待验证
+	TOTPSecret    string     `json:"-" gorm:"column:totp_secret;size:64"`
+	TOTPEnabledAt *time.Time `json:"-" gorm:"column:totp_enabled_at"`                   // 启用两步验证的时间，为空表示未启用
+	TOTPLastStep  int64      `json:"-" gorm:"column:totp_last_step;not null;default:0"` // 最近一次使用的验证码时间步，防止重复使用
 
-	//文章
-	Posts []Post `json:"posts" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	//文章（user_id 为 NOT NULL，删除用户时级联删除，SET NULL 在 MySQL/PostgreSQL 上无法成立）
+	Posts []Post `json:"posts" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	//评论
-	Comments []Comment `json:"comments" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Comments []Comment `json:"comments" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
 func (u *User) TableName() string {
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// 投递状态
+const (
+	DeliveryPending   = "pending"   // 等待投递或重试
+	DeliverySucceeded = "succeeded" // 已成功投递（收到 2xx 响应）
+	DeliveryFailed    = "failed"    // 达到最大投递次数或订阅已删除，不再重试
+)
+
+// Webhook 事件订阅
+// 订阅的事件发生时向 URL 发送 POST 请求，请求体使用 Secret 进行 HMAC-SHA256 签名
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	URL       string    `json:"url" gorm:"size:2048;not null"`
+	Events    []string  `json:"events" gorm:"serializer:json;size:255;not null"` // 订阅的事件，如 post.created
+	Secret    string    `json:"-" gorm:"size:100;not null"`                      // 签名密钥，只在创建时返回一次
+	Active    bool      `json:"active" gorm:"not null"`
+	CreatedBy uint      `json:"created_by"` // 创建订阅的管理员ID
+}
+
+func (w *Webhook) TableName() string {
+	return "zen_webhook"
+}
+
+// Subscribes 判断是否订阅了事件
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 一次事件投递
+// 事件发生时为每个订阅创建一条记录，由后台任务投递，失败时按指数退避重试
+type WebhookDelivery struct {
+	ID            uint             `json:"id" gorm:"primaryKey"`
+	CreatedAt     time.Time        `json:"created_at"`
+	WebhookID     uint             `json:"webhook_id" gorm:"not null;index"`
+	Event         string           `json:"event" gorm:"size:50;not null"`
+	Payload       string           `json:"payload" gorm:"type:text;not null"`                                        // 请求体（JSON）
+	Status        string           `json:"status" gorm:"size:20;not null;index:idx_webhook_delivery_due,priority:1"` // pending、succeeded、failed
+	Attempts      int              `json:"attempts" gorm:"not null;default:0"`                                       // 已投递次数
+	NextAttemptAt time.Time        `json:"next_attempt_at" gorm:"index:idx_webhook_delivery_due,priority:2"`         // 下次投递时间
+	DeliveredAt   *time.Time       `json:"delivered_at,omitempty"`                                                   // 成功投递的时间
+	AttemptLog    []WebhookAttempt `json:"attempt_log,omitempty" gorm:"foreignKey:DeliveryID;references:ID"`
+}
+
+func (d *WebhookDelivery) TableName() string {
+	return "zen_webhook_delivery"
+}
+
+// WebhookAttempt 一次投递请求的结果
+type WebhookAttempt struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+	DeliveryID uint      `json:"delivery_id" gorm:"not null;index"`
+	StatusCode int       `json:"status_code"`                     // HTTP 状态码，请求失败（连接错误、超时）时为 0
+	Response   string    `json:"response" gorm:"type:text"`       // 响应体的开头部分
+	Error      string    `json:"error,omitempty" gorm:"size:500"` // 请求失败的原因
+	DurationMS int64     `json:"duration_ms"`                     // 请求耗时（毫秒）
+}
+
+func (a *WebhookAttempt) TableName() string {
+	return "zen_webhook_attempt"
+}
@@ -0,0 +1,302 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	mockCodeTTL   = time.Minute     // 授权码有效期
+	mockTokenTTL  = 5 * time.Minute // ID Token 和 access token 有效期
+	mockKeyLength = 2048
+)
+
+// MockServer 模拟的 OIDC 提供方，用于本地调试和自动化测试，不依赖真实的提供方
+// 授权页只需填写邮箱即可登录（可选择邮箱是否已验证），强制使用 PKCE（S256）；
+// 带上 email 查询参数时跳过授权页直接返回授权码，便于用脚本走完整个流程
+type MockServer struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // 为空时不校验客户端密钥（公开客户端）
+
+	key   *rsa.PrivateKey
+	keyID string // 由公钥生成，重启后密钥变化时 kid 也随之变化
+	mux   *http.ServeMux
+
+	mu     sync.Mutex
+	codes  map[string]*mockGrant // 授权码 -> 授权
+	tokens map[string]*mockGrant // access token -> 授权
+}
+
+// mockGrant 一次授权的信息
+type mockGrant struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	email         string
+	emailVerified bool
+	name          string
+	expiresAt     time.Time
+}
+
+// NewMockServer 创建模拟的 OIDC 提供方，issuer 为对外访问地址（如 http://localhost:9999）
+func NewMockServer(issuer, clientID, clientSecret string) (*MockServer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, mockKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	keySum := sha256.Sum256(key.PublicKey.N.Bytes())
+	m := &MockServer{
+		Issuer:       strings.TrimSuffix(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		keyID:        hex.EncodeToString(keySum[:8]),
+		mux:          http.NewServeMux(),
+		codes:        make(map[string]*mockGrant),
+		tokens:       make(map[string]*mockGrant),
+	}
+	m.mux.HandleFunc("GET "+discoveryPath, m.discovery)
+	m.mux.HandleFunc("GET /authorize", m.authorize)
+	m.mux.HandleFunc("POST /token", m.token)
+	m.mux.HandleFunc("GET /userinfo", m.userinfo)
+	m.mux.HandleFunc("GET /jwks", m.jwks)
+	return m, nil
+}
+
+// ServeHTTP 实现 http.Handler
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.ServeHTTP(w, r)
+}
+
+// discovery 返回发现文档
+func (m *MockServer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                m.Issuer,
+		"authorization_endpoint":                m.Issuer + "/authorize",
+		"token_endpoint":                        m.Issuer + "/token",
+		"userinfo_endpoint":                     m.Issuer + "/userinfo",
+		"jwks_uri":                              m.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+// mockLoginPage 授权页：填写邮箱，选择邮箱是否已验证
+var mockLoginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head><meta charset="UTF-8"><title>Mock OIDC 登录</title></head>
+<body>
+<h1>Mock OIDC 登录</h1>
+<form method="get" action="/authorize">
+{{range $name, $values := .Query}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}<p><label>邮箱 <input type="email" name="email" required></label></p>
+<p><label>昵称 <input type="text" name="name"></label></p>
+<p><label>邮箱已验证 <select name="email_verified"><option value="true">是</option><option value="false">否</option></select></label></p>
+<p><button type="submit">登录</button></p>
+</form>
+</body>
+</html>
+`))
+
+// authorize 授权端点：校验请求参数，没有 email 参数时显示授权页，否则签发授权码并跳转回客户端
+func (m *MockServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	target, err := url.Parse(redirectURI)
+	if err != nil || !target.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case query.Get("client_id") != m.ClientID:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case query.Get("response_type") != "code":
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256":
+		http.Error(w, "PKCE with code_challenge_method=S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(query.Get("email"))
+	if email == "" {
+		delete(query, "email")
+		delete(query, "name")
+		delete(query, "email_verified")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		mockLoginPage.Execute(w, map[string]interface{}{"Query": query})
+		return
+	}
+
+	code, err := RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.mu.Lock()
+	m.codes[code] = &mockGrant{
+		redirectURI:   redirectURI,
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		email:         email,
+		emailVerified: query.Get("email_verified") != "false",
+		name:          query.Get("name"),
+		expiresAt:     time.Now().Add(mockCodeTTL),
+	}
+	m.mu.Unlock()
+
+	params := target.Query()
+	params.Set("code", code)
+	if state := query.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// token 令牌端点：校验客户端、授权码、redirect_uri 和 code_verifier，签发 ID Token
+func (m *MockServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	clientID, clientSecret, basic := r.BasicAuth()
+	if basic {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != m.ClientID || (m.ClientSecret != "" && clientSecret != m.ClientSecret) {
+		tokenError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	// 授权码只能使用一次
+	m.mu.Lock()
+	grant := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+	switch {
+	case grant == nil || time.Now().After(grant.expiresAt):
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "invalid or expired code")
+		return
+	case r.PostForm.Get("redirect_uri") != grant.redirectURI:
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri mismatch")
+		return
+	case CodeChallenge(r.PostForm.Get("code_verifier")) != grant.codeChallenge:
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "code_verifier mismatch")
+		return
+	}
+
+	now := time.Now()
+	claims := m.claims(grant)
+	claims["iss"] = m.Issuer
+	claims["aud"] = m.ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(mockTokenTTL).Unix()
+	if grant.nonce != "" {
+		claims["nonce"] = grant.nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.keyID
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	accessToken, err := RandomString()
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	m.mu.Lock()
+	grant.expiresAt = now.Add(mockTokenTTL)
+	m.tokens[accessToken] = grant
+	m.mu.Unlock()
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(mockTokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+// userinfo 返回 access token 对应的用户信息
+func (m *MockServer) userinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	m.mu.Lock()
+	grant := m.tokens[accessToken]
+	m.mu.Unlock()
+	if grant == nil || time.Now().After(grant.expiresAt) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, m.claims(grant))
+}
+
+// jwks 返回签名 ID Token 的公钥
+func (m *MockServer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := m.key.PublicKey
+	writeJSON(w, http.StatusOK, jwkSet{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: m.keyID,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+// claims 返回授权对应的用户声明，sub 由邮箱生成，同一邮箱每次登录都相同
+func (m *MockServer) claims(grant *mockGrant) jwt.MapClaims {
+	sum := sha256.Sum256([]byte(strings.ToLower(grant.email)))
+	username, _, _ := strings.Cut(grant.email, "@")
+	claims := jwt.MapClaims{
+		"sub":                "mock-" + hex.EncodeToString(sum[:8]),
+		"email":              grant.email,
+		"email_verified":     grant.emailVerified,
+		"preferred_username": username,
+	}
+	if grant.name != "" {
+		claims["name"] = grant.name
+	}
+	return claims
+}
+
+// tokenError 返回 RFC 6749 格式的错误
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+// writeJSON 写出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,344 @@
+package oidc
+
+import (
+	"blog/config"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	discoveryPath     = "/.well-known/openid-configuration"
+	discoveryTTL      = time.Hour        // 发现文档的缓存时间
+	keysRefreshPeriod = time.Minute      // 遇到未知的 kid 时重新获取 JWKS 的最短间隔
+	clockSkew         = time.Minute      // 校验 ID Token 时间时允许的误差
+	maxResponseSize   = 1 << 20          // 提供方响应的最大字节数
+	requestTimeout    = 10 * time.Second // 请求提供方的超时时间
+)
+
+// Discovery 发现文档中用到的字段
+type Discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Claims 登录用户在提供方的身份信息
+type Claims struct {
+	Issuer            string // 提供方标识（iss）
+	Subject           string // 用户在提供方的唯一标识（sub）
+	Email             string
+	EmailVerified     bool // 提供方是否验证过邮箱
+	Name              string
+	PreferredUsername string
+}
+
+// idTokenClaims ID Token 中的声明
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string      `json:"nonce"`
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"` // 部分提供方返回字符串 "true"
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+}
+
+// Provider OIDC 提供方客户端
+// 发现文档和 JWKS 懒加载并缓存，提供方暂时不可用时不影响博客启动
+type Provider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         map[string]interface{} // kid -> 公钥
+	keysAt       time.Time
+}
+
+// New 创建 OIDC 提供方客户端
+func New(cfg config.OIDCConfig) *Provider {
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// RandomString 返回 32 字节随机数的 base64url 编码，用于 state、nonce 和 PKCE 的 code_verifier
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge 返回 PKCE 的 code_challenge（S256）
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthURL 返回跳转到提供方登录页的地址
+func (p *Provider) AuthURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return d.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange 用授权码换取令牌，校验 ID Token 的签名、issuer、audience、有效期和 nonce，返回用户身份
+// ID Token 中没有邮箱时从 userinfo 端点补充
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 用授权码和 code_verifier 换取令牌
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	basicAuth := p.cfg.ClientSecret != "" && p.supportsBasicAuth(d)
+	if !basicAuth {
+		form.Set("client_id", p.cfg.ClientID)
+		if p.cfg.ClientSecret != "" {
+			form.Set("client_secret", p.cfg.ClientSecret)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if basicAuth {
+		// RFC 6749 2.3.1：客户端ID和密钥需要先进行表单编码
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := p.do(req, &tokens); err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	// 2. 校验 ID Token
+	var idClaims idTokenClaims
+	_, err = jwt.ParseWithClaims(tokens.IDToken, &idClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, d, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+	if idClaims.Nonce != nonce {
+		return nil, errors.New("id token: nonce mismatch")
+	}
+	if idClaims.Subject == "" {
+		return nil, errors.New("id token: missing sub")
+	}
+	claims := &Claims{
+		Issuer:            idClaims.Issuer,
+		Subject:           idClaims.Subject,
+		Email:             idClaims.Email,
+		EmailVerified:     truthy(idClaims.EmailVerified),
+		Name:              idClaims.Name,
+		PreferredUsername: idClaims.PreferredUsername,
+	}
+
+	// 3. ID Token 中没有邮箱时从 userinfo 补充（sub 必须一致）
+	if claims.Email == "" && d.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		if err := p.userinfo(ctx, d, tokens.AccessToken, claims); err != nil {
+			return nil, fmt.Errorf("userinfo: %w", err)
+		}
+	}
+	return claims, nil
+}
+
+// Discover 返回发现文档，缓存 1 小时
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+	discoveryURL := p.cfg.DiscoveryURL
+	if !strings.Contains(discoveryURL, "/.well-known/") {
+		discoveryURL = strings.TrimSuffix(discoveryURL, "/") + discoveryPath
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var d Discovery
+	if err := p.do(req, &d); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if d.Issuer == "" || d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("discovery: missing issuer, authorization_endpoint, token_endpoint or jwks_uri")
+	}
+	p.discovery, p.discoveredAt = &d, time.Now()
+	return &d, nil
+}
+
+// key 返回 kid 对应的公钥；找不到时重新获取 JWKS（提供方轮换密钥），但每分钟最多一次
+func (p *Provider) key(ctx context.Context, d *Discovery, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysAt) < keysRefreshPeriod {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set jwkSet
+	if err := p.do(req, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	p.keys, p.keysAt = set.publicKeys(), time.Now()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookupKey 按 kid 查找公钥；ID Token 没有 kid 且 JWKS 只有一个公钥时使用该公钥
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// userinfo 从 userinfo 端点补充邮箱等信息
+func (p *Provider) userinfo(ctx context.Context, d *Discovery, accessToken string, claims *Claims) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.UserinfoEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	var info struct {
+		Subject           string      `json:"sub"`
+		Email             string      `json:"email"`
+		EmailVerified     interface{} `json:"email_verified"`
+		Name              string      `json:"name"`
+		PreferredUsername string      `json:"preferred_username"`
+	}
+	if err := p.do(req, &info); err != nil {
+		return err
+	}
+	if info.Subject != claims.Subject {
+		return errors.New("sub does not match id token")
+	}
+	claims.Email, claims.EmailVerified = info.Email, truthy(info.EmailVerified)
+	if claims.Name == "" {
+		claims.Name = info.Name
+	}
+	if claims.PreferredUsername == "" {
+		claims.PreferredUsername = info.PreferredUsername
+	}
+	return nil
+}
+
+// supportsBasicAuth 判断令牌端点是否支持 client_secret_basic（未声明时默认支持）
+func (p *Provider) supportsBasicAuth(d *Discovery) bool {
+	if len(d.TokenAuthMethods) == 0 {
+		return true
+	}
+	for _, method := range d.TokenAuthMethods {
+		if method == "client_secret_basic" {
+			return true
+		}
+	}
+	return false
+}
+
+// do 发送请求并解析 JSON 响应，非 2xx 响应返回错误
+func (p *Provider) do(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: status %d: %s", req.Method, req.URL.Redacted(), resp.StatusCode, truncate(string(body), 200))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// truthy 解析布尔值声明，兼容 true 和 "true"
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// truncate 截断过长的错误信息
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"blog/oidc"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// runOIDCCommand 处理 oidc 子命令
+// `oidc mock [flags]`：启动模拟的 OIDC 提供方，用于本地调试 OIDC 登录，不依赖真实的提供方
+func runOIDCCommand(args []string) error {
+	if len(args) < 1 || args[0] != "mock" {
+		return fmt.Errorf("usage: blog oidc mock [-addr localhost:9999] [-issuer url] [-client-id id] [-client-secret secret]")
+	}
+	fs := flag.NewFlagSet("oidc mock", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:9999", "监听地址")
+	issuer := fs.String("issuer", "", "对外访问地址（issuer），为空时使用 http://<addr>")
+	clientID := fs.String("client-id", "blog", "客户端ID，与 oidc.client_id 一致")
+	clientSecret := fs.String("client-secret", "blog-secret", "客户端密钥，与 oidc.client_secret 一致，为空时不校验")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *issuer == "" {
+		*issuer = "http://" + *addr
+	}
+
+	server, err := oidc.NewMockServer(*issuer, *clientID, *clientSecret)
+	if err != nil {
+		return err
+	}
+	log.Printf("Blog mock OIDC provider listening on %s (discovery: %s/.well-known/openid-configuration)", *addr, server.Issuer)
+	return http.ListenAndServe(*addr, server)
+}
@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// undocumentedPaths 不需要写入文档的路由：文档页面本身（前端页面通过 NoRoute 处理，不在路由表中）
+var undocumentedPaths = map[string]bool{
+	"/openapi.json": true,
+	"/docs":         true,
+}
+
+// Verify 检查已注册的路由与 OpenAPI 文档是否一致
+// 路由存在但文档缺失、或文档存在但路由缺失时返回错误，错误信息列出所有不一致的接口
+func Verify(routes gin.RoutesInfo) error {
+	registered := make(map[string]bool)
+	for _, route := range routes {
+		if undocumentedPaths[route.Path] {
+			continue
+		}
+		registered[route.Method+" "+ginPathToOpenAPI(route.Path)] = true
+	}
+	documented := make(map[string]bool)
+	for path, item := range Spec().Paths {
+		for method, op := range map[string]*Operation{"GET": item.Get, "POST": item.Post, "PUT": item.Put, "DELETE": item.Delete} {
+			if op != nil {
+				documented[method+" "+path] = true
+			}
+		}
+	}
+
+	var problems []string
+	for route := range registered {
+		if !documented[route] {
+			problems = append(problems, "undocumented route: "+route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			problems = append(problems, "documented but not registered: "+route)
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("openapi spec out of sync with routes:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// ginPathToOpenAPI 将 gin 路由路径转换为 OpenAPI 路径，如 /posts/:id → /posts/{id}
+func ginPathToOpenAPI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
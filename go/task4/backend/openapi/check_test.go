@@ -0,0 +1,68 @@
+package openapi_test
+
+import (
+	"blog/openapi"
+	"blog/routes"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestSpecMatchesRoutes 文档与注册的路由必须一一对应，包括 /api 之外的 GraphQL、订阅源、站点地图和上传文件
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.SetupRoutes(router)
+
+	if err := openapi.Verify(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 非 /api 路由确实参与了检查
+	documented := openapi.Spec().Paths
+	for _, path := range []string{
+		"/graphql",
+		"/feed.rss", "/feed.atom", "/feed.json",
+		"/authors/{id}/feed.rss", "/authors/{id}/feed.atom", "/authors/{id}/feed.json",
+		"/sitemap.xml",
+		"/uploads/{key}",
+	} {
+		if documented[path] == nil {
+			t.Errorf("%s is not documented", path)
+		}
+	}
+}
+
+// TestVerifyReportsMismatches 缺少文档的路由和没有路由的文档都会报错，只有文档页面本身不需要文档
+func TestVerifyReportsMismatches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.SetupRoutes(router)
+	registered := router.Routes()
+
+	extra := append(gin.RoutesInfo{}, registered...)
+	extra = append(extra, gin.RouteInfo{Method: "GET", Path: "/robots.txt"})
+	if err := openapi.Verify(extra); err == nil {
+		t.Error("undocumented route outside /api was not reported")
+	}
+
+	var missing gin.RoutesInfo
+	for _, route := range registered {
+		if route.Path != "/sitemap.xml" {
+			missing = append(missing, route)
+		}
+	}
+	if err := openapi.Verify(missing); err == nil {
+		t.Error("documented route without a handler was not reported")
+	}
+
+	var withoutDocs gin.RoutesInfo
+	for _, route := range registered {
+		if route.Path != "/docs" && route.Path != "/openapi.json" {
+			withoutDocs = append(withoutDocs, route)
+		}
+	}
+	if err := openapi.Verify(withoutDocs); err != nil {
+		t.Errorf("docs pages should be exempt: %v", err)
+	}
+}
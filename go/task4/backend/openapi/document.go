@@ -0,0 +1,298 @@
+package openapi
+
+import "strconv"
+
+// Document OpenAPI 3 文档（只包含本项目用到的字段）
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info 文档基本信息
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server 服务地址
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag 接口分组
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 同一路径下各请求方法的接口
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+// Operation 单个接口
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter 路径、查询或请求头参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path、query、header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header 响应头
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType 某种内容类型的数据结构
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components 可复用的数据结构和认证方式
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme 认证方式
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Schema 数据结构（JSON Schema 子集）
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+}
+
+// securityName JWT 认证方式在 components 中的名称
+const securityName = "bearerAuth"
+
+// ref 引用 components 中的数据结构
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// str 字符串
+func str(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+// strLen 限制长度的字符串
+func strLen(description string, min, max int) *Schema {
+	return &Schema{Type: "string", Description: description, MinLength: &min, MaxLength: &max}
+}
+
+// enum 枚举字符串
+func enum(description string, values ...string) *Schema {
+	return &Schema{Type: "string", Description: description, Enum: values}
+}
+
+// integer 整数
+func integer(description string) *Schema {
+	return &Schema{Type: "integer", Description: description}
+}
+
+// boolean 布尔值
+func boolean(description string) *Schema {
+	return &Schema{Type: "boolean", Description: description}
+}
+
+// dateTime RFC 3339 时间
+func dateTime(description string) *Schema {
+	return &Schema{Type: "string", Format: "date-time", Description: description}
+}
+
+// binary 二进制文件内容（multipart 上传）
+func binary(description string) *Schema {
+	return &Schema{Type: "string", Format: "binary", Description: description}
+}
+
+// nullable 将数据结构标记为可为 null
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+// array 数组
+func array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// mapOf 键为字符串的对象
+func mapOf(values *Schema, description string) *Schema {
+	return &Schema{Type: "object", AdditionalProperties: values, Description: description}
+}
+
+// object 对象，required 为必填字段
+func object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// envelope 统一响应结构 utils.Response，data 为具体数据
+func envelope(data *Schema) *Schema {
+	return &Schema{AllOf: []*Schema{
+		ref("Response"),
+		object(map[string]*Schema{"data": data}, "data"),
+	}}
+}
+
+// newOperation 创建接口描述
+func newOperation(tag, id, summary string) *Operation {
+	return &Operation{
+		Tags:        []string{tag},
+		OperationID: id,
+		Summary:     summary,
+		Responses:   make(map[string]*Response),
+	}
+}
+
+// describe 设置接口的详细说明
+func (o *Operation) describe(description string) *Operation {
+	o.Description = description
+	return o
+}
+
+// auth 接口需要 JWT 认证
+func (o *Operation) auth() *Operation {
+	o.Security = []map[string][]string{{securityName: {}}}
+	return o.fail(401)
+}
+
+// params 添加参数
+func (o *Operation) params(params ...*Parameter) *Operation {
+	o.Parameters = append(o.Parameters, params...)
+	return o
+}
+
+// body 设置 JSON 请求体
+func (o *Operation) body(schema *Schema) *Operation {
+	o.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{"application/json": {Schema: schema}},
+	}
+	return o
+}
+
+// formData 设置 multipart/form-data 请求体
+func (o *Operation) formData(schema *Schema) *Operation {
+	o.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{"multipart/form-data": {Schema: schema}},
+	}
+	return o
+}
+
+// ok 设置 200 响应，data 为统一响应结构中 data 字段的数据结构
+func (o *Operation) ok(data *Schema) *Operation {
+	o.Responses["200"] = &Response{
+		Description: "成功",
+		Content:     map[string]*MediaType{"application/json": {Schema: envelope(data)}},
+	}
+	return o
+}
+
+// response 设置非 JSON 信封格式的响应
+func (o *Operation) response(code int, r *Response) *Operation {
+	o.Responses[strconv.Itoa(code)] = r
+	return o
+}
+
+// fail 添加错误响应，错误响应使用统一响应结构，只有 code 和 message
+func (o *Operation) fail(codes ...int) *Operation {
+	for _, code := range codes {
+		description, ok := errorDescriptions[code]
+		if !ok {
+			description = "错误"
+		}
+		o.Responses[strconv.Itoa(code)] = &Response{
+			Description: description,
+			Content:     map[string]*MediaType{"application/json": {Schema: ref("ErrorResponse")}},
+		}
+	}
+	return o
+}
+
+// errorDescriptions 错误状态码的说明（与 utils 中的状态码常量一致）
+var errorDescriptions = map[int]string{
+	400: "请求参数错误",
+	401: "未授权（Token 缺失、无效或过期）",
+	403: "无权限（非作者、角色不足或账号被封禁）",
+	404: "资源不存在",
+	409: "资源冲突",
+	412: "前置条件失败（If-Match 与当前版本不一致）",
+	413: "上传的文件过大",
+	415: "不支持的文件类型",
+	422: "Idempotency-Key 已用于不同的请求",
+	428: "缺少前置条件（未提供 If-Match 或 version）",
+	500: "服务器内部错误",
+	502: "外部服务（如 OIDC 身份提供方）无法访问或返回错误",
+}
+
+// pathParam 路径参数
+func pathParam(name, description string) *Parameter {
+	return &Parameter{Name: name, In: "path", Description: description, Required: true, Schema: integer("")}
+}
+
+// query 查询参数
+func query(name string, schema *Schema) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: schema.Description, Schema: schema}
+}
+
+// header 请求头参数
+func header(name, description string) *Parameter {
+	return &Parameter{Name: name, In: "header", Description: description, Schema: str("")}
+}
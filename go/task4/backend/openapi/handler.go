@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpecHandler 返回 OpenAPI 文档（JSON）
+// 文档本身不使用统一响应结构，便于 Swagger UI 和代码生成工具直接读取
+func SpecHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, Spec())
+}
+
+// DocsHandler 返回 Swagger UI 页面，页面从 /openapi.json 加载文档
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// swaggerUIPage Swagger UI 页面，静态资源从 CDN 加载
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Blog API 文档</title>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+    <script>
+        window.ui = SwaggerUIBundle({
+            url: '/openapi.json',
+            dom_id: '#swagger-ui',
+            persistAuthorization: true
+        });
+    </script>
+</body>
+</html>
+`
@@ -0,0 +1,933 @@
+package openapi
+
+import "sync"
+
+var (
+	specOnce sync.Once
+	spec     *Document
+)
+
+// Spec 返回博客 API 的 OpenAPI 3 文档
+// 文档只构建一次；新增或修改路由时必须同步修改这里，`blog openapi check` 会检查两者是否一致
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = &Document{
+			OpenAPI: "3.0.3",
+			Info: Info{
+				Title:       "Blog API",
+				Description: "个人博客系统后端接口。所有 JSON 接口都使用统一响应结构 `{code, message, data}`，HTTP 状态码与 code 一致。",
+				Version:     "1.0.0",
+			},
+			Servers: []Server{{URL: "/", Description: "当前服务"}},
+			Tags: []Tag{
+				{Name: "auth", Description: "注册、登录与两步验证"},
+				{Name: "tokens", Description: "个人访问令牌"},
+				{Name: "sessions", Description: "登录会话"},
+				{Name: "users", Description: "用户资料、关注与首页动态"},
+				{Name: "posts", Description: "文章"},
+				{Name: "comments", Description: "评论"},
+				{Name: "uploads", Description: "附件上传"},
+				{Name: "trash", Description: "回收站"},
+				{Name: "moderation", Description: "举报与审核"},
+				{Name: "admin", Description: "管理员"},
+				{Name: "feeds", Description: "订阅源与站点地图"},
+				{Name: "graphql", Description: "GraphQL 接口"},
+			},
+			Paths: make(map[string]*PathItem),
+			Components: Components{
+				Schemas: schemas(),
+				SecuritySchemes: map[string]*SecurityScheme{
+					securityName: {
+						Type:         "http",
+						Scheme:       "bearer",
+						BearerFormat: "JWT",
+						Description:  "登录接口返回的 Token 或个人访问令牌（blog_pat_ 开头），放在 Authorization: Bearer <token> 请求头中",
+					},
+				},
+			},
+		}
+		addAuthPaths(spec)
+		addTokenPaths(spec)
+		addSessionPaths(spec)
+		addUserPaths(spec)
+		addPostPaths(spec)
+		addCommentPaths(spec)
+		addUploadPaths(spec)
+		addTrashPaths(spec)
+		addModerationPaths(spec)
+		addAdminPaths(spec)
+		addFeedPaths(spec)
+		addGraphQLPaths(spec)
+		addIdempotency(spec)
+	})
+	return spec
+}
+
+// add 注册接口，path 使用 OpenAPI 格式（如 /api/posts/{id}）
+func add(doc *Document, method, path string, op *Operation) {
+	item := doc.Paths[path]
+	if item == nil {
+		item = &PathItem{}
+		doc.Paths[path] = item
+	}
+	switch method {
+	case "GET":
+		item.Get = op
+	case "POST":
+		item.Post = op
+	case "PUT":
+		item.Put = op
+	case "DELETE":
+		item.Delete = op
+	default:
+		panic("openapi: unsupported method " + method)
+	}
+}
+
+// 常用参数和响应
+var (
+	pageParams = []*Parameter{
+		query("page", integer("页码，从 1 开始，默认 1")),
+		query("page_size", integer("每页条数")),
+	}
+	msgData = object(map[string]*Schema{"msg": str("操作结果")}, "msg")
+)
+
+// cached 公开读接口的缓存相关参数和响应（ETag / If-None-Match / 304）
+func cached(op *Operation) *Operation {
+	op.params(header("If-None-Match", "上次响应的 ETag，内容未变化时返回 304"))
+	op.Responses["200"].Headers = map[string]*Header{
+		"ETag":          {Description: "响应内容的强 ETag", Schema: str("")},
+		"Cache-Control": {Description: "public, max-age=…, stale-while-revalidate=…", Schema: str("")},
+		"X-Cache":       {Description: "进程内缓存是否命中: HIT 或 MISS", Schema: str("")},
+	}
+	return op.response(304, &Response{Description: "内容未变化"})
+}
+
+// versioned 使用乐观锁的更新接口的版本相关参数和响应
+func versioned(op *Operation) *Operation {
+	op.params(header("If-Match", `期望的版本号，如 "3"、"v3"，或获取文章时返回的 ETag；也可以在请求体中提供 version`))
+	if resp := op.Responses["200"]; resp != nil {
+		resp.Headers = map[string]*Header{
+			"ETag": {Description: `更新后的版本号，如 "v4"，可作为下次更新的 If-Match`, Schema: str("")},
+		}
+	}
+	conflict := &Response{
+		Description: "版本冲突，data.current_version 为当前版本号",
+		Content:     map[string]*MediaType{"application/json": {Schema: envelope(ref("VersionConflict"))}},
+	}
+	return op.response(409, conflict).response(412, conflict).fail(428)
+}
+
+// addIdempotency 为所有需要认证的写接口添加 Idempotency-Key 请求头和相关响应
+func addIdempotency(doc *Document) {
+	for _, item := range doc.Paths {
+		for _, op := range []*Operation{item.Post, item.Put, item.Delete} {
+			if op == nil || op.Security == nil {
+				continue
+			}
+			op.params(header("Idempotency-Key", "幂等键，重试时使用相同的值；重复请求返回首次的响应并带有 Idempotent-Replayed: true"))
+			if _, ok := op.Responses["409"]; !ok {
+				op.fail(409)
+			}
+			op.fail(422)
+		}
+	}
+}
+
+func addAuthPaths(doc *Document) {
+	loginUser := object(map[string]*Schema{
+		"id":    integer("用户ID"),
+		"name":  str("用户名"),
+		"email": str("邮箱"),
+		"role":  ref("Role"),
+	}, "id", "name", "email", "role")
+
+	add(doc, "POST", "/api/auth/register", newOperation("auth", "register", "用户注册").
+		body(object(map[string]*Schema{
+			"name":     strLen("用户名", 3, 20),
+			"email":    str("邮箱"),
+			"password": strLen("密码", 6, 100),
+		}, "name", "email", "password")).
+		ok(object(map[string]*Schema{
+			"id":    integer("用户ID"),
+			"name":  str("用户名"),
+			"email": str("邮箱"),
+		}, "id", "name", "email")).
+		fail(400, 409, 500))
+
+	add(doc, "POST", "/api/auth/login", newOperation("auth", "login", "用户登录").
+		body(object(map[string]*Schema{
+			"name":     str("用户名"),
+			"password": str("密码"),
+		}, "name", "password")).
+		ok(object(map[string]*Schema{
+			"token":                     str("JWT Token，需要两步验证时不返回"),
+			"user":                      loginUser,
+			"two_factor_setup_required": boolean("当前角色必须启用两步验证而用户尚未启用时为 true，启用前无法访问需要该角色的接口"),
+			"two_factor_required":       boolean("账号已启用两步验证时为 true，此时只返回挑战令牌，需调用 /api/auth/login/2fa 完成登录"),
+			"challenge_token":           str("两步验证挑战令牌"),
+			"expires_at":                dateTime("挑战令牌过期时间"),
+		})).
+		describe("被封禁的用户返回 403。账号已启用两步验证时返回 two_factor_required、challenge_token 和 expires_at，不返回 token").
+		fail(400, 401, 403))
+
+	add(doc, "POST", "/api/auth/login/2fa", newOperation("auth", "loginTwoFactor", "两步验证登录").
+		body(object(map[string]*Schema{
+			"challenge_token": str("登录接口返回的挑战令牌"),
+			"code":            str("身份验证器中的 6 位验证码，或一个恢复码"),
+		}, "challenge_token", "code")).
+		ok(object(map[string]*Schema{
+			"token": str("JWT Token"),
+			"user":  loginUser,
+		}, "token", "user")).
+		describe("挑战令牌只能使用一次，最多尝试 5 次；验证码不能重复使用，恢复码使用后失效").
+		fail(400, 401, 403))
+
+	add(doc, "GET", "/api/auth/2fa", newOperation("auth", "getTwoFactor", "获取两步验证状态").
+		auth().
+		ok(ref("TwoFactorStatus")).
+		fail(403, 500))
+
+	add(doc, "POST", "/api/auth/2fa/setup", newOperation("auth", "setupTwoFactor", "生成两步验证密钥").
+		auth().
+		ok(object(map[string]*Schema{
+			"secret": str("Base32 编码的密钥"),
+			"uri":    str("otpauth:// 地址，可生成二维码供身份验证器扫描"),
+		}, "secret", "uri")).
+		describe("只接受登录返回的 JWT。重复调用会替换尚未启用的密钥；已启用时返回 409").
+		fail(403, 409, 500))
+
+	add(doc, "POST", "/api/auth/2fa/enable", newOperation("auth", "enableTwoFactor", "启用两步验证").
+		auth().
+		body(object(map[string]*Schema{
+			"code": str("身份验证器中的 6 位验证码"),
+		}, "code")).
+		ok(object(map[string]*Schema{
+			"recovery_codes": array(str("恢复码，每个只能使用一次")),
+		}, "recovery_codes")).
+		describe("只接受登录返回的 JWT。恢复码只在启用时返回一次").
+		fail(400, 403, 409, 500))
+
+	add(doc, "POST", "/api/auth/2fa/disable", newOperation("auth", "disableTwoFactor", "关闭两步验证").
+		auth().
+		body(object(map[string]*Schema{
+			"password": str("当前密码"),
+			"code":     str("身份验证器中的 6 位验证码，或一个恢复码"),
+		}, "password", "code")).
+		ok(msgData).
+		describe("只接受登录返回的 JWT。关闭后密钥和恢复码全部删除").
+		fail(400, 401, 403, 500))
+
+	add(doc, "POST", "/api/auth/2fa/recovery-codes", newOperation("auth", "regenerateRecoveryCodes", "重新生成恢复码").
+		auth().
+		body(object(map[string]*Schema{
+			"code": str("身份验证器中的 6 位验证码，或一个恢复码"),
+		}, "code")).
+		ok(object(map[string]*Schema{
+			"recovery_codes": array(str("恢复码，每个只能使用一次")),
+		}, "recovery_codes")).
+		describe("只接受登录返回的 JWT。旧的恢复码全部失效").
+		fail(400, 401, 403, 500))
+
+	add(doc, "GET", "/api/auth/oidc", newOperation("auth", "getOIDC", "获取 OIDC 登录配置").
+		ok(object(map[string]*Schema{
+			"enabled": boolean("是否启用 OIDC 登录"),
+			"name":    str("身份提供方名称，用于登录按钮"),
+		}, "enabled", "name")))
+
+	redirect := func(description string) *Response {
+		return &Response{
+			Description: description,
+			Headers:     map[string]*Header{"Location": {Description: "跳转地址", Schema: str("URL")}},
+		}
+	}
+	add(doc, "GET", "/api/auth/oidc/login", newOperation("auth", "oidcLogin", "跳转到身份提供方登录").
+		response(302, redirect("跳转到身份提供方的授权页（授权码流程 + PKCE），同时设置 oidc_state Cookie；出错时跳转到前端页面，URL 片段中带有 error")).
+		describe("由浏览器直接访问，不是 JSON 接口"))
+
+	add(doc, "GET", "/api/auth/oidc/callback", newOperation("auth", "oidcCallback", "身份提供方登录回调").
+		params(
+			query("code", str("授权码")),
+			query("state", str("跳转时生成的 state，需与 oidc_state Cookie 一致")),
+			query("error", str("身份提供方返回的错误，如 access_denied")),
+		).
+		response(302, redirect("跳转到前端页面（oidc.frontend_redirect），结果放在 URL 片段中："+
+			"成功时为 token 和 user（JSON），需要两步验证时为 two_factor_required、challenge_token 和 expires_at（再调用 /api/auth/login/2fa），失败时为 error")).
+		describe("由身份提供方跳转访问。按提供方身份（iss + sub）查找已关联的用户；首次登录时按提供方验证过的邮箱关联已有用户，没有时自动创建用户（oidc.allow_signup）"))
+}
+
+func addTokenPaths(doc *Document) {
+	scopes := enum("权限范围", "read", "write:posts", "write:comments", "moderate", "admin")
+
+	add(doc, "GET", "/api/tokens", newOperation("tokens", "listTokens", "获取个人访问令牌").
+		auth().
+		ok(object(map[string]*Schema{
+			"tokens": array(ref("PersonalAccessToken")),
+			"scopes": array(scopes),
+		}, "tokens", "scopes")).
+		describe("只接受登录返回的 JWT，不返回令牌明文").
+		fail(403, 500))
+
+	add(doc, "POST", "/api/tokens", newOperation("tokens", "createToken", "创建个人访问令牌").
+		auth().
+		body(object(map[string]*Schema{
+			"name":            strLen("令牌名称", 1, 100),
+			"scopes":          array(scopes),
+			"expires_in_days": integer("有效天数（0~365），0 或不传表示永不过期"),
+		}, "name", "scopes")).
+		ok(object(map[string]*Schema{
+			"token":  ref("PersonalAccessToken"),
+			"secret": str("令牌明文，只在创建时返回，之后无法再次查看"),
+		}, "token", "secret")).
+		describe("只接受登录返回的 JWT。令牌放在 Authorization: Bearer <secret> 中使用：GET 接口需要 read，文章写接口（含附件上传、回收站）需要 write:posts，评论写接口需要 write:comments，"+
+			"/api/moderation 和 /api/admin 下的接口（包括查询）分别需要 moderate、admin；令牌管理接口、其他写接口、GraphQL 变更和 gRPC 不接受个人访问令牌").
+		fail(400, 403, 409, 500))
+
+	add(doc, "DELETE", "/api/tokens/{id}", newOperation("tokens", "deleteToken", "撤销个人访问令牌").
+		auth().
+		params(pathParam("id", "令牌ID")).
+		ok(msgData).
+		describe("只接受登录返回的 JWT，只能撤销自己的令牌，撤销后立即失效").
+		fail(403, 404, 500))
+}
+
+func addSessionPaths(doc *Document) {
+	add(doc, "GET", "/api/sessions", newOperation("sessions", "listSessions", "获取登录会话").
+		auth().
+		ok(object(map[string]*Schema{
+			"sessions": array(ref("Session")),
+		}, "sessions")).
+		describe("返回未过期的登录会话，按最近使用时间倒序；每次登录（密码、两步验证、OIDC）创建一个会话，current 标记发起请求的会话").
+		fail(403, 500))
+
+	add(doc, "DELETE", "/api/sessions/{id}", newOperation("sessions", "deleteSession", "退出登录会话").
+		auth().
+		params(pathParam("id", "会话ID")).
+		ok(msgData).
+		describe("只接受登录返回的 JWT，只能退出自己的会话（包括当前会话）。退出后使用该会话 Token 的请求返回 401；多实例部署时其他实例最多延迟 session.cache_ttl 生效").
+		fail(403, 404, 500))
+}
+
+func addUserPaths(doc *Document) {
+	profile := object(map[string]*Schema{"user": ref("Profile")}, "user")
+	follows := object(map[string]*Schema{
+		"users":      array(ref("FollowUser")),
+		"pagination": ref("Pagination"),
+	}, "users", "pagination")
+
+	add(doc, "GET", "/api/users/{id}", newOperation("users", "getUserProfile", "获取用户资料").
+		params(pathParam("id", "用户ID")).
+		ok(profile).
+		describe("公开资料，不包含邮箱").
+		fail(404, 500))
+
+	add(doc, "GET", "/api/users/{id}/followers", newOperation("users", "listFollowers", "获取粉丝列表").
+		params(append([]*Parameter{pathParam("id", "用户ID")}, pageParams...)...).
+		ok(follows).
+		describe("按关注时间倒序；page_size 默认 20，最大 100").
+		fail(404, 500))
+
+	add(doc, "GET", "/api/users/{id}/following", newOperation("users", "listFollowing", "获取关注列表").
+		params(append([]*Parameter{pathParam("id", "用户ID")}, pageParams...)...).
+		ok(follows).
+		describe("按关注时间倒序；page_size 默认 20，最大 100").
+		fail(404, 500))
+
+	add(doc, "POST", "/api/users/{id}/follow", newOperation("users", "followUser", "关注用户").
+		auth().
+		params(pathParam("id", "用户ID")).
+		ok(profile).
+		describe("只接受登录返回的 JWT。重复关注不报错，返回被关注用户的最新资料；不能关注自己，每个用户最多关注 2000 个用户").
+		fail(400, 403, 404, 409, 500))
+
+	add(doc, "DELETE", "/api/users/{id}/follow", newOperation("users", "unfollowUser", "取消关注用户").
+		auth().
+		params(pathParam("id", "用户ID")).
+		ok(profile).
+		describe("只接受登录返回的 JWT。未关注时不报错，返回该用户的最新资料").
+		fail(403, 404, 500))
+
+	add(doc, "GET", "/api/feed", newOperation("users", "getHomeFeed", "获取首页动态").
+		auth().
+		params(
+			query("cursor", str("上一页响应中的 next_cursor，不传时从最新的文章开始")),
+			query("limit", integer("每页条数，默认 10，最大 50")),
+		).
+		ok(object(map[string]*Schema{
+			"posts":       array(ref("Post")),
+			"next_cursor": str("下一页的游标（不透明字符串），为空表示没有更多文章"),
+		}, "posts", "next_cursor")).
+		describe("返回关注的作者已发布的文章，按发布顺序倒序，使用游标分页，翻页期间有新文章发布也不会重复或遗漏。"+
+			"分发策略（timeline.strategy：read 读扩散 / write 写扩散）只影响查询方式，不影响返回结果").
+		fail(400, 403, 500))
+}
+
+func addPostPaths(doc *Document) {
+	postInput := object(map[string]*Schema{
+		"title":          strLen("标题", 2, 100),
+		"content":        strLen("内容", 10, 10000),
+		"attachment_ids": array(integer("通过上传接口上传的附件ID，最多 20 个")),
+	}, "title", "content")
+
+	add(doc, "GET", "/api/posts", cached(newOperation("posts", "listPosts", "获取文章列表").
+		params(pageParams...).
+		ok(object(map[string]*Schema{
+			"posts":      array(ref("Post")),
+			"pagination": ref("Pagination"),
+		}, "posts", "pagination")).
+		describe("只返回已发布的文章，按创建时间倒序；page_size 默认 10，最大 50").
+		fail(500)))
+
+	add(doc, "GET", "/api/posts/{id}", cached(newOperation("posts", "getPost", "获取文章详情").
+		params(pathParam("id", "文章ID")).
+		ok(object(map[string]*Schema{"post": ref("Post")}, "post")).
+		describe(`ETag 形如 "v3-<内容哈希>"，包含文章的版本号，可以直接作为更新文章时的 If-Match`).
+		fail(404)))
+
+	add(doc, "POST", "/api/posts", newOperation("posts", "createPost", "创建文章").
+		auth().
+		body(postInput).
+		ok(object(map[string]*Schema{
+			"post_id": integer("文章ID"),
+			"title":   str("标题"),
+			"status":  ref("ContentStatus"),
+		}, "post_id", "title", "status")).
+		describe("被垃圾内容过滤判定为可疑的文章 status 为 pending，审核通过后公开").
+		fail(400, 403, 500))
+
+	add(doc, "PUT", "/api/posts/{id}", versioned(newOperation("posts", "updatePost", "更新文章").
+		auth().
+		params(pathParam("id", "文章ID")).
+		body(object(map[string]*Schema{
+			"title":          strLen("标题", 2, 100),
+			"content":        strLen("内容", 10, 10000),
+			"attachment_ids": array(integer("关联的附件ID，省略时不修改附件，空数组解除所有附件")),
+			"version":        integer("期望的版本号，未提供 If-Match 时必填"),
+		}, "title", "content")).
+		ok(object(map[string]*Schema{
+			"msg":     str("操作结果"),
+			"version": integer("更新后的版本号"),
+		}, "msg", "version")).
+		describe("只有作者可以更新").
+		fail(400, 403, 404, 500)))
+
+	add(doc, "DELETE", "/api/posts/{id}", newOperation("posts", "deletePost", "删除文章").
+		auth().
+		params(pathParam("id", "文章ID")).
+		ok(msgData).
+		describe("只有作者可以删除；文章及其评论进入回收站").
+		fail(403, 404, 500))
+}
+
+func addUploadPaths(doc *Document) {
+	add(doc, "POST", "/api/uploads", newOperation("uploads", "uploadFile", "上传附件").
+		auth().
+		formData(object(map[string]*Schema{"file": binary("图片或文件")}, "file")).
+		ok(object(map[string]*Schema{"attachment": ref("Attachment")}, "attachment")).
+		describe("根据文件内容识别类型；图片会生成缩略图。返回的 url 和 thumbnail_url 为 /uploads/ 下的长期缓存地址，"+
+			"创建或更新文章时通过 attachment_ids 关联附件").
+		fail(400, 403, 413, 415, 500))
+
+	add(doc, "GET", "/uploads/{key}", newOperation("uploads", "getUpload", "读取上传的文件").
+		params(&Parameter{Name: "key", In: "path", Description: "上传接口返回的 url 中 /uploads/ 之后的部分", Required: true, Schema: str("")}).
+		response(200, &Response{
+			Description: "文件内容；响应带有 ETag 和一年的 Cache-Control: immutable，非图片文件以附件方式下载",
+			Content:     map[string]*MediaType{"application/octet-stream": {Schema: binary("文件")}},
+		}).
+		response(304, &Response{Description: "If-None-Match 与 ETag 一致"}).
+		fail(404))
+}
+
+func addCommentPaths(doc *Document) {
+	add(doc, "GET", "/api/comments/post/{post_id}", cached(newOperation("comments", "listComments", "获取文章评论").
+		params(pathParam("post_id", "文章ID")).
+		ok(object(map[string]*Schema{
+			"comments": array(ref("Comment")),
+			"count":    integer("评论数"),
+		}, "comments", "count")).
+		describe("只返回已发布的评论，按创建时间倒序").
+		fail(400, 404, 500)))
+
+	add(doc, "POST", "/api/comments", newOperation("comments", "createComment", "创建评论").
+		auth().
+		body(object(map[string]*Schema{
+			"post_id": str("文章ID（字符串形式的数字）"),
+			"content": str("评论内容"),
+		}, "post_id", "content")).
+		ok(object(map[string]*Schema{
+			"msg":        str("操作结果"),
+			"comment_id": integer("评论ID"),
+			"status":     ref("ContentStatus"),
+		}, "msg", "comment_id", "status")).
+		describe("被垃圾内容过滤判定为可疑的评论 status 为 pending，审核通过后公开").
+		fail(400, 403, 404, 500))
+
+	add(doc, "PUT", "/api/comments/{id}", versioned(newOperation("comments", "updateComment", "编辑评论").
+		auth().
+		params(pathParam("id", "评论ID")).
+		body(object(map[string]*Schema{
+			"content": str("评论内容"),
+			"version": integer("期望的版本号，未提供 If-Match 时必填"),
+		}, "content")).
+		ok(object(map[string]*Schema{
+			"msg":     str("操作结果"),
+			"version": integer("更新后的版本号"),
+		}, "msg", "version")).
+		describe("只有作者可以编辑").
+		fail(400, 403, 404, 500)))
+}
+
+func addTrashPaths(doc *Document) {
+	add(doc, "GET", "/api/trash/posts", newOperation("trash", "listTrashPosts", "获取回收站中的文章").
+		auth().
+		params(pageParams...).
+		ok(object(map[string]*Schema{
+			"posts":      array(ref("TrashedPost")),
+			"pagination": ref("Pagination"),
+		}, "posts", "pagination")).
+		describe("只返回当前用户的文章，按删除时间倒序").
+		fail(403, 500))
+
+	add(doc, "POST", "/api/trash/posts/{id}/restore", newOperation("trash", "restorePost", "从回收站恢复文章").
+		auth().
+		params(pathParam("id", "文章ID")).
+		ok(object(map[string]*Schema{
+			"msg":               str("操作结果"),
+			"post_id":           integer("文章ID"),
+			"restored_comments": integer("一并恢复的评论数"),
+		}, "msg", "post_id", "restored_comments")).
+		fail(403, 404, 500))
+
+	add(doc, "DELETE", "/api/trash/posts/{id}", newOperation("trash", "purgePost", "彻底删除回收站中的文章").
+		auth().
+		params(pathParam("id", "文章ID")).
+		ok(msgData).
+		describe("文章及其所有评论被永久删除").
+		fail(403, 404, 500))
+}
+
+func addModerationPaths(doc *Document) {
+	targetType := enum("对象类型", "post", "comment")
+
+	add(doc, "POST", "/api/reports", newOperation("moderation", "createReport", "举报文章或评论").
+		auth().
+		body(object(map[string]*Schema{
+			"target_type": targetType,
+			"target_id":   integer("对象ID"),
+			"reason":      enum("举报原因", "spam", "abuse", "illegal", "other"),
+			"detail":      strLen("举报说明", 0, 500),
+		}, "target_type", "target_id", "reason")).
+		ok(object(map[string]*Schema{
+			"msg":       str("操作结果"),
+			"report_id": integer("举报ID"),
+			"hidden":    boolean("本次举报是否触发了自动隐藏"),
+		}, "msg", "report_id", "hidden")).
+		describe("不能举报自己的内容；同一内容已有待处理的举报时返回 409").
+		fail(400, 403, 404, 409, 500))
+
+	add(doc, "GET", "/api/moderation/queue", newOperation("moderation", "getModerationQueue", "审核队列").
+		auth().
+		params(query("target_type", targetType)).
+		params(pageParams...).
+		ok(object(map[string]*Schema{
+			"items":      array(ref("ModerationQueueItem")),
+			"pagination": ref("Pagination"),
+		}, "items", "pagination")).
+		describe("版主和管理员可用；按内容聚合待处理的举报，举报数多的排在前面").
+		fail(403, 500))
+
+	add(doc, "GET", "/api/moderation/reports", newOperation("moderation", "listReports", "举报明细").
+		auth().
+		params(
+			query("target_type", targetType),
+			query("target_id", integer("对象ID")),
+			query("status", ref("ReportStatus")),
+		).
+		params(pageParams...).
+		ok(object(map[string]*Schema{
+			"reports":    array(ref("Report")),
+			"pagination": ref("Pagination"),
+		}, "reports", "pagination")).
+		describe("版主和管理员可用").
+		fail(400, 403, 500))
+
+	add(doc, "GET", "/api/moderation/pending", newOperation("moderation", "listPendingContent", "待审核内容").
+		auth().
+		params(query("target_type", targetType)).
+		params(pageParams...).
+		ok(object(map[string]*Schema{
+			"items": &Schema{
+				Type:        "array",
+				Description: "target_type 为 post 时是 Post 列表，为 comment 时是 Comment 列表",
+				Items:       &Schema{Type: "object"},
+			},
+			"pagination": ref("Pagination"),
+		}, "items", "pagination")).
+		describe("版主和管理员可用；列出被垃圾内容过滤判定为可疑的内容，审核通过使用 dismiss 动作").
+		fail(400, 403, 500))
+
+	add(doc, "POST", "/api/moderation/actions", newOperation("moderation", "moderateTarget", "处理举报").
+		auth().
+		body(object(map[string]*Schema{
+			"target_type": targetType,
+			"target_id":   integer("对象ID"),
+			"action":      enum("处理动作", "hide", "delete", "dismiss", "ban"),
+			"note":        str("备注，记录到审计日志"),
+		}, "target_type", "target_id", "action")).
+		ok(object(map[string]*Schema{
+			"msg":              str("操作结果"),
+			"status":           ref("ContentStatus"),
+			"resolved_reports": integer("关闭的举报数"),
+		}, "msg", "status", "resolved_reports")).
+		describe("版主和管理员可用；该内容所有待处理的举报都会被关闭").
+		fail(400, 403, 404, 500))
+
+	add(doc, "DELETE", "/api/moderation/bans/{id}", newOperation("moderation", "unbanUser", "解除封禁").
+		auth().
+		params(pathParam("id", "用户ID")).
+		ok(msgData).
+		describe("版主和管理员可用").
+		fail(403, 404, 500))
+}
+
+func addAdminPaths(doc *Document) {
+	filters := []*Parameter{
+		query("actor_id", integer("操作者ID")),
+		query("action", str("操作类型，如 post.update")),
+		query("from", dateTime("开始时间（含），RFC 3339")),
+		query("to", dateTime("结束时间（不含），RFC 3339")),
+	}
+
+	add(doc, "GET", "/api/admin/audit-logs", newOperation("admin", "listAuditLogs", "查询审计日志").
+		auth().
+		params(filters...).
+		params(pageParams...).
+		ok(object(map[string]*Schema{
+			"logs":       array(ref("AuditLog")),
+			"pagination": ref("Pagination"),
+		}, "logs", "pagination")).
+		describe("仅管理员可用；按时间倒序").
+		fail(400, 403, 500))
+
+	add(doc, "GET", "/api/admin/audit-logs/export", newOperation("admin", "exportAuditLogs", "导出审计日志").
+		auth().
+		params(filters...).
+		response(200, &Response{
+			Description: "JSON Lines，每行一条 AuditLog，按时间正序",
+			Content:     map[string]*MediaType{"application/x-ndjson": {Schema: ref("AuditLog")}},
+		}).
+		describe("仅管理员可用").
+		fail(400, 403))
+
+	add(doc, "GET", "/api/admin/export", newOperation("admin", "exportContent", "导出全部文章").
+		auth().
+		response(200, &Response{
+			Description: "zip 归档，每篇文章一个 posts/<id>.md 文件（YAML Front Matter 包含作者、时间、状态和评论），可用 `blog import` 导入",
+			Content:     map[string]*MediaType{"application/zip": {Schema: binary("zip 文件")}},
+		}).
+		describe("仅管理员可用；不包含回收站中的文章").
+		fail(403))
+
+	events := enum("事件类型", "post.created", "post.updated", "post.deleted", "comment.created")
+	add(doc, "GET", "/api/admin/webhooks", newOperation("admin", "listWebhooks", "获取事件订阅").
+		auth().
+		ok(object(map[string]*Schema{
+			"webhooks": array(ref("Webhook")),
+			"events":   array(events),
+		}, "webhooks", "events")).
+		describe("仅管理员可用；不返回签名密钥").
+		fail(403, 500))
+
+	add(doc, "POST", "/api/admin/webhooks", newOperation("admin", "createWebhook", "创建事件订阅").
+		auth().
+		body(object(map[string]*Schema{
+			"url":    str("接收事件的 http(s) 地址"),
+			"events": array(events),
+			"secret": str("签名密钥（16~100 个字符），为空时随机生成"),
+		}, "url", "events")).
+		ok(object(map[string]*Schema{
+			"webhook": ref("Webhook"),
+			"secret":  str("签名密钥，只在创建时返回"),
+		}, "webhook", "secret")).
+		describe("仅管理员可用；事件以 POST 请求投递，请求头 X-Blog-Signature-256 为 HMAC-SHA256(secret, \"<X-Blog-Timestamp>.<请求体>\")，失败时按指数退避重试").
+		fail(400, 403, 500))
+
+	add(doc, "DELETE", "/api/admin/webhooks/{id}", newOperation("admin", "deleteWebhook", "删除事件订阅").
+		auth().
+		params(pathParam("id", "订阅ID")).
+		ok(msgData).
+		describe("仅管理员可用；投递记录一并删除").
+		fail(403, 404, 500))
+
+	add(doc, "GET", "/api/admin/webhooks/{id}/deliveries", newOperation("admin", "listWebhookDeliveries", "查询投递记录").
+		auth().
+		params(pathParam("id", "订阅ID"), query("status", enum("投递状态", "pending", "succeeded", "failed"))).
+		params(pageParams...).
+		ok(object(map[string]*Schema{
+			"deliveries": array(ref("WebhookDelivery")),
+			"pagination": ref("Pagination"),
+		}, "deliveries", "pagination")).
+		describe("仅管理员可用；按时间倒序，每条记录包含所有投递请求的状态码、响应和耗时").
+		fail(403, 404, 500))
+}
+
+// schemas 可复用的数据结构，与 models 和 handlers 中的 JSON 字段一致
+func schemas() map[string]*Schema {
+	timestamps := func(props map[string]*Schema) map[string]*Schema {
+		props["id"] = integer("ID")
+		props["created_at"] = dateTime("创建时间")
+		props["updated_at"] = dateTime("更新时间")
+		return props
+	}
+	return map[string]*Schema{
+		"Response": object(map[string]*Schema{
+			"code":    integer("与 HTTP 状态码一致"),
+			"message": str("错误信息，成功时省略"),
+			"data":    {Description: "业务数据，错误时一般省略"},
+		}, "code"),
+		"ErrorResponse": object(map[string]*Schema{
+			"code":    integer("与 HTTP 状态码一致"),
+			"message": str("错误信息"),
+		}, "code", "message"),
+		"VersionConflict": object(map[string]*Schema{
+			"current_version": integer("当前版本号"),
+		}, "current_version"),
+		"Pagination": object(map[string]*Schema{
+			"page":       integer("当前页码"),
+			"page_size":  integer("每页条数"),
+			"total":      integer("总条数"),
+			"total_page": integer("总页数"),
+		}, "page", "page_size", "total", "total_page"),
+		"Role":          enum("用户角色", "user", "moderator", "admin"),
+		"ContentStatus": enum("文章、评论状态", "published", "hidden", "pending"),
+		"ReportStatus":  enum("举报状态", "open", "resolved", "dismissed"),
+		"User": object(timestamps(map[string]*Schema{
+			"name":      str("用户名"),
+			"email":     str("邮箱"),
+			"role":      ref("Role"),
+			"banned_at": nullable(dateTime("封禁时间，未封禁时省略")),
+		})),
+		"Post": object(timestamps(map[string]*Schema{
+			"title":       str("标题"),
+			"content":     str("内容"),
+			"user_id":     integer("作者ID"),
+			"version":     integer("乐观锁版本号"),
+			"status":      ref("ContentStatus"),
+			"flag_reason": str("被垃圾内容过滤判定为可疑的原因"),
+			"user":        nullable(ref("User")),
+			"comments":    nullable(array(ref("Comment"))),
+			"attachments": array(ref("Attachment")),
+		})),
+		"Attachment": object(map[string]*Schema{
+			"id":            integer("ID"),
+			"created_at":    dateTime("上传时间"),
+			"user_id":       integer("上传者ID"),
+			"post_id":       nullable(integer("关联的文章ID，未关联时为 null")),
+			"filename":      str("原始文件名"),
+			"content_type":  str("根据文件内容识别的 MIME 类型"),
+			"size":          integer("字节数"),
+			"hash":          str("文件内容的 SHA-256"),
+			"width":         integer("图片宽度（仅图片）"),
+			"height":        integer("图片高度（仅图片）"),
+			"url":           str("访问地址"),
+			"thumbnail_url": str("缩略图访问地址（仅图片）"),
+		}, "id", "content_type", "size", "hash", "url"),
+		"Comment": object(timestamps(map[string]*Schema{
+			"content":     str("评论内容"),
+			"user_id":     integer("作者ID"),
+			"post_id":     integer("文章ID"),
+			"version":     integer("乐观锁版本号"),
+			"status":      ref("ContentStatus"),
+			"flag_reason": str("被垃圾内容过滤判定为可疑的原因"),
+			"user":        nullable(ref("User")),
+			"post":        nullable(ref("Post")),
+		})),
+		"TrashedPost": {AllOf: []*Schema{
+			ref("Post"),
+			object(map[string]*Schema{
+				"deleted_at": dateTime("删除时间"),
+				"purge_at":   dateTime("预计彻底删除的时间，永久保留时省略"),
+			}, "deleted_at"),
+		}},
+		"Report": object(timestamps(map[string]*Schema{
+			"reporter_id": integer("举报人ID"),
+			"target_type": enum("对象类型", "post", "comment"),
+			"target_id":   integer("对象ID"),
+			"reason":      enum("举报原因", "spam", "abuse", "illegal", "other"),
+			"detail":      str("举报说明"),
+			"status":      ref("ReportStatus"),
+			"resolution":  enum("处理动作", "hide", "delete", "dismiss", "ban"),
+			"resolved_by": integer("处理人ID"),
+			"resolved_at": dateTime("处理时间"),
+			"reporter":    ref("User"),
+		})),
+		"ModerationQueueItem": object(map[string]*Schema{
+			"target_type":       enum("对象类型", "post", "comment"),
+			"target_id":         integer("对象ID"),
+			"report_count":      integer("待处理的举报数"),
+			"reasons":           mapOf(integer(""), "各举报原因的次数"),
+			"first_reported_at": dateTime("最早举报时间"),
+			"last_reported_at":  dateTime("最近举报时间"),
+			"target": nullable(object(map[string]*Schema{
+				"title":   str("文章标题（仅文章）"),
+				"post_id": integer("所属文章ID（仅评论）"),
+				"content": str("内容"),
+				"status":  ref("ContentStatus"),
+				"deleted": boolean("是否已被作者删除"),
+				"author": object(map[string]*Schema{
+					"id":     integer("作者ID"),
+					"name":   str("作者用户名"),
+					"banned": boolean("是否被封禁"),
+				}),
+			})),
+		}, "target_type", "target_id", "report_count", "reasons"),
+		"AuditLog": object(map[string]*Schema{
+			"id":          integer("ID"),
+			"created_at":  dateTime("记录时间"),
+			"actor_id":    nullable(integer("操作者ID，匿名或认证失败时为 null")),
+			"actor_name":  str("操作者用户名"),
+			"action":      str("操作类型，如 auth.login、post.update"),
+			"target_type": str("操作对象类型"),
+			"target_id":   nullable(integer("操作对象ID")),
+			"ip":          str("客户端 IP"),
+			"user_agent":  str("客户端 User-Agent"),
+			"detail":      str("补充说明"),
+			"before":      {Type: "object", Description: "操作前的快照"},
+			"after":       {Type: "object", Description: "操作后的快照"},
+		}, "id", "created_at", "action"),
+		"PersonalAccessToken": object(map[string]*Schema{
+			"id":           integer("ID"),
+			"created_at":   dateTime("创建时间"),
+			"user_id":      integer("所属用户ID"),
+			"name":         str("令牌名称"),
+			"prefix":       str("令牌开头几位，用于辨认"),
+			"scopes":       array(str("权限范围")),
+			"expires_at":   nullable(dateTime("过期时间，为空表示永不过期")),
+			"last_used_at": nullable(dateTime("最近使用时间（精确到分钟）")),
+			"last_used_ip": str("最近使用的客户端 IP"),
+		}, "id", "name", "prefix", "scopes"),
+		"Profile": object(map[string]*Schema{
+			"id":              integer("用户ID"),
+			"name":            str("用户名"),
+			"created_at":      dateTime("注册时间"),
+			"followers_count": integer("粉丝数"),
+			"following_count": integer("关注数"),
+		}, "id", "name", "created_at", "followers_count", "following_count"),
+		"FollowUser": object(map[string]*Schema{
+			"id":          integer("用户ID"),
+			"name":        str("用户名"),
+			"followed_at": dateTime("关注时间"),
+		}, "id", "name", "followed_at"),
+		"Session": object(map[string]*Schema{
+			"id":           integer("ID"),
+			"created_at":   dateTime("登录时间"),
+			"user_id":      integer("所属用户ID"),
+			"method":       enum("登录方式", "password", "2fa", "oidc"),
+			"device":       str("由 User-Agent 识别出的浏览器和操作系统，如 Chrome on Windows"),
+			"user_agent":   str("登录时的 User-Agent"),
+			"ip":           str("最近使用的客户端 IP"),
+			"last_seen_at": dateTime("最近使用时间（精确到分钟）"),
+			"expires_at":   dateTime("过期时间，与 Token 的过期时间一致"),
+			"current":      boolean("是否为发起请求的会话"),
+		}, "id", "created_at", "method", "device", "last_seen_at", "expires_at", "current"),
+		"TwoFactorStatus": object(map[string]*Schema{
+			"enabled":                  boolean("是否已启用两步验证"),
+			"enabled_at":               dateTime("启用时间，未启用时不返回"),
+			"recovery_codes_remaining": integer("未使用的恢复码个数"),
+			"required":                 boolean("当前角色是否必须启用两步验证"),
+		}, "enabled", "recovery_codes_remaining", "required"),
+		"Webhook": object(map[string]*Schema{
+			"id":         integer("ID"),
+			"created_at": dateTime("创建时间"),
+			"updated_at": dateTime("更新时间"),
+			"url":        str("接收事件的地址"),
+			"events":     array(str("事件类型")),
+			"active":     boolean("是否启用"),
+			"created_by": integer("创建订阅的管理员ID"),
+		}, "id", "url", "events", "active"),
+		"WebhookDelivery": object(map[string]*Schema{
+			"id":              integer("ID，即请求头 X-Blog-Delivery"),
+			"created_at":      dateTime("事件发生时间"),
+			"webhook_id":      integer("订阅ID"),
+			"event":           str("事件类型"),
+			"payload":         str("请求体（JSON 字符串）"),
+			"status":          enum("投递状态", "pending", "succeeded", "failed"),
+			"attempts":        integer("已投递次数"),
+			"next_attempt_at": dateTime("下次投递时间"),
+			"delivered_at":    dateTime("成功投递的时间"),
+			"attempt_log": array(object(map[string]*Schema{
+				"id":          integer("ID"),
+				"created_at":  dateTime("请求时间"),
+				"delivery_id": integer("投递记录ID"),
+				"status_code": integer("HTTP 状态码，请求失败时为 0"),
+				"response":    str("响应体的开头部分"),
+				"error":       str("请求失败的原因"),
+				"duration_ms": integer("请求耗时（毫秒）"),
+			}, "id", "created_at", "status_code")),
+		}, "id", "webhook_id", "event", "status", "attempts"),
+	}
+}
+
+func addFeedPaths(doc *Document) {
+	formats := []struct {
+		ext, id, name, contentType string
+	}{
+		{"rss", "Rss", "RSS 2.0", "application/rss+xml"},
+		{"atom", "Atom", "Atom", "application/atom+xml"},
+		{"json", "Json", "JSON Feed 1.1", "application/feed+json"},
+	}
+	for _, format := range formats {
+		feedResponse := &Response{
+			Description: format.name + " 订阅源，Last-Modified 为最近一篇文章的更新时间",
+			Content:     map[string]*MediaType{format.contentType: {Schema: str(format.name + " 文档")}},
+		}
+		add(doc, "GET", "/feed."+format.ext, cached(newOperation("feeds", "siteFeed"+format.id, "站点 "+format.name+" 订阅源").
+			params(header("If-Modified-Since", "上次响应的 Last-Modified，没有 If-None-Match 时使用")).
+			response(200, feedResponse).
+			describe("包含最新发布的 feed.max_items 篇文章").
+			fail(500)))
+		add(doc, "GET", "/authors/{id}/feed."+format.ext, cached(newOperation("feeds", "authorFeed"+format.id, "作者 "+format.name+" 订阅源").
+			params(pathParam("id", "用户ID"), header("If-Modified-Since", "上次响应的 Last-Modified，没有 If-None-Match 时使用")).
+			response(200, feedResponse).
+			describe("包含该作者最新发布的 feed.max_items 篇文章").
+			fail(404, 500)))
+	}
+
+	add(doc, "GET", "/sitemap.xml", cached(newOperation("feeds", "sitemap", "站点地图").
+		response(200, &Response{
+			Description: "sitemaps.org 格式的站点地图，包含首页和所有已发布的文章及其最后修改时间",
+			Content:     map[string]*MediaType{"application/xml": {Schema: str("XML 文档")}},
+		}).
+		fail(500)))
+}
+
+func addGraphQLPaths(doc *Document) {
+	result := &Response{
+		Description: "GraphQL 标准结构 {data, errors}，不使用统一响应结构；请求无效时也返回该结构，状态码为 400 / 401 / 403 / 405",
+		Content: map[string]*MediaType{"application/json": {Schema: object(map[string]*Schema{
+			"data":   {Type: "object", Description: "查询结果"},
+			"errors": array(object(map[string]*Schema{"message": str("错误信息")}, "message")),
+		})}},
+	}
+	description := "携带 Authorization 头时以该用户身份执行，Token 无效时返回 401；个人访问令牌只能执行查询且需要 read 权限。" +
+		"查询深度和复杂度受 graphql.max_depth、graphql.max_complexity 限制"
+
+	add(doc, "GET", "/graphql", newOperation("graphql", "graphqlQuery", "执行 GraphQL 查询").
+		params(
+			query("query", str("GraphQL 查询文档")),
+			query("operationName", str("要执行的操作名")),
+			query("variables", str("JSON 对象形式的变量")),
+		).
+		response(200, result).
+		describe(description+"；GET 只允许查询操作，变更操作返回 405"))
+
+	add(doc, "POST", "/graphql", newOperation("graphql", "graphqlExecute", "执行 GraphQL 查询或变更").
+		body(object(map[string]*Schema{
+			"query":         str("GraphQL 文档"),
+			"operationName": str("要执行的操作名"),
+			"variables":     {Type: "object", Description: "变量"},
+		}, "query")).
+		response(200, result).
+		describe(description+"。启用了两步验证的账号 login 变更返回 twoFactorRequired 和 challengeToken，再用 verifyTwoFactor 变更提交验证码获取 Token"))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"blog/openapi"
+	"blog/routes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runOpenAPICommand 处理 openapi 子命令
+// `openapi print` 打印 OpenAPI 文档，`openapi check` 检查文档与已注册的路由是否一致（不一致时退出码为 1，可用于 CI）
+func runOpenAPICommand(args []string) error {
+	if len(args) == 0 || (args[0] != "print" && args[0] != "check") {
+		return fmt.Errorf("usage: blog openapi print|check")
+	}
+	if args[0] == "print" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(openapi.Spec())
+	}
+
+	// 只注册路由不启动服务，无需初始化数据库
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	routes.SetupRoutes(router)
+	if err := openapi.Verify(router.Routes()); err != nil {
+		return err
+	}
+	fmt.Println("openapi spec matches registered routes")
+	return nil
+}
@@ -1,8 +1,12 @@
 package routes
 
 import (
+	"blog/cache"
+	"blog/gql"
 	"blog/handlers"
 	"blog/middleware"
+	"blog/models"
+	"blog/openapi"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,52 +15,164 @@ import (
 // 主路由注册函数，配置所有API端点和中间件
 func SetupRoutes(r *gin.Engine) {
 	// 实现路由注册逻辑
-	// 1. 应用全局中间件（CORS、日志）
-	r.Use(middleware.CORSMiddleware(), gin.Recovery(), middleware.LoggerMiddleware())
-
-	// 使用相对路径（从 backend 目录出发）
-	r.Static("/css", "../frontend/css")
-	r.Static("/js", "../frontend/js")
-	r.StaticFile("/", "../frontend/index.html")
-	r.StaticFile("/index.html", "../frontend/index.html")
-	r.StaticFile("/pages/login.html", "../frontend/pages/login.html")
-	r.StaticFile("/pages/register.html", "../frontend/pages/register.html")
-	r.StaticFile("/pages/create-post.html", "../frontend/pages/create-post.html")
-	r.StaticFile("/pages/post-detail.html", "../frontend/pages/post-detail.html")
+	// 1. 应用全局中间件（链路追踪、CORS、异常恢复、日志）
+	r.Use(middleware.TracingMiddleware(), middleware.CORSMiddleware(), middleware.RecoveryMiddleware(), middleware.LoggerMiddleware())
+
+	// 前端页面和静态资源（编译时嵌入，未匹配其他路由的 GET 请求由此处理，前端路由回退到 index.html）
+	r.NoRoute(handlers.ServeFrontend)
+
+	// API 文档（OpenAPI 3 文档和 Swagger UI 页面）
+	r.GET("/openapi.json", openapi.SpecHandler)
+	r.GET("/docs", openapi.DocsHandler)
+
+	// 上传的文件
+	r.GET("/uploads/*key", handlers.ServeUpload)
+
+	// 订阅源和站点地图（公开只读，与文章列表使用相同的缓存策略）
+	setupFeedRoutes(r.Group("", middleware.CacheMiddleware(cache.TagPost, cache.TagUser)))
+
+	// GraphQL 接口（与 REST 接口共用业务逻辑，写入后同样在短时间内读主库）
+	graphqlHandler := gql.Handler()
+	r.GET("/graphql", middleware.ReadAfterWriteMiddleware(), graphqlHandler)
+	r.POST("/graphql", middleware.ReadAfterWriteMiddleware(), graphqlHandler)
 
 	// 2. 创建API路由组 /api
-	api := r.Group("/api")
+	// 需要认证的写接口在 AuthMiddleware 之后注册 IdempotencyMiddleware，支持 Idempotency-Key 请求头；
+	// AuthMiddleware 的参数为个人访问令牌调用该写接口所需的权限范围
+	api := r.Group("/api", middleware.ReadAfterWriteMiddleware())
 	{ // 3. 注册各功能模块的路由
 		setupAuthRoutes(api)
+		setupTokenRoutes(api)
+		setupSessionRoutes(api)
+		setupUserRoutes(api)
 		setupPostRoutes(api)
 		setupCommentRoutes(api)
+		setupUploadRoutes(api)
+		setupTrashRoutes(api)
+		setupReportRoutes(api)
+		setupAdminRoutes(api)
 	}
 
 }
 
 // setupAuthRoutes 注册认证路由
-// 注册用户注册和登录相关的路由
+// 注册用户注册、登录（含 OIDC 登录）和两步验证相关的路由
 func setupAuthRoutes(r *gin.RouterGroup) {
 	//实现认证路由注册
 	r.POST("/auth/register", handlers.Register)
 	r.POST("/auth/login", handlers.Login)
+	r.POST("/auth/login/2fa", handlers.LoginTwoFactor)
+
+	// OIDC 登录：浏览器跳转到身份提供方，回调后跳转回前端页面
+	r.GET("/auth/oidc", handlers.GetOIDC)
+	r.GET("/auth/oidc/login", handlers.OIDCLogin)
+	r.GET("/auth/oidc/callback", handlers.OIDCCallback)
+
+	// 两步验证的启用、关闭和恢复码管理（个人访问令牌只能查询状态）
+	twoFactor := r.Group("/auth/2fa", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware())
+	twoFactor.GET("", handlers.GetTwoFactor)
+	twoFactor.POST("/setup", handlers.SetupTwoFactor)
+	twoFactor.POST("/enable", handlers.EnableTwoFactor)
+	twoFactor.POST("/disable", handlers.DisableTwoFactor)
+	twoFactor.POST("/recovery-codes", handlers.RegenerateRecoveryCodes)
+}
+
+// setupTokenRoutes 注册个人访问令牌路由
+// 令牌只能通过登录的 JWT 查看、创建和撤销，个人访问令牌本身不能查看或创建令牌
+func setupTokenRoutes(r *gin.RouterGroup) {
+	tokens := r.Group("/tokens", middleware.AuthMiddleware(middleware.LoginOnly), middleware.IdempotencyMiddleware())
+	tokens.GET("", handlers.GetTokens)
+	tokens.POST("", handlers.CreateToken)
+	tokens.DELETE("/:id", handlers.DeleteToken)
+}
+
+// setupSessionRoutes 注册登录会话路由
+// 查看登录设备和远程退出；个人访问令牌只能查看，不能退出会话
+func setupSessionRoutes(r *gin.RouterGroup) {
+	sessions := r.Group("/sessions", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware())
+	sessions.GET("", handlers.GetSessions)
+	sessions.DELETE("/:id", handlers.DeleteSession)
+}
+
+// setupUserRoutes 注册用户资料、关注和首页动态路由
+// 资料、粉丝和关注列表公开访问；关注、取消关注只接受登录的 JWT，首页动态也可以使用带 read 权限的个人访问令牌
+func setupUserRoutes(r *gin.RouterGroup) {
+	r.GET("/users/:id", handlers.GetUserProfile)
+	r.GET("/users/:id/followers", handlers.GetFollowers)
+	r.GET("/users/:id/following", handlers.GetFollowing)
+	r.POST("/users/:id/follow", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), handlers.FollowUser)
+	r.DELETE("/users/:id/follow", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), handlers.UnfollowUser)
+	r.GET("/feed", middleware.AuthMiddleware(), handlers.GetHomeFeed)
 }
 
 // setupPostRoutes 注册文章路由
 // 注册文章CRUD相关的路由
 func setupPostRoutes(r *gin.RouterGroup) {
 	// TODO: 实现文章路由注册
-	r.GET("/posts", handlers.GetPosts)
-	r.GET("/posts/:id", handlers.GetPost)
-	r.POST("/posts", middleware.AuthMiddleware(), handlers.CreatePost)
-	r.PUT("/posts/:id", middleware.AuthMiddleware(), handlers.UpdatePost)
-	r.DELETE("/posts/:id", middleware.AuthMiddleware(), handlers.DeletePost)
+	r.GET("/posts", middleware.CacheMiddleware(cache.TagPost, cache.TagUser), handlers.GetPosts)
+	r.GET("/posts/:id", middleware.CacheMiddleware(cache.TagPost, cache.TagUser), handlers.GetPost)
+	r.POST("/posts", middleware.AuthMiddleware(models.ScopeWritePosts), middleware.IdempotencyMiddleware(), handlers.CreatePost)
+	r.PUT("/posts/:id", middleware.AuthMiddleware(models.ScopeWritePosts), middleware.IdempotencyMiddleware(), handlers.UpdatePost)
+	r.DELETE("/posts/:id", middleware.AuthMiddleware(models.ScopeWritePosts), middleware.IdempotencyMiddleware(), handlers.DeletePost)
 }
 
 // setupCommentRoutes 注册评论路由
-// 注册评论创建和查询相关的路由
+// 注册评论创建、编辑和查询相关的路由
 func setupCommentRoutes(r *gin.RouterGroup) {
 	// TODO: 实现评论路由注册
-	r.GET("/comments/post/:post_id", handlers.GetCommentsByPost)
-	r.POST("/comments", middleware.AuthMiddleware(), handlers.CreateComment)
+	r.GET("/comments/post/:post_id", middleware.CacheMiddleware(cache.TagPost, cache.TagComment, cache.TagUser), handlers.GetCommentsByPost)
+	r.POST("/comments", middleware.AuthMiddleware(models.ScopeWriteComments), middleware.IdempotencyMiddleware(), handlers.CreateComment)
+	r.PUT("/comments/:id", middleware.AuthMiddleware(models.ScopeWriteComments), middleware.IdempotencyMiddleware(), handlers.UpdateComment)
+}
+
+// setupFeedRoutes 注册订阅源路由
+// 注册站点和作者的 RSS、Atom、JSON Feed 以及站点地图
+func setupFeedRoutes(r *gin.RouterGroup) {
+	for _, format := range []string{"rss", "atom", "json"} {
+		r.GET("/feed."+format, handlers.SiteFeed(format))
+		r.GET("/authors/:id/feed."+format, handlers.AuthorFeed(format))
+	}
+	r.GET("/sitemap.xml", handlers.Sitemap)
+}
+
+// setupUploadRoutes 注册上传路由
+// 注册附件上传的路由，上传后的文件通过 /uploads/<key> 访问
+func setupUploadRoutes(r *gin.RouterGroup) {
+	r.POST("/uploads", middleware.AuthMiddleware(models.ScopeWritePosts), middleware.IdempotencyMiddleware(), handlers.UploadFile)
+}
+
+// setupTrashRoutes 注册回收站路由
+// 注册作者查看、恢复和彻底删除已删除文章的路由，个人访问令牌的查看也需要 write:posts
+func setupTrashRoutes(r *gin.RouterGroup) {
+	trash := r.Group("/trash", middleware.AuthMiddleware(models.ScopeWritePosts), middleware.IdempotencyMiddleware())
+	trash.GET("/posts", handlers.GetTrashPosts)
+	trash.POST("/posts/:id/restore", handlers.RestorePost)
+	trash.DELETE("/posts/:id", handlers.PurgePost)
+}
+
+// setupReportRoutes 注册举报与审核路由
+// 注册读者举报和版主处理审核队列相关的路由；个人访问令牌调用审核接口（包括查询）需要 moderate
+func setupReportRoutes(r *gin.RouterGroup) {
+	r.POST("/reports", middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(), handlers.CreateReport)
+
+	moderation := r.Group("/moderation", middleware.AuthMiddleware(models.ScopeModerate), middleware.RequireRole(models.RoleModerator, models.RoleAdmin),
+		middleware.IdempotencyMiddleware())
+	moderation.GET("/queue", handlers.GetModerationQueue)
+	moderation.GET("/reports", handlers.GetReports)
+	moderation.GET("/pending", handlers.GetPendingContent)
+	moderation.POST("/actions", handlers.ModerateTarget)
+	moderation.DELETE("/bans/:id", handlers.UnbanUser)
+}
+
+// setupAdminRoutes 注册管理员路由
+// 注册审计日志查询和导出、文章导出、事件订阅管理等仅管理员可用的路由；个人访问令牌调用（包括查询）需要 admin
+func setupAdminRoutes(r *gin.RouterGroup) {
+	admin := r.Group("/admin", middleware.AuthMiddleware(models.ScopeAdmin), middleware.RequireRole(models.RoleAdmin), middleware.IdempotencyMiddleware())
+	admin.GET("/audit-logs", handlers.GetAuditLogs)
+	admin.GET("/audit-logs/export", handlers.ExportAuditLogs)
+	admin.GET("/export", handlers.ExportContent)
+	admin.GET("/webhooks", handlers.GetWebhooks)
+	admin.POST("/webhooks", handlers.CreateWebhook)
+	admin.DELETE("/webhooks/:id", handlers.DeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", handlers.GetWebhookDeliveries)
 }
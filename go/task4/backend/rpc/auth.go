@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"blog/models"
+	"blog/rpc/blogpb"
+	"blog/service"
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// publicMethods 无需认证的方法，与 REST 中未使用 AuthMiddleware 的接口一致
+var publicMethods = map[string]bool{
+	blogpb.AuthService_Register_FullMethodName:        true,
+	blogpb.AuthService_Login_FullMethodName:           true,
+	blogpb.AuthService_VerifyTwoFactor_FullMethodName: true,
+	blogpb.PostService_ListPosts_FullMethodName:       true,
+	blogpb.PostService_GetPost_FullMethodName:         true,
+	blogpb.CommentService_ListComments_FullMethodName: true,
+}
+
+// userKey context 中当前用户的键
+type userKey struct{}
+
+// authInterceptor JWT 认证拦截器，与 middleware.AuthMiddleware 等价，但不接受个人访问令牌
+// 从 metadata 的 authorization（Bearer <token>）中读取 Token，校验通过后将当前用户放入 context
+func authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	user, token, err := service.Authenticate(ctx, authorization)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	// 个人访问令牌只用于 REST 接口
+	if token != nil {
+		return nil, toStatus(service.DenyScope(ctx, user, token))
+	}
+	return handler(context.WithValue(ctx, userKey{}, user), req)
+}
+
+// currentUser 返回认证拦截器放入 context 的当前用户
+func currentUser(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userKey{}).(*models.User)
+	return user
+}
@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"blog/models"
+	"blog/rpc/blogpb"
+	"blog/service"
+	"blog/utils"
+	"errors"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcCodes service 错误码（HTTP 状态码）对应的 gRPC 状态码
+var grpcCodes = map[int]codes.Code{
+	utils.CodeBadRequest:           codes.InvalidArgument,
+	utils.CodeUnauthorized:         codes.Unauthenticated,
+	utils.CodeForbidden:            codes.PermissionDenied,
+	utils.CodeNotFound:             codes.NotFound,
+	utils.CodeConflict:             codes.AlreadyExists,
+	utils.CodePreconditionFailed:   codes.FailedPrecondition,
+	utils.CodePreconditionRequired: codes.FailedPrecondition,
+	utils.CodeInternalError:        codes.Internal,
+}
+
+// toStatus 将 service 返回的错误转换为 gRPC 状态
+// 版本冲突返回 ABORTED，并在 ErrorInfo 中附带当前版本号（metadata.current_version）
+func toStatus(err error) error {
+	var conflict *service.VersionConflictError
+	if errors.As(err, &conflict) {
+		st := status.New(codes.Aborted, conflict.Error())
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   "VERSION_CONFLICT",
+			Domain:   "blog",
+			Metadata: map[string]string{"current_version": strconv.FormatUint(uint64(conflict.Current), 10)},
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+	code, message := service.Status(err)
+	grpcCode, ok := grpcCodes[code]
+	if !ok {
+		grpcCode = codes.Unknown
+	}
+	return status.Error(grpcCode, message)
+}
+
+// toUser 转换用户，user 为空时返回 nil
+func toUser(user *models.User) *blogpb.User {
+	if user == nil {
+		return nil
+	}
+	return &blogpb.User{
+		Id:        uint64(user.ID),
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+}
+
+// toLoginResponse 转换登录成功的结果
+func toLoginResponse(token string, user *models.User) *blogpb.LoginResponse {
+	return &blogpb.LoginResponse{
+		Token:                  token,
+		User:                   toUser(user),
+		TwoFactorSetupRequired: user.TOTPEnabledAt == nil && service.TwoFactorRequired(user.Role),
+	}
+}
+
+// toPost 转换文章
+func toPost(post *models.Post) *blogpb.Post {
+	return &blogpb.Post{
+		Id:        uint64(post.ID),
+		Title:     post.Title,
+		Content:   post.Content,
+		UserId:    uint64(post.UserID),
+		Version:   uint64(post.Version),
+		Status:    post.Status,
+		CreatedAt: timestamppb.New(post.CreatedAt),
+		UpdatedAt: timestamppb.New(post.UpdatedAt),
+		Author:    toUser(post.User),
+	}
+}
+
+// toComment 转换评论
+func toComment(comment *models.Comment) *blogpb.Comment {
+	return &blogpb.Comment{
+		Id:        uint64(comment.ID),
+		Content:   comment.Content,
+		UserId:    uint64(comment.UserID),
+		PostId:    uint64(comment.PostID),
+		Version:   uint64(comment.Version),
+		Status:    comment.Status,
+		CreatedAt: timestamppb.New(comment.CreatedAt),
+		UpdatedAt: timestamppb.New(comment.UpdatedAt),
+		Author:    toUser(comment.User),
+	}
+}
+
+// toPagination 转换分页信息
+func toPagination(p service.Pagination) *blogpb.Pagination {
+	return &blogpb.Pagination{
+		Page:      int32(p.Page),
+		PageSize:  int32(p.PageSize),
+		Total:     p.Total,
+		TotalPage: int32(p.TotalPage()),
+	}
+}
+
+// expectedVersion 读取请求中必填的版本号，未提供时返回与 REST 428 对应的 FAILED_PRECONDITION
+func expectedVersion(version *uint64) (*uint, error) {
+	if version == nil {
+		return nil, status.Error(codes.FailedPrecondition, utils.MsgVersionRequired)
+	}
+	v := uint(*version)
+	return &v, nil
+}
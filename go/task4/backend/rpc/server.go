@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/rpc/blogpb"
+	"context"
+	"log"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer 创建 gRPC 服务器并注册认证、文章、评论服务
+// 拦截器依次负责：恢复 panic、记录日志和审计所需的客户端信息、JWT 认证
+func NewServer() *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		recoveryInterceptor,
+		loggerInterceptor(),
+		authInterceptor,
+	))
+	blogpb.RegisterAuthServiceServer(server, authServer{})
+	blogpb.RegisterPostServiceServer(server, postServer{})
+	blogpb.RegisterCommentServiceServer(server, commentServer{})
+	// 支持 grpcurl 等工具查询接口定义
+	reflection.Register(server)
+	return server
+}
+
+// recoveryInterceptor 将处理请求时的 panic 转换为 INTERNAL 错误，避免整个进程退出
+func recoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Blog gRPC panic: %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "服务器内部错误")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// loggerInterceptor 请求日志拦截器
+// 与 HTTP 的 LoggerMiddleware 一致：warn 只记录失败的请求，error 只记录服务端错误；
+// 同时把客户端 IP、User-Agent 和方法名放入 context，供审计日志使用
+func loggerInterceptor() grpc.UnaryServerInterceptor {
+	level := config.LoadConfig().Log.Level
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		client := audit.Client{Resource: info.FullMethod}
+		if p, ok := peer.FromContext(ctx); ok {
+			client.IP = hostOf(p.Addr.String())
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("user-agent"); len(values) > 0 {
+				client.UserAgent = values[0]
+			}
+		}
+
+		resp, err := handler(audit.WithClient(ctx, client), req)
+
+		code := status.Code(err)
+		if (level == "warn" && code == codes.OK) || (level == "error" && !isServerError(code)) {
+			return resp, err
+		}
+		log.Printf("[GRPC] %-16s | %13v | %15s | %s", code, time.Since(start), client.IP, info.FullMethod)
+		return resp, err
+	}
+}
+
+// isServerError 是否为服务端错误（对应 HTTP 5xx）
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+// hostOf 去掉地址中的端口，解析失败时原样返回
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
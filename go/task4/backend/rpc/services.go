@@ -0,0 +1,158 @@
+package rpc
+
+import (
+	"blog/rpc/blogpb"
+	"blog/service"
+	"context"
+	"errors"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// authServer 认证服务
+type authServer struct {
+	blogpb.UnimplementedAuthServiceServer
+}
+
+// Register 用户注册
+func (authServer) Register(ctx context.Context, req *blogpb.RegisterRequest) (*blogpb.User, error) {
+	user, err := service.Register(ctx, service.RegisterInput{
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toUser(user), nil
+}
+
+// Login 用户登录
+// 启用了两步验证的用户返回挑战令牌，由 VerifyTwoFactor 完成登录
+func (authServer) Login(ctx context.Context, req *blogpb.LoginRequest) (*blogpb.LoginResponse, error) {
+	token, user, err := service.Login(ctx, req.GetName(), req.GetPassword())
+	var challenge *service.TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		return &blogpb.LoginResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     challenge.ChallengeToken,
+			ChallengeExpiresAt: timestamppb.New(challenge.ExpiresAt),
+		}, nil
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toLoginResponse(token, user), nil
+}
+
+// VerifyTwoFactor 两步验证登录
+func (authServer) VerifyTwoFactor(ctx context.Context, req *blogpb.VerifyTwoFactorRequest) (*blogpb.LoginResponse, error) {
+	token, user, err := service.VerifyTwoFactorLogin(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toLoginResponse(token, user), nil
+}
+
+// postServer 文章服务
+type postServer struct {
+	blogpb.UnimplementedPostServiceServer
+}
+
+// ListPosts 获取已发布的文章列表
+func (postServer) ListPosts(ctx context.Context, req *blogpb.ListPostsRequest) (*blogpb.ListPostsResponse, error) {
+	posts, pagination, err := service.ListPosts(ctx, int(req.GetPage()), int(req.GetPageSize()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &blogpb.ListPostsResponse{Pagination: toPagination(pagination)}
+	for i := range posts {
+		resp.Posts = append(resp.Posts, toPost(&posts[i]))
+	}
+	return resp, nil
+}
+
+// GetPost 获取文章详情
+func (postServer) GetPost(ctx context.Context, req *blogpb.GetPostRequest) (*blogpb.Post, error) {
+	post, err := service.GetPost(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPost(post), nil
+}
+
+// CreatePost 创建文章
+func (postServer) CreatePost(ctx context.Context, req *blogpb.CreatePostRequest) (*blogpb.Post, error) {
+	post, err := service.CreatePost(ctx, currentUser(ctx).ID, service.PostInput{
+		Title:   req.GetTitle(),
+		Content: req.GetContent(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPost(post), nil
+}
+
+// UpdatePost 更新文章
+func (postServer) UpdatePost(ctx context.Context, req *blogpb.UpdatePostRequest) (*blogpb.Post, error) {
+	version, err := expectedVersion(req.Version)
+	if err != nil {
+		return nil, err
+	}
+	post, err := service.UpdatePost(ctx, currentUser(ctx).ID, uint(req.GetId()), service.PostInput{
+		Title:   req.GetTitle(),
+		Content: req.GetContent(),
+	}, version)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPost(post), nil
+}
+
+// DeletePost 删除文章
+func (postServer) DeletePost(ctx context.Context, req *blogpb.DeletePostRequest) (*blogpb.DeletePostResponse, error) {
+	if err := service.DeletePost(ctx, currentUser(ctx).ID, uint(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &blogpb.DeletePostResponse{}, nil
+}
+
+// commentServer 评论服务
+type commentServer struct {
+	blogpb.UnimplementedCommentServiceServer
+}
+
+// ListComments 获取文章的已发布评论
+func (commentServer) ListComments(ctx context.Context, req *blogpb.ListCommentsRequest) (*blogpb.ListCommentsResponse, error) {
+	comments, err := service.ListComments(ctx, uint(req.GetPostId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &blogpb.ListCommentsResponse{}
+	for i := range comments {
+		resp.Comments = append(resp.Comments, toComment(&comments[i]))
+	}
+	return resp, nil
+}
+
+// CreateComment 创建评论
+func (commentServer) CreateComment(ctx context.Context, req *blogpb.CreateCommentRequest) (*blogpb.Comment, error) {
+	comment, err := service.CreateComment(ctx, currentUser(ctx).ID, uint(req.GetPostId()), req.GetContent())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toComment(comment), nil
+}
+
+// UpdateComment 编辑评论
+func (commentServer) UpdateComment(ctx context.Context, req *blogpb.UpdateCommentRequest) (*blogpb.Comment, error) {
+	version, err := expectedVersion(req.Version)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := service.UpdateComment(ctx, currentUser(ctx).ID, uint(req.GetId()), req.GetContent(), version)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toComment(comment), nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/cache"
+	"blog/config"
+	"blog/database"
+	"blog/models"
+	"blog/utils"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// emailRegex 邮箱格式验证正则表达式
+var emailRegex = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+
+var (
+	authUsersOnce sync.Once
+	authUsers     *cache.LRU[models.User] // 用户ID -> 认证所需的用户字段（角色、封禁和两步验证状态）
+	authUsersMu   sync.Mutex
+	authUsersGen  uint64 // 每次失效加一，查询期间发生过失效时不写入缓存，避免缓存失效前读到的旧状态
+)
+
+// authUserCache 返回认证用户缓存，与会话状态缓存使用相同的容量和有效期
+// 封禁、解封和开关两步验证时删除本进程中的缓存；命令行修改角色或多实例部署时其他进程最多经过 session.cache_ttl 后生效
+func authUserCache() *cache.LRU[models.User] {
+	authUsersOnce.Do(func() {
+		cfg := config.LoadConfig().Session
+		authUsers = cache.NewLRU[models.User](cfg.CacheMaxEntries, cfg.CacheTTL)
+	})
+	return authUsers
+}
+
+// InvalidateUser 删除用户的认证缓存，修改用户的角色、封禁状态或两步验证状态后调用
+func InvalidateUser(userId uint) {
+	authUsersMu.Lock()
+	defer authUsersMu.Unlock()
+	authUsersGen++
+	authUserCache().Delete(strconv.FormatUint(uint64(userId), 10))
+}
+
+// loadAuthUser 返回认证所需的用户字段，优先读取缓存
+func loadAuthUser(ctx context.Context, userId uint) (models.User, error) {
+	key := strconv.FormatUint(uint64(userId), 10)
+	if user, ok := authUserCache().Get(key); ok {
+		return user, nil
+	}
+	authUsersMu.Lock()
+	gen := authUsersGen
+	authUsersMu.Unlock()
+
+	var user models.User
+	err := database.DB.WithContext(ctx).Select("id", "name", "role", "banned_at", "totp_enabled_at").First(&user, userId).Error
+	if err != nil {
+		return user, err
+	}
+	authUsersMu.Lock()
+	defer authUsersMu.Unlock()
+	if gen == authUsersGen {
+		authUserCache().Set(key, user)
+	}
+	return user, nil
+}
+
+// RegisterInput 注册信息
+type RegisterInput struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+// validateRegisterInput 验证注册输入
+// 验证用户名、邮箱格式和密码长度
+func validateRegisterInput(input RegisterInput) string {
+	// 验证用户名
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return "用户名不能为空"
+	}
+	if len(name) < 3 {
+		return "用户名长度至少3个字符"
+	}
+	if len(name) > 20 {
+		return "用户名长度不能超过20个字符"
+	}
+
+	// 验证邮箱
+	email := strings.TrimSpace(input.Email)
+	if email == "" {
+		return "邮箱不能为空"
+	}
+	if !emailRegex.MatchString(email) {
+		return "邮箱格式不正确"
+	}
+
+	// 验证密码
+	if input.Password == "" {
+		return "密码不能为空"
+	}
+	if len(input.Password) < 6 {
+		return "密码长度至少6位"
+	}
+	if len(input.Password) > 100 {
+		return "密码长度不能超过100位"
+	}
+
+	return "" // 返回空字符串表示验证通过
+}
+
+// Register 用户注册
+// 验证输入、检查用户名和邮箱是否已存在、创建用户（密码加密由 User 模型的 BeforeCreate 钩子处理）
+func Register(ctx context.Context, input RegisterInput) (*models.User, error) {
+	db := database.DB.WithContext(ctx)
+	if errMsg := validateRegisterInput(input); errMsg != "" {
+		return nil, fail(utils.CodeBadRequest, errMsg)
+	}
+
+	var count int64
+	db.Model(&models.User{}).
+		Where("name = ? ", input.Name).Count(&count)
+	if count > 0 {
+		return nil, fail(utils.CodeConflict, utils.MsgUsernameExists)
+	}
+	db.Model(&models.User{}).
+		Where("email = ? ", input.Email).Count(&count)
+	if count > 0 {
+		return nil, fail(utils.CodeConflict, utils.MsgEmailExists)
+	}
+
+	user := &models.User{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: input.Password,
+		Role:     models.RoleUser,
+	}
+	if err := db.Create(user).Error; err != nil {
+		return nil, internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionRegister,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+		After:      map[string]interface{}{"name": user.Name, "email": user.Email},
+	})
+	return user, nil
+}
+
+// Login 用户登录
+// 验证用户名密码，被封禁的用户不能登录；成功时返回 JWT Token 和用户信息。
+// 启用了两步验证的用户返回 *TwoFactorRequiredError，其中包含提交验证码所需的挑战令牌
+func Login(ctx context.Context, name, password string) (string, *models.User, error) {
+	db := database.DB.WithContext(ctx)
+	var user models.User
+	if err := db.Model(&models.User{}).Where("name = ? ", name).First(&user).Error; err != nil {
+		audit.RecordContext(ctx, audit.Entry{ActorName: name, Action: audit.ActionLoginFailed, Detail: "unknown user"})
+		return "", nil, fail(utils.CodeUnauthorized, utils.MsgLoginFailed)
+	}
+	if !utils.CheckPassword(password, user.Password) {
+		recordLoginFailed(ctx, &user, "wrong password")
+		return "", nil, fail(utils.CodeUnauthorized, utils.MsgLoginFailed)
+	}
+	if user.BannedAt != nil {
+		recordLoginFailed(ctx, &user, "banned")
+		return "", nil, fail(utils.CodeForbidden, utils.MsgUserBanned)
+	}
+	// 启用了两步验证的用户需要再提交验证码，见 VerifyTwoFactorLogin
+	if user.TOTPEnabledAt != nil {
+		challenge, err := createChallenge(ctx, &user)
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, challenge
+	}
+
+	token, err := createSession(ctx, &user, SessionPassword)
+	if err != nil {
+		return "", nil, err
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+	})
+	return token, &user, nil
+}
+
+// recordLoginFailed 记录已知用户登录失败的审计日志
+func recordLoginFailed(ctx context.Context, user *models.User, reason string) {
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionLoginFailed,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+		Detail:     reason,
+	})
+}
+
+// Authenticate 校验 Authorization 头（Bearer Token）并返回当前用户
+// Token 可以是登录返回的 JWT（对应的登录会话必须未退出），也可以是个人访问令牌（blog_pat_ 开头），后者同时返回令牌，由调用方检查权限范围；
+// 用户不存在返回 401，被封禁返回 403（以主库为准，缓存在封禁后立即失效）；失败时记录审计日志
+func Authenticate(ctx context.Context, authorization string) (*models.User, *models.PersonalAccessToken, error) {
+	if authorization == "" {
+		return nil, nil, denyUnauthorized(ctx, "missing token")
+	}
+	// 验证Token格式
+	parts := strings.SplitN(authorization, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, nil, denyUnauthorized(ctx, "malformed authorization header")
+	}
+	// 验证Token有效性,解析Token获取用户ID
+	var userId uint
+	var token *models.PersonalAccessToken
+	if strings.HasPrefix(parts[1], models.TokenPrefix) {
+		var err error
+		if token, err = authenticateToken(ctx, parts[1]); err != nil {
+			return nil, nil, err
+		}
+		userId = token.UserID
+	} else {
+		claims, err := utils.ValidateToken(parts[1])
+		if err != nil {
+			return nil, nil, denyUnauthorized(ctx, "invalid token")
+		}
+		// 会话已退出（远程退出）的 Token 立即失效
+		if err := authenticateSession(ctx, claims); err != nil {
+			return nil, nil, err
+		}
+		userId = claims.UserID
+	}
+	// 角色、封禁和两步验证状态缓存在进程内，缓存命中时不查询数据库
+	user, err := loadAuthUser(ctx, userId)
+	if err != nil {
+		return nil, nil, denyUnauthorized(ctx, "unknown user")
+	}
+	if user.BannedAt != nil {
+		audit.RecordContext(ctx, audit.Entry{
+			ActorID:   user.ID,
+			ActorName: user.Name,
+			Action:    audit.ActionForbidden,
+			Detail:    "banned: " + audit.ClientFrom(ctx).Resource,
+		})
+		return nil, nil, fail(utils.CodeForbidden, utils.MsgUserBanned)
+	}
+	return &user, token, nil
+}
+
+// DenyScope 个人访问令牌缺少接口所需的权限范围时记录审计日志并返回 403 错误
+func DenyScope(ctx context.Context, user *models.User, token *models.PersonalAccessToken) error {
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionForbidden,
+		TargetType: audit.TargetToken,
+		TargetID:   token.ID,
+		Detail:     "token scope: " + audit.ClientFrom(ctx).Resource,
+	})
+	return fail(utils.CodeForbidden, "令牌没有访问该接口的权限")
+}
+
+// denyUnauthorized 记录认证失败的审计日志并返回 401 错误
+func denyUnauthorized(ctx context.Context, reason string) error {
+	audit.RecordContext(ctx, audit.Entry{
+		Action: audit.ActionUnauthorized,
+		Detail: reason + ": " + audit.ClientFrom(ctx).Resource,
+	})
+	return fail(utils.CodeUnauthorized, utils.MsgUnauthorized)
+}
+
+// denyNotOwner 非作者操作资源时记录审计日志并返回 403 错误
+func denyNotOwner(ctx context.Context, userId uint, targetType string, targetID uint) error {
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionForbidden,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Detail:     "not owner: " + audit.ClientFrom(ctx).Resource,
+	})
+	return fail(utils.CodeForbidden, utils.MsgNoPermission)
+}
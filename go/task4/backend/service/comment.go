@@ -0,0 +1,147 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/database"
+	"blog/filter"
+	"blog/models"
+	"blog/utils"
+	"blog/webhook"
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CreateComment 对已发布的文章发表评论
+// 被垃圾内容过滤判定为可疑的评论进入待审核状态，审核通过时才触发 comment.created 事件
+func CreateComment(ctx context.Context, userId, postId uint, content string) (*models.Comment, error) {
+	db := database.DB.WithContext(ctx)
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fail(utils.CodeBadRequest, "评论内容不能为空")
+	}
+	var count int64
+	db.Model(&models.Post{}).Where("id = ? AND status = ?", postId, models.StatusPublished).Count(&count)
+	if count == 0 {
+		return nil, fail(utils.CodeNotFound, utils.MsgPostNotFound)
+	}
+
+	comment := &models.Comment{
+		Content: content,
+		UserID:  userId,
+		PostID:  postId,
+		Status:  models.StatusPublished,
+	}
+	verdict := filter.Check(ctx, filter.Content{
+		Type:   filter.TypeComment,
+		UserID: userId,
+		Body:   comment.Content,
+	})
+	if verdict.Suspicious {
+		comment.Status = models.StatusPending
+		comment.FlagReason = verdict.Reason()
+	}
+	if err := db.Create(comment).Error; err != nil {
+		return nil, internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionCommentCreate,
+		TargetType: audit.TargetComment,
+		TargetID:   comment.ID,
+		After:      CommentSnapshot(*comment),
+		Detail:     comment.FlagReason,
+	})
+	if comment.Status == models.StatusPublished {
+		webhook.Emit(ctx, webhook.EventCommentCreated, webhook.Comment(comment))
+	}
+	return comment, nil
+}
+
+// ListComments 获取已发布文章的已发布评论（关联作者信息），按创建时间倒序
+func ListComments(ctx context.Context, postId uint) ([]models.Comment, error) {
+	db := database.ReadDB(ctx)
+	var post models.Post
+	err := db.Where("id = ? AND status = ?", postId, models.StatusPublished).First(&post).Error
+	if err != nil && database.HasReplicas() {
+		// 副本可能尚未同步刚创建的文章，回退到主库确认
+		db = database.DB.WithContext(ctx)
+		err = db.Where("id = ? AND status = ?", postId, models.StatusPublished).First(&post).Error
+	}
+	if err != nil {
+		return nil, fail(utils.CodeNotFound, utils.MsgNotFound)
+	}
+	var comments []models.Comment
+	err = db.Where("post_id = ? AND status = ?", postId, models.StatusPublished).
+		Preload("User").
+		Order("created_at DESC, id DESC").
+		Find(&comments).Error
+	if err != nil {
+		return nil, internal(err)
+	}
+	return comments, nil
+}
+
+// UpdateComment 编辑评论
+// 只有作者才能编辑；version 为客户端期望的版本号，为 nil 时不校验版本（If-Match: *）
+// 返回更新后的评论，版本号不一致时返回 *VersionConflictError
+func UpdateComment(ctx context.Context, userId, id uint, content string, version *uint) (*models.Comment, error) {
+	db := database.DB.WithContext(ctx)
+	var comment models.Comment
+	if err := db.Where("id = ?", id).First(&comment).Error; err != nil {
+		return nil, fail(utils.CodeNotFound, utils.MsgCommentNotFound)
+	}
+	if comment.UserID != userId {
+		return nil, denyNotOwner(ctx, userId, audit.TargetComment, comment.ID)
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fail(utils.CodeBadRequest, "评论内容不能为空")
+	}
+
+	// 校验版本号（乐观锁）
+	expected := comment.Version
+	if version != nil {
+		expected = *version
+	}
+	if expected != comment.Version {
+		return nil, &VersionConflictError{Current: comment.Version}
+	}
+	// 只有版本号未变化时才会更新成功
+	result := db.Model(&models.Comment{}).
+		Where("id = ? AND version = ?", comment.ID, expected).
+		Updates(map[string]interface{}{
+			"content": content,
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return nil, internal(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// 校验之后被其他请求抢先更新
+		db.Model(&models.Comment{}).Select("version").Where("id = ?", comment.ID).Scan(&comment.Version)
+		return nil, &VersionConflictError{Current: comment.Version}
+	}
+	before := CommentSnapshot(comment)
+	comment.Content, comment.Version = content, expected+1
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionCommentUpdate,
+		TargetType: audit.TargetComment,
+		TargetID:   comment.ID,
+		Before:     before,
+		After:      CommentSnapshot(comment),
+	})
+	return &comment, nil
+}
+
+// CommentSnapshot 评论的审计快照
+func CommentSnapshot(comment models.Comment) map[string]interface{} {
+	return map[string]interface{}{
+		"post_id": comment.PostID,
+		"content": comment.Content,
+		"version": comment.Version,
+		"status":  comment.Status,
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"blog/utils"
+	"errors"
+	"log"
+)
+
+// Error 业务错误
+// Code 与 HTTP 状态码一致（utils.Code*），REST 接口直接使用，gRPC 接口据此转换为对应的状态码
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// fail 创建业务错误
+func fail(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// internal 记录底层错误并返回不暴露细节的 500 错误
+func internal(err error) *Error {
+	log.Println("Blog service error: ", err)
+	return fail(utils.CodeInternalError, utils.MsgInternalError)
+}
+
+// VersionConflictError 乐观锁版本冲突
+// REST 接口根据版本号的来源返回 409 或 412，gRPC 接口返回 ABORTED
+type VersionConflictError struct {
+	Current uint // 当前版本号
+}
+
+func (e *VersionConflictError) Error() string {
+	return utils.MsgVersionConflict
+}
+
+// Status 返回错误对应的状态码和错误信息
+// 非业务错误一律视为 500
+func Status(err error) (int, string) {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Code, serviceErr.Message
+	}
+	var conflict *VersionConflictError
+	if errors.As(err, &conflict) {
+		return utils.CodeConflict, conflict.Error()
+	}
+	var challenge *TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		return utils.CodeUnauthorized, challenge.Error()
+	}
+	return utils.CodeInternalError, utils.MsgInternalError
+}
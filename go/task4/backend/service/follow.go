@@ -0,0 +1,215 @@
+package service
+
+import (
+	"blog/database"
+	"blog/models"
+	"blog/timeline"
+	"blog/utils"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxFollowing = 2000 // 每个用户最多关注的作者数，同时限制了读扩散时首页动态查询的规模
+
+// Profile 用户的公开资料
+type Profile struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"created_at"`
+	FollowersCount int64     `json:"followers_count"` // 粉丝数
+	FollowingCount int64     `json:"following_count"` // 关注数
+}
+
+// FollowUser 粉丝列表和关注列表中的用户
+type FollowUser struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	FollowedAt time.Time `json:"followed_at"` // 关注时间
+}
+
+// GetProfile 获取用户的公开资料（不包含邮箱等私人信息）
+func GetProfile(ctx context.Context, id uint) (*Profile, error) {
+	return loadProfile(database.ReadDB(ctx), id)
+}
+
+// Follow 关注作者，已关注时直接返回；返回作者的公开资料（包含最新的粉丝数）
+func Follow(ctx context.Context, userId, authorId uint) (*Profile, error) {
+	db := database.DB.WithContext(ctx)
+	if userId == authorId {
+		return nil, fail(utils.CodeBadRequest, "不能关注自己")
+	}
+	if _, err := findUser(db, authorId); err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := db.Model(&models.Follow{}).Where("follower_id = ?", userId).Count(&count).Error; err != nil {
+		return nil, internal(err)
+	}
+	if count >= maxFollowing {
+		return nil, fail(utils.CodeConflict, fmt.Sprintf("最多只能关注%d个用户", maxFollowing))
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		follow := models.Follow{FollowerID: userId, FolloweeID: authorId}
+		result := tx.Where(follow).FirstOrCreate(&follow)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil // 已关注
+		}
+		return timeline.Current().Followed(tx, userId, authorId)
+	})
+	if err != nil {
+		// 同时发起的重复关注违反唯一索引，另一个请求已完成关注
+		if db.Where("follower_id = ? AND followee_id = ?", userId, authorId).First(&models.Follow{}).Error == nil {
+			return loadProfile(db, authorId)
+		}
+		return nil, internal(err)
+	}
+	return loadProfile(db, authorId)
+}
+
+// Unfollow 取消关注，未关注时直接返回；返回作者的公开资料（包含最新的粉丝数）
+func Unfollow(ctx context.Context, userId, authorId uint) (*Profile, error) {
+	db := database.DB.WithContext(ctx)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND followee_id = ?", userId, authorId).Delete(&models.Follow{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil // 未关注
+		}
+		return timeline.Current().Unfollowed(tx, userId, authorId)
+	})
+	if err != nil {
+		return nil, internal(err)
+	}
+	return loadProfile(db, authorId)
+}
+
+// ListFollowers 获取用户的粉丝，按关注时间倒序分页
+func ListFollowers(ctx context.Context, userId uint, page, pageSize int) ([]FollowUser, Pagination, error) {
+	return listFollows(ctx, userId, "followee_id", "follower_id", page, pageSize)
+}
+
+// ListFollowing 获取用户关注的作者，按关注时间倒序分页
+func ListFollowing(ctx context.Context, userId uint, page, pageSize int) ([]FollowUser, Pagination, error) {
+	return listFollows(ctx, userId, "follower_id", "followee_id", page, pageSize)
+}
+
+// listFollows 按 column = userId 查询关注关系，返回 other 列对应的用户
+// pageSize 默认 20，最大 100
+func listFollows(ctx context.Context, userId uint, column, other string, page, pageSize int) ([]FollowUser, Pagination, error) {
+	db := database.ReadDB(ctx)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20 // 默认每页20条
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 最大每页100条
+	}
+	pagination := Pagination{Page: page, PageSize: pageSize}
+	if _, err := findUser(db, userId); err != nil {
+		return nil, pagination, err
+	}
+
+	query := db.Table("zen_follow").
+		Joins("JOIN zen_user ON zen_user.id = zen_follow."+other+" AND zen_user.deleted_at IS NULL").
+		Where("zen_follow."+column+" = ?", userId)
+	if err := query.Count(&pagination.Total).Error; err != nil {
+		return nil, pagination, internal(err)
+	}
+	users := []FollowUser{}
+	err := query.Select("zen_user.id, zen_user.name, zen_follow.created_at AS followed_at").
+		Order("zen_follow.id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&users).Error
+	if err != nil {
+		return nil, pagination, internal(err)
+	}
+	return users, pagination, nil
+}
+
+// HomeFeed 获取首页动态：关注的作者发布的文章（关联作者信息），按发布顺序倒序
+// 使用游标分页：cursor 为上一页返回的 nextCursor，为空时从最新的文章开始；没有更多文章时 nextCursor 为空。
+// limit 默认 10，最大 50。具体查询方式由分发策略决定（timeline.strategy），返回结果与策略无关
+func HomeFeed(ctx context.Context, userId uint, cursor string, limit int) ([]models.Post, string, error) {
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	query := timeline.Current().Posts(database.ReadDB(ctx).Model(&models.Post{}), userId).
+		Where("zen_post.status = ?", models.StatusPublished)
+	if cursor != "" {
+		before, err := decodeFeedCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("zen_post.id < ?", before)
+	}
+
+	// 多查一条判断是否还有下一页
+	posts := []models.Post{}
+	err := query.Select("zen_post.*").Preload("User").
+		Order("zen_post.id DESC").
+		Limit(limit + 1).
+		Find(&posts).Error
+	if err != nil {
+		return nil, "", internal(err)
+	}
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		nextCursor = encodeFeedCursor(posts[limit-1].ID)
+	}
+	return posts, nextCursor, nil
+}
+
+// loadProfile 查询用户的公开资料和粉丝数、关注数
+func loadProfile(db *gorm.DB, id uint) (*Profile, error) {
+	var user models.User
+	if err := db.Select("id", "name", "created_at").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fail(utils.CodeNotFound, "用户不存在")
+		}
+		return nil, internal(err)
+	}
+	profile := &Profile{ID: user.ID, Name: user.Name, CreatedAt: user.CreatedAt}
+	if err := db.Model(&models.Follow{}).Where("followee_id = ?", id).Count(&profile.FollowersCount).Error; err != nil {
+		return nil, internal(err)
+	}
+	if err := db.Model(&models.Follow{}).Where("follower_id = ?", id).Count(&profile.FollowingCount).Error; err != nil {
+		return nil, internal(err)
+	}
+	return profile, nil
+}
+
+// encodeFeedCursor 把上一页最后一篇文章的ID编码为不透明的游标，客户端不应解析游标内容
+func encodeFeedCursor(postId uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(postId), 10)))
+}
+
+// decodeFeedCursor 解析游标，返回上一页最后一篇文章的ID
+func decodeFeedCursor(cursor string) (uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		var id uint64
+		if id, err = strconv.ParseUint(string(raw), 10, 64); err == nil && id > 0 {
+			return uint(id), nil
+		}
+	}
+	return 0, fail(utils.CodeBadRequest, "无效的游标")
+}
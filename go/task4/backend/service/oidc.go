@@ -0,0 +1,290 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/database"
+	"blog/models"
+	"blog/oidc"
+	"blog/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const maxOIDCNameAttempts = 5 // 自动创建用户时生成不重复用户名的尝试次数
+
+var (
+	oidcOnce     sync.Once
+	oidcProvider *oidc.Provider
+)
+
+// provider 返回 OIDC 提供方客户端（发现文档和 JWKS 在进程内缓存）
+func provider() *oidc.Provider {
+	oidcOnce.Do(func() {
+		oidcProvider = oidc.New(config.LoadConfig().OIDC)
+	})
+	return oidcProvider
+}
+
+// StartOIDCLogin 开始 OIDC 登录，返回提供方登录页地址和 state
+// state、nonce 和 PKCE 的 code_verifier 保存在数据库中，调用方还需把 state 写入 Cookie，回调时核对，防止登录 CSRF
+func StartOIDCLogin(ctx context.Context) (string, string, error) {
+	cfg := config.LoadConfig().OIDC
+	if !cfg.Enabled {
+		return "", "", fail(utils.CodeNotFound, "未启用 OIDC 登录")
+	}
+	db := database.DB.WithContext(ctx)
+	now := time.Now()
+	if err := db.Where("expires_at < ?", now).Delete(&models.OIDCLoginState{}).Error; err != nil {
+		return "", "", internal(err)
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return "", "", internal(err)
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+	authURL, err := provider().AuthURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Println("Blog oidc error: ", err)
+		return "", "", fail(utils.CodeBadGateway, "身份提供方暂时无法访问，请稍后重试")
+	}
+	err = db.Create(&models.OIDCLoginState{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(cfg.StateTTL),
+	}).Error
+	if err != nil {
+		return "", "", internal(err)
+	}
+	return authURL, state, nil
+}
+
+// FinishOIDCLogin 完成 OIDC 登录：核对 state，用授权码换取并校验 ID Token，找到或创建对应的用户
+// 成功时返回 JWT Token 和用户信息；启用了两步验证的用户与密码登录一样返回 *TwoFactorRequiredError
+func FinishOIDCLogin(ctx context.Context, state, code string) (string, *models.User, error) {
+	if !config.LoadConfig().OIDC.Enabled {
+		return "", nil, fail(utils.CodeNotFound, "未启用 OIDC 登录")
+	}
+	db := database.DB.WithContext(ctx)
+
+	// 1. 取出并删除登录状态，每个 state 只能使用一次
+	var loginState models.OIDCLoginState
+	if err := db.Where("state_hash = ?", hashToken(state)).First(&loginState).Error; err != nil {
+		return "", nil, fail(utils.CodeUnauthorized, "登录已失效，请重新登录")
+	}
+	result := db.Delete(&loginState)
+	if result.Error != nil {
+		return "", nil, internal(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", nil, fail(utils.CodeUnauthorized, "登录已失效，请重新登录")
+	}
+	if !time.Now().Before(loginState.ExpiresAt) {
+		return "", nil, fail(utils.CodeUnauthorized, "登录已过期，请重新登录")
+	}
+
+	// 2. 用授权码换取 ID Token 并校验
+	claims, err := provider().Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		log.Println("Blog oidc error: ", err)
+		audit.RecordContext(ctx, audit.Entry{Action: audit.ActionLoginFailed, Detail: "oidc: " + err.Error()})
+		return "", nil, fail(utils.CodeBadGateway, "身份提供方验证失败，请重新登录")
+	}
+
+	// 3. 找到或创建对应的用户
+	user, err := resolveOIDCUser(ctx, claims)
+	if err != nil {
+		return "", nil, err
+	}
+	if user.BannedAt != nil {
+		recordLoginFailed(ctx, user, "banned")
+		return "", nil, fail(utils.CodeForbidden, utils.MsgUserBanned)
+	}
+	if user.TOTPEnabledAt != nil {
+		challenge, err := createChallenge(ctx, user)
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, challenge
+	}
+
+	token, err := createSession(ctx, user, SessionOIDC)
+	if err != nil {
+		return "", nil, err
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+		Detail:     "oidc: " + claims.Issuer,
+	})
+	return token, user, nil
+}
+
+// resolveOIDCUser 返回提供方身份对应的用户
+// 已关联过的身份直接返回对应用户；否则按提供方验证过的邮箱关联已有用户，没有时自动创建用户（oidc.allow_signup）
+func resolveOIDCUser(ctx context.Context, claims *oidc.Claims) (*models.User, error) {
+	db := database.DB.WithContext(ctx)
+	var identity models.UserIdentity
+	err := db.Where("issuer = ? AND subject = ?", claims.Issuer, claims.Subject).First(&identity).Error
+	if err == nil {
+		return findUser(db, identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, internal(err)
+	}
+
+	// 未经提供方验证的邮箱可能属于他人，不能用于关联或创建用户
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified || !emailRegex.MatchString(email) {
+		audit.RecordContext(ctx, audit.Entry{ActorName: email, Action: audit.ActionLoginFailed, Detail: "oidc: email not verified"})
+		return nil, fail(utils.CodeForbidden, "身份提供方没有返回已验证的邮箱，无法登录")
+	}
+
+	var user models.User
+	err = db.Where("LOWER(email) = ?", strings.ToLower(email)).First(&user).Error
+	created := false
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !config.LoadConfig().OIDC.AllowSignup {
+			audit.RecordContext(ctx, audit.Entry{ActorName: email, Action: audit.ActionLoginFailed, Detail: "oidc: signup disabled"})
+			return nil, fail(utils.CodeForbidden, "该邮箱尚未注册，请先注册账号")
+		}
+		if err := createOIDCUser(ctx, claims, email, &user); err != nil {
+			return nil, err
+		}
+		created = true
+	default:
+		return nil, internal(err)
+	}
+
+	identity = models.UserIdentity{UserID: user.ID, Issuer: claims.Issuer, Subject: claims.Subject, Email: email}
+	if err := db.Create(&identity).Error; err != nil {
+		// 同一身份并发首次登录时，另一个请求已完成关联
+		var existing models.UserIdentity
+		if db.Where("issuer = ? AND subject = ?", claims.Issuer, claims.Subject).First(&existing).Error == nil {
+			return findUser(db, existing.UserID)
+		}
+		return nil, internal(err)
+	}
+	if !created {
+		audit.RecordContext(ctx, audit.Entry{
+			ActorID:    user.ID,
+			ActorName:  user.Name,
+			Action:     audit.ActionOIDCLink,
+			TargetType: audit.TargetUser,
+			TargetID:   user.ID,
+			After:      map[string]interface{}{"issuer": claims.Issuer, "subject": claims.Subject, "email": email},
+		})
+	}
+	return &user, nil
+}
+
+// createOIDCUser 为首次登录的提供方身份创建用户（JIT）
+// 用户名取自 preferred_username 或邮箱前缀，重复时追加随机数字；密码为随机值，用户只能通过 OIDC 登录
+func createOIDCUser(ctx context.Context, claims *oidc.Claims, email string, user *models.User) error {
+	db := database.DB.WithContext(ctx)
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return internal(err)
+	}
+	password := base64.RawURLEncoding.EncodeToString(buf)
+
+	base := oidcUsername(claims.PreferredUsername)
+	if base == "" {
+		local, _, _ := strings.Cut(email, "@")
+		base = oidcUsername(local)
+	}
+	if base == "" {
+		base = "user"
+	}
+	name := base
+	for i := 0; ; i++ {
+		var count int64
+		if err := db.Model(&models.User{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return internal(err)
+		}
+		if count == 0 {
+			break
+		}
+		if i == maxOIDCNameAttempts {
+			return fail(utils.CodeConflict, utils.MsgUsernameExists)
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return internal(err)
+		}
+		name = fmt.Sprintf("%s-%04d", truncateName(base, 15), n.Int64())
+	}
+
+	*user = models.User{
+		Name:     name,
+		Email:    email,
+		Password: password,
+		Role:     models.RoleUser,
+	}
+	if err := db.Create(user).Error; err != nil {
+		return internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionRegister,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+		Detail:     "oidc: " + claims.Issuer,
+		After:      map[string]interface{}{"name": user.Name, "email": user.Email},
+	})
+	return nil
+}
+
+// oidcUsername 把提供方的用户名整理为本站用户名：只保留字母、数字、下划线、连字符和点，最长 20 个字节
+// 整理后不足 3 个字符时返回空字符串
+func oidcUsername(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			b.WriteRune(r)
+		}
+	}
+	name := truncateName(b.String(), 20)
+	if len(name) < 3 {
+		return ""
+	}
+	return name
+}
+
+// truncateName 按字节截断字符串（用户名、User-Agent 等），不截断多字节字符
+func truncateName(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	end := 0
+	for i := range s {
+		if i > max {
+			break
+		}
+		end = i
+	}
+	return s[:end]
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/database"
+	"blog/filter"
+	"blog/models"
+	"blog/timeline"
+	"blog/utils"
+	"blog/webhook"
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Pagination 分页信息
+type Pagination struct {
+	Page     int   // 当前页码
+	PageSize int   // 每页条数
+	Total    int64 // 总条数
+}
+
+// TotalPage 总页数
+func (p Pagination) TotalPage() int {
+	return (int(p.Total) + p.PageSize - 1) / p.PageSize
+}
+
+// PostInput 创建或更新文章的内容
+type PostInput struct {
+	Title         string
+	Content       string
+	AttachmentIDs []uint // 关联的附件ID，为 nil 时不修改附件，为空切片时解除所有附件
+}
+
+// validatePostInput 去除首尾空白并验证标题和内容长度
+func validatePostInput(input *PostInput) error {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Content = strings.TrimSpace(input.Content)
+
+	if input.Title == "" {
+		return fail(utils.CodeBadRequest, "标题不能为空")
+	}
+	if len(input.Title) < 2 {
+		return fail(utils.CodeBadRequest, "标题长度至少2个字符")
+	}
+	if len(input.Title) > 100 {
+		return fail(utils.CodeBadRequest, "标题长度不能超过100个字符")
+	}
+
+	if input.Content == "" {
+		return fail(utils.CodeBadRequest, "内容不能为空")
+	}
+	if len(input.Content) < 10 {
+		return fail(utils.CodeBadRequest, "内容长度至少10个字符")
+	}
+	if len(input.Content) > 10000 {
+		return fail(utils.CodeBadRequest, "内容长度不能超过10000个字符")
+	}
+	return nil
+}
+
+// CreatePost 创建文章
+// 被垃圾内容过滤判定为可疑的文章进入待审核状态，审核通过时才触发 post.created 事件
+func CreatePost(ctx context.Context, userId uint, input PostInput) (*models.Post, error) {
+	db := database.DB.WithContext(ctx)
+	if err := validatePostInput(&input); err != nil {
+		return nil, err
+	}
+	post := &models.Post{
+		UserID:  userId,
+		Title:   input.Title,
+		Content: input.Content,
+		Status:  models.StatusPublished,
+	}
+	verdict := filter.Check(ctx, filter.Content{
+		Type:   filter.TypePost,
+		UserID: userId,
+		Title:  post.Title,
+		Body:   post.Content,
+	})
+	if verdict.Suspicious {
+		post.Status = models.StatusPending
+		post.FlagReason = verdict.Reason()
+	}
+	err := database.Transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return internal(err)
+		}
+		// 写扩散时写入粉丝的首页动态收件箱
+		if err := timeline.Current().Published(tx, post); err != nil {
+			return internal(err)
+		}
+		if input.AttachmentIDs != nil {
+			return attachToPost(tx, userId, post.ID, input.AttachmentIDs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionPostCreate,
+		TargetType: audit.TargetPost,
+		TargetID:   post.ID,
+		After:      PostSnapshot(*post),
+		Detail:     post.FlagReason,
+	})
+	if post.Status == models.StatusPublished {
+		webhook.Emit(ctx, webhook.EventPostCreated, webhook.Post(post))
+	}
+	return post, nil
+}
+
+// ListPosts 获取已发布的文章列表（关联作者信息），按创建时间倒序分页
+// pageSize 默认 10，最大 50
+func ListPosts(ctx context.Context, page, pageSize int) ([]models.Post, Pagination, error) {
+	db := database.ReadDB(ctx)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10 // 默认每页10条
+	}
+	if pageSize > 50 {
+		pageSize = 50 // 最大每页50条
+	}
+	pagination := Pagination{Page: page, PageSize: pageSize}
+
+	// 只返回已发布的文章，被隐藏的文章不公开
+	db = db.Where("status = ?", models.StatusPublished)
+	db.Model(&models.Post{}).Count(&pagination.Total)
+	var posts []models.Post
+	err := db.Preload("User").
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&posts).Error
+	if err != nil {
+		return nil, pagination, internal(err)
+	}
+	return posts, pagination, nil
+}
+
+// GetPost 获取已发布的文章详情（关联作者信息和附件）
+func GetPost(ctx context.Context, id uint) (*models.Post, error) {
+	db := database.ReadDB(ctx)
+	var count int64
+	db.Model(&models.Post{}).Where("id = ? AND status = ?", id, models.StatusPublished).Count(&count)
+	if count == 0 && database.HasReplicas() {
+		// 副本可能尚未同步刚创建的文章，回退到主库确认
+		db = database.DB.WithContext(ctx)
+		db.Model(&models.Post{}).Where("id = ? AND status = ?", id, models.StatusPublished).Count(&count)
+	}
+	if count == 0 {
+		return nil, fail(utils.CodeNotFound, utils.MsgPostNotFound)
+	}
+	var post models.Post
+	err := db.Preload("User").
+		Preload("Attachments", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		Where("id = ? ", id).First(&post).Error
+	if err != nil {
+		return nil, fail(utils.CodeNotFound, utils.MsgPostNotFound)
+	}
+	return &post, nil
+}
+
+// UpdatePost 更新文章
+// 只有作者才能更新；version 为客户端期望的版本号，为 nil 时不校验版本（If-Match: *）
+// 返回更新后的文章，版本号不一致时返回 *VersionConflictError
+func UpdatePost(ctx context.Context, userId, id uint, input PostInput, version *uint) (*models.Post, error) {
+	db := database.DB.WithContext(ctx)
+	post, err := findOwnPost(ctx, db, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePostInput(&input); err != nil {
+		return nil, err
+	}
+
+	// 校验版本号（乐观锁）
+	expected := post.Version
+	if version != nil {
+		expected = *version
+	}
+	if expected != post.Version {
+		return nil, &VersionConflictError{Current: post.Version}
+	}
+	// 只有版本号未变化时才会更新成功，附件关联与内容在同一事务中更新
+	err = database.Transaction(db, func(tx *gorm.DB) error {
+		result := tx.Model(&models.Post{}).
+			Where("id = ? AND version = ?", post.ID, expected).
+			Updates(map[string]interface{}{
+				"title":   input.Title,
+				"content": input.Content,
+				"version": gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return internal(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// 校验之后被其他请求抢先更新
+			tx.Model(&models.Post{}).Select("version").Where("id = ?", post.ID).Scan(&post.Version)
+			return &VersionConflictError{Current: post.Version}
+		}
+		if input.AttachmentIDs != nil {
+			return attachToPost(tx, userId, post.ID, input.AttachmentIDs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	before := PostSnapshot(*post)
+	post.Title, post.Content, post.Version = input.Title, input.Content, expected+1
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionPostUpdate,
+		TargetType: audit.TargetPost,
+		TargetID:   post.ID,
+		Before:     before,
+		After:      PostSnapshot(*post),
+	})
+	if post.Status == models.StatusPublished {
+		webhook.Emit(ctx, webhook.EventPostUpdated, webhook.Post(post))
+	}
+	return post, nil
+}
+
+// DeletePost 删除文章
+// 只有作者才能删除，删除后的文章和评论进入回收站
+func DeletePost(ctx context.Context, userId, id uint) error {
+	db := database.DB.WithContext(ctx)
+	post, err := findOwnPost(ctx, db, userId, id)
+	if err != nil {
+		return err
+	}
+	if err := SoftDeletePost(db, post); err != nil {
+		return internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionPostDelete,
+		TargetType: audit.TargetPost,
+		TargetID:   post.ID,
+		Before:     PostSnapshot(*post),
+	})
+	if post.Status == models.StatusPublished {
+		webhook.Emit(ctx, webhook.EventPostDeleted, webhook.Post(post))
+	}
+	return nil
+}
+
+// findOwnPost 查询文章并验证当前用户是否为作者
+func findOwnPost(ctx context.Context, db *gorm.DB, userId, id uint) (*models.Post, error) {
+	var post models.Post
+	if err := db.Where("id = ? ", id).First(&post).Error; err != nil {
+		return nil, fail(utils.CodeNotFound, utils.MsgPostNotFound)
+	}
+	if post.UserID != userId {
+		return nil, denyNotOwner(ctx, userId, audit.TargetPost, post.ID)
+	}
+	return &post, nil
+}
+
+// SoftDeletePost 软删除文章及其评论
+// 评论使用与文章相同的删除时间，从回收站恢复时据此一并恢复
+func SoftDeletePost(db *gorm.DB, post *models.Post) error {
+	return database.Transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Delete(post).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Comment{}).
+			Where("post_id = ?", post.ID).
+			UpdateColumn("deleted_at", post.DeletedAt.Time).Error
+	})
+}
+
+// PostSnapshot 文章的审计快照
+func PostSnapshot(post models.Post) map[string]interface{} {
+	return map[string]interface{}{
+		"title":   post.Title,
+		"content": post.Content,
+		"version": post.Version,
+		"status":  post.Status,
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/cache"
+	"blog/config"
+	"blog/database"
+	"blog/models"
+	"blog/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 登录方式，记录在会话中
+const (
+	SessionPassword  = "password"
+	SessionTwoFactor = "2fa"
+	SessionOIDC      = "oidc"
+)
+
+// sessionState 缓存的会话状态，userID 为 0 表示会话不存在（已退出或已过期）
+type sessionState struct {
+	id         uint
+	userID     uint
+	lastSeenAt time.Time
+	expiresAt  time.Time
+}
+
+var (
+	sessionsOnce sync.Once
+	sessions     *cache.LRU[sessionState] // jti 的 SHA-256 -> 会话状态
+	sessionsMu   sync.Mutex
+	sessionsGen  uint64 // 每次退出会话加一，校验期间发生过退出时不写入缓存，避免把退出前读到的状态重新缓存
+)
+
+// sessionCache 返回会话状态缓存
+// 退出会话时删除本进程中的缓存；多实例部署时其他实例最多经过 session.cache_ttl 后失效
+func sessionCache() *cache.LRU[sessionState] {
+	sessionsOnce.Do(func() {
+		cfg := config.LoadConfig().Session
+		sessions = cache.NewLRU[sessionState](cfg.CacheMaxEntries, cfg.CacheTTL)
+	})
+	return sessions
+}
+
+// sessionGeneration 返回当前的会话缓存版本，读取会话状态之前调用
+func sessionGeneration() uint64 {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	return sessionsGen
+}
+
+// storeSession 写入会话状态缓存，gen 之后发生过退出会话时不写入
+func storeSession(key string, state sessionState, gen uint64) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	if gen == sessionsGen {
+		sessionCache().Set(key, state)
+	}
+}
+
+// revokeSession 删除会话的缓存状态，在删除会话记录之后调用
+func revokeSession(key string) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	sessionsGen++
+	sessionCache().Delete(key)
+}
+
+// ListSessions 返回用户未过期的登录会话，按最近使用时间倒序
+// authorization 为当前请求的 Authorization 头，用于标记发起请求的会话
+func ListSessions(ctx context.Context, userId uint, authorization string) ([]models.Session, error) {
+	var list []models.Session
+	err := database.DB.WithContext(ctx).Where("user_id = ? AND expires_at > ?", userId, time.Now()).
+		Order("last_seen_at DESC").Order("id DESC").Find(&list).Error
+	if err != nil {
+		return nil, internal(err)
+	}
+	current := ""
+	if claims, err := utils.ParseToken(strings.TrimPrefix(authorization, "Bearer ")); err == nil && claims.ID != "" {
+		current = hashToken(claims.ID)
+	}
+	for i := range list {
+		list[i].Current = list[i].TokenHash == current
+	}
+	return list, nil
+}
+
+// DeleteSession 退出登录会话，只能退出自己的会话；退出后使用该会话 Token 的请求返回 401
+func DeleteSession(ctx context.Context, userId, sessionId uint) error {
+	db := database.DB.WithContext(ctx)
+	var session models.Session
+	if err := db.Where("id = ? AND user_id = ?", sessionId, userId).First(&session).Error; err != nil {
+		return fail(utils.CodeNotFound, "会话不存在")
+	}
+	if err := db.Delete(&session).Error; err != nil {
+		return internal(err)
+	}
+	revokeSession(session.TokenHash)
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionSessionRevoke,
+		TargetType: audit.TargetSession,
+		TargetID:   session.ID,
+		Before:     map[string]interface{}{"method": session.Method, "device": session.Device, "ip": session.IP},
+	})
+	return nil
+}
+
+// createSession 为登录成功的用户创建会话并签发 JWT Token，同时清理该用户已过期的会话
+func createSession(ctx context.Context, user *models.User, method string) (string, error) {
+	db := database.DB.WithContext(ctx)
+	now := time.Now()
+	if err := db.Where("user_id = ? AND expires_at < ?", user.ID, now).Delete(&models.Session{}).Error; err != nil {
+		return "", internal(err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", internal(err)
+	}
+	sessionID := base64.RawURLEncoding.EncodeToString(buf)
+	client := audit.ClientFrom(ctx)
+	session := models.Session{
+		UserID:     user.ID,
+		TokenHash:  hashToken(sessionID),
+		Method:     method,
+		Device:     describeDevice(client.UserAgent),
+		UserAgent:  truncateName(client.UserAgent, 255),
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(config.LoadConfig().JWT.ExpireTime),
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return "", internal(err)
+	}
+	token, err := utils.GenerateToken(user.ID, sessionID)
+	if err != nil {
+		return "", internal(err)
+	}
+	return token, nil
+}
+
+// authenticateSession 校验 JWT Token 对应的会话是否仍然有效，并更新最近使用时间
+// 会话状态（包括不存在的会话）缓存在进程内，缓存命中时不查询数据库；
+// 校验期间会话被退出时不写入缓存，更新最近使用时间时发现会话已被删除同样视为已退出
+func authenticateSession(ctx context.Context, claims *utils.Claims) error {
+	if claims.ID == "" {
+		// 升级前签发的 Token 没有会话，需要重新登录
+		return denyUnauthorized(ctx, "token without session")
+	}
+	db := database.DB.WithContext(ctx)
+	key := hashToken(claims.ID)
+	gen := sessionGeneration()
+	state, ok := sessionCache().Get(key)
+	if !ok {
+		var session models.Session
+		err := db.Where("token_hash = ?", key).First(&session).Error
+		switch {
+		case err == nil:
+			state = sessionState{id: session.ID, userID: session.UserID, lastSeenAt: session.LastSeenAt, expiresAt: session.ExpiresAt}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			state = sessionState{}
+		default:
+			return internal(err)
+		}
+		storeSession(key, state, gen)
+	}
+
+	now := time.Now()
+	if state.userID == 0 || state.userID != claims.UserID || !now.Before(state.expiresAt) {
+		return denyUnauthorized(ctx, "revoked session")
+	}
+	if now.Sub(state.lastSeenAt) >= lastUsedResolution {
+		result := db.Model(&models.Session{}).Where("id = ?", state.id).
+			Updates(map[string]interface{}{"last_seen_at": now, "ip": audit.ClientFrom(ctx).IP})
+		if result.Error != nil {
+			return internal(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			storeSession(key, sessionState{}, gen)
+			return denyUnauthorized(ctx, "revoked session")
+		}
+		state.lastSeenAt = now
+		storeSession(key, state, gen)
+	}
+	return nil
+}
+
+// describeDevice 从 User-Agent 识别浏览器和操作系统，如 "Chrome on Windows"；无法识别时返回 User-Agent 的开头部分
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "未知设备"
+	}
+	// 顺序有意义：Edge 和 Opera 的 User-Agent 同时包含 Chrome，Chrome 的同时包含 Safari
+	browsers := [][2]string{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"}, {"Chrome/", "Chrome"},
+		{"Safari/", "Safari"}, {"curl/", "curl"}, {"grpc-", "gRPC"}, {"Go-http-client", "Go"},
+	}
+	systems := [][2]string{
+		{"Android", "Android"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"}, {"Windows", "Windows"},
+		{"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	}
+	browser, system := "", ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b[0]) {
+			browser = b[1]
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(userAgent, s[0]) {
+			system = s[1]
+			break
+		}
+	}
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return truncateName(userAgent, 100)
+	}
+}
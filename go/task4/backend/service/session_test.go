@@ -0,0 +1,21 @@
+package service
+
+import "testing"
+
+// TestStoreSessionAfterRevoke 退出会话之前读到的状态不会在退出之后重新写入缓存
+func TestStoreSessionAfterRevoke(t *testing.T) {
+	key := hashToken("revoked-session")
+	state := sessionState{id: 1, userID: 1}
+
+	gen := sessionGeneration()
+	revokeSession(key)
+	storeSession(key, state, gen)
+	if _, ok := sessionCache().Get(key); ok {
+		t.Fatal("stale session state was cached after revocation")
+	}
+
+	storeSession(key, state, sessionGeneration())
+	if cached, ok := sessionCache().Get(key); !ok || cached != state {
+		t.Fatalf("session state was not cached: %+v, %v", cached, ok)
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/database"
+	"blog/models"
+	"blog/utils"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	maxTokensPerUser   = 50          // 每个用户最多持有的个人访问令牌数
+	maxTokenLifetime   = 365         // 令牌最长有效天数
+	tokenPrefixLength  = 6           // 列表中显示的令牌随机部分长度
+	lastUsedResolution = time.Minute // 最近使用时间的更新间隔，避免每个请求都写库
+)
+
+// CreateTokenInput 创建个人访问令牌的参数
+type CreateTokenInput struct {
+	Name          string
+	Scopes        []string
+	ExpiresInDays int // 有效天数，0 表示永不过期
+}
+
+// CreateToken 创建个人访问令牌
+// 返回令牌明文和令牌信息；数据库中只保存令牌的 SHA-256，明文无法再次查看
+func CreateToken(ctx context.Context, userId uint, input CreateTokenInput) (string, *models.PersonalAccessToken, error) {
+	db := database.DB.WithContext(ctx)
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > 100 {
+		return "", nil, fail(utils.CodeBadRequest, "令牌名称长度必须在1到100个字符之间")
+	}
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	if input.ExpiresInDays < 0 || input.ExpiresInDays > maxTokenLifetime {
+		return "", nil, fail(utils.CodeBadRequest, fmt.Sprintf("有效天数必须在0到%d之间，0 表示永不过期", maxTokenLifetime))
+	}
+
+	var count int64
+	if err := db.Model(&models.PersonalAccessToken{}).Where("user_id = ?", userId).Count(&count).Error; err != nil {
+		return "", nil, internal(err)
+	}
+	if count >= maxTokensPerUser {
+		return "", nil, fail(utils.CodeConflict, fmt.Sprintf("最多只能创建%d个令牌，请先撤销不再使用的令牌", maxTokensPerUser))
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, internal(err)
+	}
+	secret := models.TokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	token := &models.PersonalAccessToken{
+		UserID:    userId,
+		Name:      name,
+		Prefix:    secret[:len(models.TokenPrefix)+tokenPrefixLength],
+		TokenHash: hashToken(secret),
+		Scopes:    scopes,
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := db.Create(token).Error; err != nil {
+		return "", nil, internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionTokenCreate,
+		TargetType: audit.TargetToken,
+		TargetID:   token.ID,
+		After:      map[string]interface{}{"name": token.Name, "scopes": token.Scopes, "expires_at": token.ExpiresAt},
+	})
+	return secret, token, nil
+}
+
+// ListTokens 返回用户的所有个人访问令牌，按创建时间倒序
+func ListTokens(ctx context.Context, userId uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := database.DB.WithContext(ctx).Where("user_id = ?", userId).Order("id DESC").Find(&tokens).Error
+	if err != nil {
+		return nil, internal(err)
+	}
+	return tokens, nil
+}
+
+// DeleteToken 撤销个人访问令牌，只能撤销自己的令牌，撤销后立即失效
+func DeleteToken(ctx context.Context, userId, tokenId uint) error {
+	db := database.DB.WithContext(ctx)
+	var token models.PersonalAccessToken
+	if err := db.Where("id = ? AND user_id = ?", tokenId, userId).First(&token).Error; err != nil {
+		return fail(utils.CodeNotFound, "令牌不存在")
+	}
+	if err := db.Delete(&token).Error; err != nil {
+		return internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionTokenDelete,
+		TargetType: audit.TargetToken,
+		TargetID:   token.ID,
+		Before:     map[string]interface{}{"name": token.Name, "scopes": token.Scopes},
+	})
+	return nil
+}
+
+// authenticateToken 校验个人访问令牌是否存在且未过期，并更新最近使用时间
+func authenticateToken(ctx context.Context, secret string) (*models.PersonalAccessToken, error) {
+	db := database.DB.WithContext(ctx)
+	var token models.PersonalAccessToken
+	if err := db.Where("token_hash = ?", hashToken(secret)).First(&token).Error; err != nil {
+		return nil, denyUnauthorized(ctx, "invalid access token")
+	}
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, denyUnauthorized(ctx, "expired access token")
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		ip := audit.ClientFrom(ctx).IP
+		err := db.Model(&token).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+	return &token, nil
+}
+
+// normalizeScopes 校验权限范围并去重，至少需要一个
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool, len(scopes))
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !validScope(scope) {
+			return nil, fail(utils.CodeBadRequest, fmt.Sprintf("不支持的权限范围 %q，可选：%s", scope, strings.Join(models.Scopes, "、")))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fail(utils.CodeBadRequest, "scopes 不能为空")
+	}
+	return result, nil
+}
+
+// validScope 判断权限范围是否存在
+func validScope(scope string) bool {
+	for _, s := range models.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// hashToken 返回令牌的 SHA-256（十六进制）
+// 令牌为 256 位随机数，无需加盐和慢哈希
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/database"
+	"blog/models"
+	"blog/totp"
+	"blog/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount    = 10                                 // 每次生成的恢复码个数
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567" // 恢复码字符集（Base32，不含易混淆的 0、1、8、9）
+	maxChallengeAttempts = 5                                  // 每次登录挑战允许提交的验证码次数
+)
+
+// TwoFactorRequiredError 密码验证通过，需要提交两步验证的验证码才能完成登录
+// REST 接口返回挑战令牌，客户端携带挑战令牌和验证码调用 /api/auth/login/2fa
+type TwoFactorRequiredError struct {
+	ChallengeToken string    // 挑战令牌
+	ExpiresAt      time.Time // 挑战令牌的过期时间
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "账号已启用两步验证，请提交验证码完成登录"
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"` // 未使用的恢复码个数
+	Required               bool       `json:"required"`                 // 当前角色是否必须启用两步验证
+}
+
+// TwoFactorRequired 判断角色是否必须启用两步验证（two_factor.required_roles）
+func TwoFactorRequired(role string) bool {
+	for _, required := range config.LoadConfig().TwoFactor.RequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTwoFactorStatus 返回用户的两步验证状态
+func GetTwoFactorStatus(ctx context.Context, userId uint) (*TwoFactorStatus, error) {
+	db := database.DB.WithContext(ctx)
+	user, err := findUser(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	status := &TwoFactorStatus{
+		Enabled:   user.TOTPEnabledAt != nil,
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  TwoFactorRequired(user.Role),
+	}
+	if status.Enabled {
+		err := db.Model(&models.RecoveryCode{}).
+			Where("user_id = ? AND used_at IS NULL", userId).
+			Count(&status.RecoveryCodesRemaining).Error
+		if err != nil {
+			return nil, internal(err)
+		}
+	}
+	return status, nil
+}
+
+// SetupTwoFactor 为用户生成新的 TOTP 密钥，返回密钥和 otpauth:// 地址
+// 密钥在 EnableTwoFactor 验证通过前不生效；重复调用会替换尚未验证的密钥
+func SetupTwoFactor(ctx context.Context, userId uint) (string, string, error) {
+	db := database.DB.WithContext(ctx)
+	user, err := findUser(db, userId)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TOTPEnabledAt != nil {
+		return "", "", fail(utils.CodeConflict, "已启用两步验证，如需更换请先关闭")
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", internal(err)
+	}
+	if err := db.Model(user).Update("totp_secret", secret).Error; err != nil {
+		return "", "", internal(err)
+	}
+	return secret, totp.URI(config.LoadConfig().TwoFactor.Issuer, user.Name, secret), nil
+}
+
+// EnableTwoFactor 校验身份验证器生成的验证码并启用两步验证，返回恢复码明文（只返回这一次）
+func EnableTwoFactor(ctx context.Context, userId uint, code string) ([]string, error) {
+	db := database.DB.WithContext(ctx)
+	user, err := findUser(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, fail(utils.CodeConflict, "已启用两步验证")
+	}
+	if user.TOTPSecret == "" {
+		return nil, fail(utils.CodeBadRequest, "请先生成两步验证密钥")
+	}
+	step, ok := totp.Validate(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, fail(utils.CodeBadRequest, "验证码错误")
+	}
+
+	var codes []string
+	err = database.Transaction(db, func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(user).Updates(map[string]interface{}{"totp_enabled_at": now, "totp_last_step": step}).Error
+		if err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, userId)
+		return err
+	})
+	if err != nil {
+		return nil, internal(err)
+	}
+	InvalidateUser(user.ID)
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionTwoFactorEnable,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+	})
+	return codes, nil
+}
+
+// DisableTwoFactor 关闭两步验证，需要密码以及验证码或恢复码
+func DisableTwoFactor(ctx context.Context, userId uint, password, code string) error {
+	db := database.DB.WithContext(ctx)
+	user, err := findUser(db, userId)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil {
+		return fail(utils.CodeBadRequest, "未启用两步验证")
+	}
+	if !utils.CheckPassword(password, user.Password) {
+		return fail(utils.CodeBadRequest, "密码错误")
+	}
+	if _, err := verifySecondFactor(ctx, db, user, code); err != nil {
+		return err
+	}
+	if err := ResetTwoFactor(db, user.ID); err != nil {
+		return internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionTwoFactorDisable,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+	})
+	return nil
+}
+
+// ResetTwoFactor 清除用户的两步验证密钥和恢复码
+// 供关闭两步验证和命令行重置（用户丢失身份验证器和恢复码时）使用
+func ResetTwoFactor(db *gorm.DB, userId uint) error {
+	err := database.Transaction(db, func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error
+	})
+	InvalidateUser(userId)
+	return err
+}
+
+// RegenerateRecoveryCodes 使旧的恢复码全部失效并生成新的恢复码，需要验证码或恢复码
+func RegenerateRecoveryCodes(ctx context.Context, userId uint, code string) ([]string, error) {
+	db := database.DB.WithContext(ctx)
+	user, err := findUser(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, fail(utils.CodeBadRequest, "未启用两步验证")
+	}
+	if _, err := verifySecondFactor(ctx, db, user, code); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err = database.Transaction(db, func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userId)
+		return err
+	})
+	if err != nil {
+		return nil, internal(err)
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionRecoveryCodes,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+	})
+	return codes, nil
+}
+
+// VerifyTwoFactorLogin 两步验证登录的第二步
+// 校验挑战令牌和验证码（或恢复码），成功时返回 JWT Token 和用户信息；每个挑战最多提交 5 次验证码
+func VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string) (string, *models.User, error) {
+	db := database.DB.WithContext(ctx)
+	var challenge models.TwoFactorChallenge
+	if err := db.Where("token_hash = ?", hashToken(challengeToken)).First(&challenge).Error; err != nil {
+		return "", nil, fail(utils.CodeUnauthorized, "验证已失效，请重新登录")
+	}
+	if !time.Now().Before(challenge.ExpiresAt) {
+		db.Delete(&challenge)
+		return "", nil, fail(utils.CodeUnauthorized, "验证已过期，请重新登录")
+	}
+	// 先占用一次提交机会，并发提交也不会超过次数限制
+	result := db.Model(&models.TwoFactorChallenge{}).
+		Where("id = ? AND attempts < ?", challenge.ID, maxChallengeAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return "", nil, internal(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		db.Delete(&challenge)
+		return "", nil, fail(utils.CodeUnauthorized, "验证码错误次数过多，请重新登录")
+	}
+
+	user, err := findUser(db, challenge.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+	if user.BannedAt != nil {
+		db.Delete(&challenge)
+		return "", nil, fail(utils.CodeForbidden, utils.MsgUserBanned)
+	}
+	method, err := verifySecondFactor(ctx, db, user, code)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := db.Delete(&challenge).Error; err != nil {
+		return "", nil, internal(err)
+	}
+
+	token, err := createSession(ctx, user, SessionTwoFactor)
+	if err != nil {
+		return "", nil, err
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+		Detail:     "2fa: " + method,
+	})
+	return token, user, nil
+}
+
+// createChallenge 密码验证通过后为启用了两步验证的用户创建登录挑战，同时清理过期的挑战
+func createChallenge(ctx context.Context, user *models.User) (*TwoFactorRequiredError, error) {
+	db := database.DB.WithContext(ctx)
+	now := time.Now()
+	if err := db.Where("expires_at < ?", now).Delete(&models.TwoFactorChallenge{}).Error; err != nil {
+		return nil, internal(err)
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, internal(err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	challenge := models.TwoFactorChallenge{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(config.LoadConfig().TwoFactor.ChallengeTTL),
+	}
+	if err := db.Create(&challenge).Error; err != nil {
+		return nil, internal(err)
+	}
+	return &TwoFactorRequiredError{ChallengeToken: token, ExpiresAt: challenge.ExpiresAt}, nil
+}
+
+// verifySecondFactor 校验验证码或恢复码，返回验证方式（totp 或 recovery_code）
+// 6 位数字按 TOTP 验证码处理，同一时间步的验证码只能使用一次；其他按恢复码处理，使用后失效
+func verifySecondFactor(ctx context.Context, db *gorm.DB, user *models.User, code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+			result := db.Model(&models.User{}).
+				Where("id = ? AND totp_last_step < ?", user.ID, step).
+				Update("totp_last_step", step)
+			if result.Error != nil {
+				return "", internal(result.Error)
+			}
+			if result.RowsAffected > 0 {
+				return "totp", nil
+			}
+		}
+	} else if normalized := normalizeRecoveryCode(code); normalized != "" {
+		result := db.Model(&models.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalized)).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return "", internal(result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return "recovery_code", nil
+		}
+	}
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    user.ID,
+		ActorName:  user.Name,
+		Action:     audit.ActionTwoFactorFailed,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+	})
+	return "", fail(utils.CodeUnauthorized, "验证码错误")
+}
+
+// replaceRecoveryCodes 删除用户的所有恢复码并生成新的恢复码，返回明文（格式 xxxxx-xxxxx）
+func replaceRecoveryCodes(tx *gorm.DB, userId uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.RecoveryCode, recoveryCodeCount)
+	buf := make([]byte, 10)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		for j, b := range buf {
+			buf[j] = recoveryCodeAlphabet[b%byte(len(recoveryCodeAlphabet))]
+		}
+		codes[i] = string(buf[:5]) + "-" + string(buf[5:])
+		records[i] = models.RecoveryCode{UserID: userId, CodeHash: hashToken(string(buf))}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode 去掉恢复码中的分隔符和空白并转为小写，格式不正确时返回空字符串
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != 10 {
+		return ""
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(recoveryCodeAlphabet, r) {
+			return ""
+		}
+	}
+	return code
+}
+
+// findUser 根据ID查询用户，不存在时返回 404
+func findUser(db *gorm.DB, userId uint) (*models.User, error) {
+	var user models.User
+	if err := db.First(&user, userId).Error; err != nil {
+		return nil, fail(utils.CodeNotFound, "用户不存在")
+	}
+	return &user, nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"blog/audit"
+	"blog/config"
+	"blog/database"
+	"blog/media"
+	"blog/models"
+	"blog/storage"
+	"blog/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// maxPostAttachments 单篇文章最多关联的附件数
+const maxPostAttachments = 20
+
+// Upload 上传附件
+// 根据文件内容校验类型、大小和图片尺寸，图片生成缩略图；文件按内容的 SHA-256 存储，相同内容只存储一份。
+// 上传后的附件不属于任何文章，创建或更新文章时通过 attachment_ids 关联
+func Upload(ctx context.Context, userId uint, filename string, data []byte) (*models.Attachment, error) {
+	cfg := config.LoadConfig().Upload
+	if len(data) == 0 {
+		return nil, fail(utils.CodeBadRequest, "文件不能为空")
+	}
+	if len(data) > cfg.MaxSize {
+		return nil, fail(utils.CodePayloadTooLarge, fmt.Sprintf("文件大小不能超过 %s", formatSize(cfg.MaxSize)))
+	}
+	info, err := media.Inspect(data)
+	if err != nil {
+		return nil, fail(utils.CodeBadRequest, "图片已损坏或格式不支持")
+	}
+	if info.Ext == "" || !allowedType(cfg.AllowedTypes, info.ContentType) {
+		return nil, fail(utils.CodeUnsupportedMediaType, utils.MsgUnsupportedMediaType)
+	}
+	if info.Image && (info.Width > cfg.MaxImageWidth || info.Height > cfg.MaxImageHeight) {
+		return nil, fail(utils.CodeBadRequest, fmt.Sprintf("图片尺寸不能超过 %dx%d", cfg.MaxImageWidth, cfg.MaxImageHeight))
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	attachment := &models.Attachment{
+		UserID:      userId,
+		Filename:    cleanFilename(filename),
+		ContentType: info.ContentType,
+		Size:        int64(len(data)),
+		Hash:        hash,
+		Key:         storage.Key(hash, info.Ext),
+		Width:       info.Width,
+		Height:      info.Height,
+	}
+	// 先生成缩略图，像素数据损坏的图片不会被存储
+	if info.Image {
+		thumbnail, contentType, ext, err := media.Thumbnail(data, cfg.ThumbnailSize)
+		if err != nil {
+			return nil, fail(utils.CodeBadRequest, "图片已损坏或格式不支持")
+		}
+		attachment.ThumbnailKey = storage.Key(hash, fmt.Sprintf("-thumb%d%s", cfg.ThumbnailSize, ext))
+		if err := putOnce(ctx, attachment.ThumbnailKey, thumbnail, contentType); err != nil {
+			return nil, internal(err)
+		}
+	}
+	if err := putOnce(ctx, attachment.Key, data, info.ContentType); err != nil {
+		return nil, internal(err)
+	}
+
+	if err := database.DB.WithContext(ctx).Create(attachment).Error; err != nil {
+		return nil, internal(err)
+	}
+	attachment.SetURLs()
+	audit.RecordContext(ctx, audit.Entry{
+		ActorID:    userId,
+		Action:     audit.ActionUpload,
+		TargetType: audit.TargetAttachment,
+		TargetID:   attachment.ID,
+		After: map[string]interface{}{
+			"filename":     attachment.Filename,
+			"content_type": attachment.ContentType,
+			"size":         attachment.Size,
+			"hash":         attachment.Hash,
+		},
+	})
+	return attachment, nil
+}
+
+// putOnce 对象不存在时才写入（内容寻址，相同 key 的内容一定相同）
+func putOnce(ctx context.Context, key string, data []byte, contentType string) error {
+	exists, err := storage.Default.Exists(ctx, key)
+	if err != nil || exists {
+		return err
+	}
+	return storage.Default.Put(ctx, key, data, contentType)
+}
+
+// attachToPost 把附件关联到文章，原先关联但不在 ids 中的附件解除关联
+// 附件必须由作者本人上传，且未关联其他文章
+func attachToPost(tx *gorm.DB, userId, postId uint, ids []uint) error {
+	ids = uniqueIDs(ids)
+	if len(ids) > maxPostAttachments {
+		return fail(utils.CodeBadRequest, fmt.Sprintf("每篇文章最多关联%d个附件", maxPostAttachments))
+	}
+	if len(ids) > 0 {
+		var count int64
+		err := tx.Model(&models.Attachment{}).
+			Where("id IN ? AND user_id = ? AND (post_id IS NULL OR post_id = ?)", ids, userId, postId).
+			Count(&count).Error
+		if err != nil {
+			return internal(err)
+		}
+		if int(count) != len(ids) {
+			return fail(utils.CodeBadRequest, utils.MsgAttachmentInvalid)
+		}
+	}
+
+	detach := tx.Model(&models.Attachment{}).Where("post_id = ?", postId)
+	if len(ids) > 0 {
+		detach = detach.Where("id NOT IN ?", ids)
+	}
+	if err := detach.Update("post_id", nil).Error; err != nil {
+		return internal(err)
+	}
+	if len(ids) > 0 {
+		if err := tx.Model(&models.Attachment{}).Where("id IN ?", ids).Update("post_id", postId).Error; err != nil {
+			return internal(err)
+		}
+	}
+	return nil
+}
+
+// uniqueIDs 去除重复的ID，保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// allowedType 判断 MIME 类型是否在允许范围内
+func allowedType(allowed []string, contentType string) bool {
+	for _, t := range allowed {
+		if strings.EqualFold(t, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFilename 只保留文件名部分并去除控制字符，最长 255 个字符
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == "/" {
+		name = ""
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
+
+// formatSize 以 MB 或 KB 为单位格式化字节数
+func formatSize(size int) string {
+	if size >= 1<<20 && size%(1<<20) == 0 {
+		return fmt.Sprintf("%d MB", size>>20)
+	}
+	if size >= 1<<10 {
+		return fmt.Sprintf("%d KB", size>>10)
+	}
+	return fmt.Sprintf("%d 字节", size)
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"blog/utils"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// pngHeader 只包含签名和 IHDR 的 PNG，用于声明任意尺寸
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 6
+	chunk := append([]byte("IHDR"), ihdr...)
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+// TestUploadRejects 大小、类型和图片尺寸不合法的文件在写入存储和数据库之前被拒绝（使用默认配置）
+func TestUploadRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		code int
+	}{
+		{"empty", nil, utils.CodeBadRequest},
+		{"too large", bytes.Repeat([]byte("a"), 10<<20+1), utils.CodePayloadTooLarge},
+		{"html", []byte("<!DOCTYPE html><script>alert(1)</script>"), utils.CodeUnsupportedMediaType},
+		{"zip not allowed by default", []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00"), utils.CodeUnsupportedMediaType},
+		{"executable", []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00"), utils.CodeUnsupportedMediaType},
+		{"corrupt image", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...), utils.CodeBadRequest},
+		{"image too wide", pngHeader(8193, 10), utils.CodeBadRequest},
+		{"image too tall", pngHeader(10, 8193), utils.CodeBadRequest},
+	}
+	for _, tt := range tests {
+		_, err := Upload(context.Background(), 1, "file", tt.data)
+		if code, message := Status(err); code != tt.code {
+			t.Errorf("%s: got %d %q, want %d", tt.name, code, message, tt.code)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"blog/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 存储错误
+var (
+	ErrNotFound   = errors.New("storage: object not found") // 对象不存在
+	ErrInvalidKey = errors.New("storage: invalid key")      // key 不合法
+)
+
+// Storage 对象存储接口
+// key 为 "/" 分隔的相对路径，由 Key 生成；同一个 key 的内容不会改变（内容寻址），因此写入是幂等的
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Open 读取对象，不存在时返回 ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Exists 判断对象是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+// Default 全局存储后端，由 Init 根据配置创建
+var Default Storage
+
+// Init 根据配置创建全局存储后端
+func Init(cfg *config.UploadConfig) error {
+	switch cfg.Storage {
+	case "s3":
+		Default = NewS3(cfg.S3)
+	default:
+		local, err := NewLocal(cfg.LocalDir)
+		if err != nil {
+			return err
+		}
+		Default = local
+	}
+	return nil
+}
+
+// Key 根据内容的 SHA-256 十六进制摘要生成对象 key，按摘要前两位分目录
+// suffix 为文件名后缀（含扩展名），如 ".png"、"-thumb.jpg"
+func Key(hash, suffix string) string {
+	return hash[:2] + "/" + hash + suffix
+}
+
+// validKey 校验 key，拒绝绝对路径、空段和 ".."，防止越出存储目录
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("%w %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package timeline
+
+import (
+	"blog/config"
+	"blog/models"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 分发策略名称，与 timeline.strategy 配置项一致
+const (
+	StrategyRead  = "read"  // 读扩散
+	StrategyWrite = "write" // 写扩散
+)
+
+// Strategy 首页动态的分发策略
+// 发布文章、关注和取消关注时在同一个事务中调用对应的方法；读取时用 Posts 把文章查询限定为用户首页动态中的文章，
+// 状态过滤、排序和分页由调用方完成，因此切换策略不影响接口的行为
+type Strategy interface {
+	// Name 策略名称
+	Name() string
+	// Published 作者创建文章后调用（包括待审核的文章，读取时按状态过滤）
+	Published(tx *gorm.DB, post *models.Post) error
+	// Followed followerID 关注 authorID 后调用
+	Followed(tx *gorm.DB, followerID, authorID uint) error
+	// Unfollowed followerID 取消关注 authorID 后调用
+	Unfollowed(tx *gorm.DB, followerID, authorID uint) error
+	// Posts 把文章查询限定为 userID 首页动态中的文章（关注的作者发布的文章）
+	Posts(db *gorm.DB, userID uint) *gorm.DB
+}
+
+var (
+	mu      sync.RWMutex
+	current Strategy = FanOutOnRead{}
+)
+
+// Init 根据配置选择分发策略
+func Init(cfg *config.TimelineConfig) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = New(cfg)
+}
+
+// New 返回配置对应的分发策略
+func New(cfg *config.TimelineConfig) Strategy {
+	if cfg.Strategy == StrategyWrite {
+		return FanOutOnWrite{Backfill: cfg.Backfill}
+	}
+	return FanOutOnRead{}
+}
+
+// Current 返回当前的分发策略
+func Current() Strategy {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// FanOutOnRead 读扩散：读取时查询关注的作者的文章
+// 发布和关注时没有额外开销，读取时的查询随关注数增长
+type FanOutOnRead struct{}
+
+func (FanOutOnRead) Name() string { return StrategyRead }
+
+func (FanOutOnRead) Published(tx *gorm.DB, post *models.Post) error { return nil }
+
+func (FanOutOnRead) Followed(tx *gorm.DB, followerID, authorID uint) error { return nil }
+
+func (FanOutOnRead) Unfollowed(tx *gorm.DB, followerID, authorID uint) error { return nil }
+
+func (FanOutOnRead) Posts(db *gorm.DB, userID uint) *gorm.DB {
+	followees := db.Session(&gorm.Session{NewDB: true}).Model(&models.Follow{}).
+		Select("followee_id").Where("follower_id = ?", userID)
+	return db.Where("zen_post.user_id IN (?)", followees)
+}
+
+// FanOutOnWrite 写扩散：发布文章时写入每个粉丝的收件箱（zen_timeline_item），读取时只查自己的收件箱
+// 读取开销与关注数无关；发布的开销随粉丝数增长，关注作者时写入该作者最近的 Backfill 篇文章
+type FanOutOnWrite struct {
+	Backfill int
+}
+
+func (FanOutOnWrite) Name() string { return StrategyWrite }
+
+func (FanOutOnWrite) Published(tx *gorm.DB, post *models.Post) error {
+	// 用一条 INSERT ... SELECT 写入所有粉丝的收件箱，不把粉丝列表读到内存中
+	return tx.Exec("INSERT INTO zen_timeline_item (user_id, post_id, author_id) "+
+		"SELECT follower_id, ?, ? FROM zen_follow WHERE followee_id = ?", post.ID, post.UserID, post.UserID).Error
+}
+
+func (s FanOutOnWrite) Followed(tx *gorm.DB, followerID, authorID uint) error {
+	if s.Backfill <= 0 {
+		return nil
+	}
+	var postIDs []uint
+	err := tx.Model(&models.Post{}).Where("user_id = ?", authorID).
+		Order("id DESC").Limit(s.Backfill).Pluck("id", &postIDs).Error
+	if err != nil || len(postIDs) == 0 {
+		return err
+	}
+	items := make([]models.TimelineItem, len(postIDs))
+	for i, postID := range postIDs {
+		items[i] = models.TimelineItem{UserID: followerID, PostID: postID, AuthorID: authorID}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&items).Error
+}
+
+func (FanOutOnWrite) Unfollowed(tx *gorm.DB, followerID, authorID uint) error {
+	return tx.Where("user_id = ? AND author_id = ?", followerID, authorID).Delete(&models.TimelineItem{}).Error
+}
+
+func (FanOutOnWrite) Posts(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Joins("JOIN zen_timeline_item ON zen_timeline_item.post_id = zen_post.id AND zen_timeline_item.user_id = ?", userID)
+}
+
+// Rebuild 清空并按关注关系重新生成所有收件箱，每个关注关系写入作者最近的 backfill 篇文章，返回处理的关注关系数
+// 从读扩散切换到写扩散前执行（也可用于修复收件箱）；在一个事务中完成，期间读取仍然看到旧的收件箱
+func Rebuild(db *gorm.DB, backfill int) (int, error) {
+	strategy := FanOutOnWrite{Backfill: backfill}
+	count := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.TimelineItem{}).Error; err != nil {
+			return err
+		}
+		var follows []models.Follow
+		return tx.Order("id").FindInBatches(&follows, 500, func(batch *gorm.DB, _ int) error {
+			for _, follow := range follows {
+				if err := strategy.Followed(tx, follow.FollowerID, follow.FolloweeID); err != nil {
+					return err
+				}
+			}
+			count += len(follows)
+			return nil
+		}).Error
+	})
+	return count, err
+}
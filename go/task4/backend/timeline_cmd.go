@@ -0,0 +1,35 @@
+package main
+
+import (
+	"blog/config"
+	"blog/database"
+	"blog/timeline"
+	"fmt"
+)
+
+// runTimelineCommand 处理 timeline 子命令
+// `timeline rebuild [flags]`：按关注关系重新生成写扩散的首页动态收件箱，从读扩散切换到写扩散前执行
+func runTimelineCommand(args []string) error {
+	if len(args) < 1 || args[0] != "rebuild" {
+		return fmt.Errorf("usage: blog timeline rebuild [flags]")
+	}
+	cfg, err := config.Init(args[1:])
+	if err != nil {
+		return err
+	}
+	if err := database.InitDB(&cfg.Database); err != nil {
+		return fmt.Errorf("database init: %w", err)
+	}
+	if err := database.InitTable(); err != nil {
+		return fmt.Errorf("database migrate: %w", err)
+	}
+	count, err := timeline.Rebuild(database.DB, cfg.Timeline.Backfill)
+	if err != nil {
+		return fmt.Errorf("timeline rebuild: %w", err)
+	}
+	fmt.Printf("timeline rebuilt: %d follows, up to %d posts each\n", count, cfg.Timeline.Backfill)
+	if cfg.Timeline.Strategy != timeline.StrategyWrite {
+		fmt.Println("note: timeline.strategy is not write, inboxes are not kept up to date until it is switched")
+	}
+	return nil
+}
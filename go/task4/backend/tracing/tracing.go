@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"blog/config"
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName 本服务内部创建 Span 时使用的 Tracer 名称
+const TracerName = "blog"
+
+// ShutdownFunc 关闭追踪器，刷新尚未导出的 Span
+type ShutdownFunc func(ctx context.Context) error
+
+// InitTracer 初始化 OpenTelemetry 追踪
+// 根据配置创建 OTLP 或 stdout 导出器，设置全局 TracerProvider 和 W3C Trace Context 传播器
+func InitTracer(cfg *config.TracingConfig) (ShutdownFunc, error) {
+	// 无论是否启用追踪，都设置 W3C 传播器，保证上游的 traceparent 可以透传
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	// 1. 创建导出器
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 描述服务本身的资源属性
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 创建 TracerProvider（按比例采样，并尊重上游的采样决定）
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// newExporter 根据配置创建 Span 导出器
+func newExporter(cfg *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", cfg.Exporter)
+	}
+}
+
+// Tracer 返回本服务使用的 Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
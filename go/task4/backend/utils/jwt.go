@@ -9,17 +9,18 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateToken 生成JWT Token
-// 根据用户ID生成JWT Token字符串
+// Claims JWT Token 中的声明
+// 登录会话的标识放在 jti（RegisteredClaims.ID）中，用于远程退出
 type Claims struct {
 	UserID uint `json:"user_id"`
 	jwt.RegisteredClaims
 }
 
-var cfg = config.LoadConfig()
-
-func GenerateToken(userID uint) (string, error) {
+// GenerateToken 生成JWT Token
+// 根据用户ID和登录会话标识生成JWT Token字符串
+func GenerateToken(userID uint, sessionID string) (string, error) {
 	// 实现JWT生成逻辑
+	cfg := config.LoadConfig()
 	// 1. 创建Claims（包含用户ID、过期时间等）
 	expirationTime := time.Now().Add(cfg.JWT.ExpireTime)
 	claims := Claims{
@@ -29,6 +30,7 @@ func GenerateToken(userID uint) (string, error) {
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "blog-service",
+			ID:        sessionID,
 		},
 	}
 	// 2. 使用密钥签名
@@ -43,9 +45,10 @@ func GenerateToken(userID uint) (string, error) {
 }
 
 // ParseToken 解析JWT Token
-// 解析JWT Token并返回用户ID
-func ParseToken(tokenString string) (uint, error) {
+// 解析JWT Token并返回其中的声明（用户ID、会话标识等）
+func ParseToken(tokenString string) (*Claims, error) {
 	// 实现JWT解析逻辑
+	cfg := config.LoadConfig()
 	// 1. 解析Token字符串
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -55,27 +58,27 @@ func ParseToken(tokenString string) (uint, error) {
 	})
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			return 0, fmt.Errorf("token expired")
+			return nil, fmt.Errorf("token expired")
 		}
-		return 0, errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	// 提取用户信息
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims.UserID, nil
+		return claims, nil
 	}
 
-	return 0, errors.New("invalid token claims")
+	return nil, errors.New("invalid token claims")
 }
 
 // ValidateToken 验证Token有效性
 // 检查Token是否有效（未过期、签名正确）
-func ValidateToken(tokenString string) (uint, error) {
+func ValidateToken(tokenString string) (*Claims, error) {
 	// 实现Token验证逻辑
 	// 调用 ParseToken 并检查错误
-	userID, err := ParseToken(tokenString)
+	claims, err := ParseToken(tokenString)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return userID, nil
+	return claims, nil
 }
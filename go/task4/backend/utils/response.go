@@ -15,6 +15,20 @@ const (
 	CodeNotFound      = http.StatusNotFound            // 404 - 资源不存在（文章、评论、用户不存在）
 	CodeConflict      = http.StatusConflict            // 409 - 资源冲突（用户名已存在、邮箱已存在）
 	CodeInternalError = http.StatusInternalServerError // 500 - 服务器内部错误（数据库错误、未知错误）
+
+	// 乐观锁相关状态码
+	CodePreconditionFailed   = http.StatusPreconditionFailed   // 412 - 前置条件失败（If-Match 与当前版本不一致）
+	CodePreconditionRequired = http.StatusPreconditionRequired // 428 - 缺少前置条件（更新时未提供 If-Match 或 version）
+
+	// 文件上传相关状态码
+	CodePayloadTooLarge      = http.StatusRequestEntityTooLarge // 413 - 上传的文件过大
+	CodeUnsupportedMediaType = http.StatusUnsupportedMediaType  // 415 - 不支持的文件类型
+
+	// 幂等键相关状态码
+	CodeUnprocessableEntity = http.StatusUnprocessableEntity // 422 - 幂等键已用于内容不同的请求
+
+	// 外部服务相关状态码
+	CodeBadGateway = http.StatusBadGateway // 502 - 外部服务（如 OIDC 身份提供方）无法访问或返回错误
 )
 
 // 业务错误消息常量（便于统一错误提示）
@@ -34,6 +48,20 @@ const (
 	MsgPostNotFound    = "文章不存在"    // CodeNotFound (404)
 	MsgCommentNotFound = "评论不存在"    // CodeNotFound (404)
 	MsgNoPermission    = "无权限操作此资源" // CodeForbidden (403)
+	MsgUserBanned      = "账号已被封禁"   // CodeForbidden (403)
+
+	// 乐观锁相关消息
+	MsgVersionConflict = "内容已被他人修改，请刷新后重试"                    // CodeConflict (409) / CodePreconditionFailed (412)
+	MsgVersionRequired = "缺少版本号，请提供 If-Match 请求头或 version 字段" // CodePreconditionRequired (428)
+
+	// 文件上传相关消息
+	MsgUnsupportedMediaType = "不支持的文件类型"       // CodeUnsupportedMediaType (415)
+	MsgAttachmentInvalid    = "附件不存在或已被其他文章使用" // CodeBadRequest (400)
+
+	// 幂等键相关消息
+	MsgIdempotencyKeyInvalid    = "Idempotency-Key 格式错误，长度须为1到255个可见字符" // CodeBadRequest (400)
+	MsgIdempotencyKeyInProgress = "相同 Idempotency-Key 的请求正在处理，请稍后重试"    // CodeConflict (409)
+	MsgIdempotencyKeyReused     = "Idempotency-Key 已用于不同的请求"            // CodeUnprocessableEntity (422)
 )
 
 // Response 统一响应结构体
@@ -58,3 +86,9 @@ func Error(c *gin.Context, code int, message string) {
 	c.JSON(code, Response{Code: code, Message: message})
 
 }
+
+// ErrorWithData 携带数据的错误响应
+// 用于需要返回额外信息的错误（如版本冲突时返回当前版本号）
+func ErrorWithData(c *gin.Context, code int, message string, data interface{}) {
+	c.JSON(code, Response{Code: code, Message: message, Data: data})
+}
@@ -0,0 +1,243 @@
+package web
+
+import (
+	"blog/config"
+	"blog/frontend"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// IndexFile 首页，也是客户端路由的回退页面
+const IndexFile = "index.html"
+
+// minCompressSize 小于该大小的文件不压缩
+const minCompressSize = 256
+
+// Asset 一个静态文件及其预压缩版本
+type Asset struct {
+	Name        string // 相对前端目录的路径，如 css/style.css
+	ContentType string // MIME 类型
+	Data        []byte // 原始内容
+	ETag        string // 原始内容的强 ETag
+	gzip        []byte // gzip 压缩后的内容，不值得压缩时为空
+	brotli      []byte // brotli 压缩后的内容，不值得压缩时为空
+}
+
+// Compressed 是否有预压缩版本（需要返回 Vary: Accept-Encoding）
+func (a *Asset) Compressed() bool {
+	return a.gzip != nil || a.brotli != nil
+}
+
+// Variant 根据 Accept-Encoding 选择返回的版本，返回内容、Content-Encoding（未压缩时为空）和对应的 ETag
+// 同一文件的不同编码使用不同的 ETag，避免缓存把压缩内容返回给不支持的客户端
+func (a *Asset) Variant(acceptEncoding string) ([]byte, string, string) {
+	switch negotiate(acceptEncoding, a.brotli != nil, a.gzip != nil) {
+	case "br":
+		return a.brotli, "br", strings.TrimSuffix(a.ETag, `"`) + `-br"`
+	case "gzip":
+		return a.gzip, "gzip", strings.TrimSuffix(a.ETag, `"`) + `-gz"`
+	default:
+		return a.Data, "", a.ETag
+	}
+}
+
+// Assets 前端静态资源
+// 嵌入模式下启动时加载全部文件并预压缩；开发模式下每次请求从磁盘读取，不压缩、不缓存
+type Assets struct {
+	fsys        fs.FS
+	dev         bool
+	assetMaxAge time.Duration
+	files       map[string]*Asset
+}
+
+// Default 全局前端资源，由 Init 根据配置创建
+var Default *Assets
+
+// Init 根据配置创建全局前端资源
+// 设置了 dir 时从磁盘读取（开发模式），否则使用编译时嵌入的资源
+func Init(cfg *config.FrontendConfig) error {
+	if cfg.Dir != "" {
+		fsys := os.DirFS(cfg.Dir)
+		if _, err := fs.Stat(fsys, IndexFile); err != nil {
+			return fmt.Errorf("frontend dir %s: %w", cfg.Dir, err)
+		}
+		log.Printf("Blog frontend: serving from disk %s", cfg.Dir)
+		Default = &Assets{fsys: fsys, dev: true}
+		return nil
+	}
+	assets, err := Load(frontend.FS, cfg.AssetMaxAge)
+	if err != nil {
+		return err
+	}
+	Default = assets
+	return nil
+}
+
+// Load 加载 fsys 中的全部文件并生成 gzip、brotli 预压缩版本
+func Load(fsys fs.FS, assetMaxAge time.Duration) (*Assets, error) {
+	files := make(map[string]*Asset)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		asset, err := newAsset(name, data, true)
+		if err != nil {
+			return fmt.Errorf("compress %s: %w", name, err)
+		}
+		files[name] = asset
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if files[IndexFile] == nil {
+		return nil, fmt.Errorf("frontend: %s not found", IndexFile)
+	}
+	return &Assets{fsys: fsys, assetMaxAge: assetMaxAge, files: files}, nil
+}
+
+// Open 读取文件，name 为相对前端目录的路径；文件不存在或为目录时返回 fs.ErrNotExist
+func (a *Assets) Open(name string) (*Asset, error) {
+	if !fs.ValidPath(name) {
+		return nil, fs.ErrNotExist
+	}
+	if !a.dev {
+		asset, ok := a.files[name]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return asset, nil
+	}
+	info, err := fs.Stat(a.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fs.ErrNotExist
+	}
+	data, err := fs.ReadFile(a.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return newAsset(name, data, false)
+}
+
+// CacheControl 返回文件的 Cache-Control
+// 文件名不带内容摘要，HTML 页面每次都重新校验（ETag 未变化时返回 304），其余资源缓存 asset_max_age；开发模式下始终重新校验
+func (a *Assets) CacheControl(name string) string {
+	if a.dev || a.assetMaxAge <= 0 || path.Ext(name) == ".html" {
+		return "no-cache"
+	}
+	return "public, max-age=" + strconv.Itoa(int(a.assetMaxAge.Seconds()))
+}
+
+// newAsset 创建静态文件，compress 为 true 时对文本类文件生成压缩版本（压缩后没有变小则不保留）
+func newAsset(name string, data []byte, compress bool) (*Asset, error) {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	sum := sha256.Sum256(data)
+	asset := &Asset{
+		Name:        name,
+		ContentType: contentType,
+		Data:        data,
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+	if !compress || len(data) < minCompressSize || !compressible(contentType) {
+		return asset, nil
+	}
+
+	var buf bytes.Buffer
+	gz, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() < len(data) {
+		asset.gzip = bytes.Clone(buf.Bytes())
+	}
+
+	buf.Reset()
+	br := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	if _, err := br.Write(data); err != nil {
+		return nil, err
+	}
+	if err := br.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() < len(data) {
+		asset.brotli = bytes.Clone(buf.Bytes())
+	}
+	return asset, nil
+}
+
+// compressible 判断 MIME 类型是否值得压缩（图片、字体等已压缩的格式除外）
+func compressible(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/javascript", mediaType == "application/json",
+		mediaType == "application/manifest+json", mediaType == "image/svg+xml", mediaType == "application/xml":
+		return true
+	}
+	return false
+}
+
+// negotiate 根据 Accept-Encoding 选择编码，返回 "br"、"gzip" 或空字符串（不压缩）
+// 按 q 值选择，q 值相同时优先 brotli；q=0 表示不接受，"*" 匹配未列出的编码
+func negotiate(acceptEncoding string, hasBrotli, hasGzip bool) string {
+	if acceptEncoding == "" || (!hasBrotli && !hasGzip) {
+		return ""
+	}
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		weights[coding] = q
+	}
+	weight := func(coding string) float64 {
+		if q, ok := weights[coding]; ok {
+			return q
+		}
+		return weights["*"]
+	}
+
+	best, bestQ := "", 0.0
+	if hasBrotli && weight("br") > bestQ {
+		best, bestQ = "br", weight("br")
+	}
+	if hasGzip && weight("gzip") > bestQ {
+		best = "gzip"
+	}
+	return best
+}
@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"blog/config"
+	"blog/database"
+	"blog/models"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+)
+
+// 事件类型
+const (
+	EventPostCreated    = "post.created"    // 创建文章
+	EventPostUpdated    = "post.updated"    // 更新文章
+	EventPostDeleted    = "post.deleted"    // 删除文章（作者删除或版主删除）
+	EventCommentCreated = "comment.created" // 发表评论
+)
+
+// Events 支持订阅的全部事件
+var Events = []string{EventPostCreated, EventPostUpdated, EventPostDeleted, EventCommentCreated}
+
+// 投递请求头
+const (
+	HeaderEvent     = "X-Blog-Event"         // 事件类型
+	HeaderDelivery  = "X-Blog-Delivery"      // 投递记录ID，重试时不变，可用于去重
+	HeaderTimestamp = "X-Blog-Timestamp"     // 签名时间（Unix 秒）
+	HeaderSignature = "X-Blog-Signature-256" // 签名，格式为 "sha256=<十六进制>"
+)
+
+// ValidEvent 判断事件类型是否支持订阅
+func ValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload 投递的请求体
+type Payload struct {
+	Event     string      `json:"event"`      // 事件类型
+	CreatedAt time.Time   `json:"created_at"` // 事件发生时间
+	Data      interface{} `json:"data"`       // 事件数据，如 {"post": {...}}
+}
+
+// PostData 文章事件中的文章信息
+type PostData struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Status    string    `json:"status"`
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentData 评论事件中的评论信息
+type CommentData struct {
+	ID        uint      `json:"id"`
+	PostID    uint      `json:"post_id"`
+	UserID    uint      `json:"user_id"`
+	Content   string    `json:"content"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Post 返回文章事件的数据
+func Post(post *models.Post) map[string]interface{} {
+	return map[string]interface{}{"post": PostData{
+		ID:        post.ID,
+		UserID:    post.UserID,
+		Title:     post.Title,
+		Content:   post.Content,
+		Status:    post.Status,
+		Version:   post.Version,
+		CreatedAt: post.CreatedAt,
+	}}
+}
+
+// Comment 返回评论事件的数据
+func Comment(comment *models.Comment) map[string]interface{} {
+	return map[string]interface{}{"comment": CommentData{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		UserID:    comment.UserID,
+		Content:   comment.Content,
+		Status:    comment.Status,
+		CreatedAt: comment.CreatedAt,
+	}}
+}
+
+// Emit 为订阅了事件的每个 Webhook 创建投递记录，并唤醒后台任务立即投递
+// 投递记录保存在数据库中，服务重启后继续投递；出错只打印日志，不影响触发事件的操作本身
+func Emit(ctx context.Context, event string, data interface{}) {
+	if !config.LoadConfig().Webhook.Enabled {
+		return
+	}
+	// 请求结束后 context 会被取消，投递记录仍需写入
+	db := database.DB.WithContext(context.WithoutCancel(ctx))
+	var hooks []models.Webhook
+	if err := db.Where("active = ?", true).Find(&hooks).Error; err != nil {
+		log.Println("Blog webhook error: ", err)
+		return
+	}
+	var deliveries []models.WebhookDelivery
+	var payload []byte
+	for _, hook := range hooks {
+		if !hook.Subscribes(event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(Payload{Event: event, CreatedAt: time.Now(), Data: data})
+			if err != nil {
+				log.Println("Blog webhook error: ", err)
+				return
+			}
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := db.Create(&deliveries).Error; err != nil {
+		log.Println("Blog webhook error: ", err)
+		return
+	}
+	notify()
+}
+
+// Sign 计算签名：HMAC-SHA256(secret, "<timestamp>.<body>")，返回 "sha256=<十六进制>"
+// 签名包含时间戳，接收方可以拒绝时间相差过大的请求以防止重放
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
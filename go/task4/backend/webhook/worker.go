@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"blog/config"
+	"blog/database"
+	"blog/models"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	batchSize       = 20   // 每次取出的待投递记录数
+	concurrency     = 4    // 同时进行的投递请求数
+	maxResponseSize = 1024 // 投递日志中保存的响应体长度
+	userAgent       = "Blog-Webhook/1.0"
+)
+
+// wakeup 有新的投递记录时唤醒后台任务
+var wakeup = make(chan struct{}, 1)
+
+// notify 唤醒后台任务，不阻塞
+func notify() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// StartWorker 启动后台投递任务
+// 每隔 PollInterval 或有新事件时投递到期的记录，ctx 取消时退出；未启用时不启动
+func StartWorker(ctx context.Context, cfg *config.WebhookConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	worker := &worker{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+	go func() {
+		ticker := time.NewTicker(cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			worker.run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-wakeup:
+			}
+		}
+	}()
+}
+
+// worker 投递任务
+type worker struct {
+	cfg    *config.WebhookConfig
+	client *http.Client
+}
+
+// run 投递所有到期的记录，直到没有到期的记录为止
+func (w *worker) run(ctx context.Context) {
+	db := database.DB.WithContext(ctx)
+	for ctx.Err() == nil {
+		var due []models.WebhookDelivery
+		err := db.Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+			Order("next_attempt_at ASC, id ASC").
+			Limit(batchSize).
+			Find(&due).Error
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Println("Blog webhook error: ", err)
+			}
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, concurrency)
+		claimed := 0
+		for i := range due {
+			if !w.claim(db, &due[i]) {
+				continue
+			}
+			claimed++
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(delivery *models.WebhookDelivery) {
+				defer func() { <-slots; wg.Done() }()
+				if err := w.deliver(ctx, delivery); err != nil && ctx.Err() == nil {
+					log.Println("Blog webhook error: ", err)
+				}
+			}(&due[i])
+		}
+		wg.Wait()
+		if claimed == 0 {
+			// 全部被其他实例领取，等待下一轮
+			return
+		}
+	}
+}
+
+// claim 领取一条投递记录：把下次投递时间推迟到本次请求超时之后
+// 多个实例同时运行时只有一个能领取成功；领取后进程退出的记录在推迟的时间到达后会被重新投递
+func (w *worker) claim(db *gorm.DB, delivery *models.WebhookDelivery) bool {
+	now := time.Now()
+	lease := now.Add(2 * w.cfg.Timeout)
+	result := db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, models.DeliveryPending, now).
+		Update("next_attempt_at", lease)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+	delivery.NextAttemptAt = lease
+	return true
+}
+
+// deliver 投递一次，记录请求结果并更新投递状态
+func (w *worker) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	db := database.DB.WithContext(ctx)
+	var hook models.Webhook
+	err := db.Where("id = ? AND active = ?", delivery.WebhookID, true).First(&hook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 订阅已删除或停用
+		return db.Model(delivery).Update("status", models.DeliveryFailed).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	attempt := w.send(ctx, &hook, delivery)
+	attempt.DeliveryID = delivery.ID
+	if err := db.Create(&attempt).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"attempts": delivery.Attempts + 1}
+	now := time.Now()
+	switch {
+	case attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+		updates["status"] = models.DeliverySucceeded
+		updates["delivered_at"] = now
+	case delivery.Attempts+1 >= w.cfg.MaxAttempts:
+		updates["status"] = models.DeliveryFailed
+	default:
+		updates["next_attempt_at"] = now.Add(Backoff(delivery.Attempts+1, w.cfg.InitialBackoff, w.cfg.MaxBackoff))
+	}
+	return db.Model(delivery).Updates(updates).Error
+}
+
+// send 发送签名后的请求，返回请求结果
+func (w *worker) send(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) models.WebhookAttempt {
+	var attempt models.WebhookAttempt
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = truncate(err.Error(), 500)
+		return attempt
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := w.client.Do(req)
+	attempt.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = truncate(err.Error(), 500)
+		return attempt
+	}
+	defer resp.Body.Close()
+	attempt.StatusCode = resp.StatusCode
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	attempt.Response = string(bytes.ToValidUTF8(response, nil))
+	// 读完剩余内容以便复用连接
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return attempt
+}
+
+// Backoff 返回第 attempts 次投递失败后的等待时间：initial × 2^(attempts-1)，不超过 max
+func Backoff(attempts int, initial, max time.Duration) time.Duration {
+	wait := initial
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return wait
+}
+
+// truncate 截断过长的字符串
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,10 @@
+// Package frontend 嵌入前端静态资源
+// 前端目录单独作为一个模块，后端通过 replace 引用，编译后的程序不再依赖启动目录下的 ../frontend
+package frontend
+
+import "embed"
+
+// FS 前端页面、样式和脚本
+//
+//go:embed index.html css js pages
+var FS embed.FS
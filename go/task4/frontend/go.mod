@@ -0,0 +1,3 @@
+module blog/frontend
+
+go 1.24
@@ -4,6 +4,7 @@
 
 let isEditMode = false;
 let editPostId = null;
+let editPostVersion = null;
 
 document.addEventListener('DOMContentLoaded', () => {
     // 检查登录状态
@@ -72,6 +73,7 @@ async function loadPostForEdit() {
         // 填充表单
         document.getElementById('postTitle').value = post.title || '';
         document.getElementById('postContent').value = post.content || '';
+        editPostVersion = post.version;
         
     } catch (error) {
         console.error('加载文章失败:', error);
@@ -112,7 +114,7 @@ async function handleFormSubmit(e) {
     try {
         if (isEditMode) {
             // 更新文章
-            await postAPI.update(editPostId, title, content);
+            await postAPI.update(editPostId, title, content, editPostVersion);
             alert('文章更新成功！');
             window.location.href = `/pages/post-detail.html?id=${editPostId}`;
         } else {
@@ -135,6 +137,13 @@ async function handleFormSubmit(e) {
                 return;
             }
             
+            // 被垃圾内容过滤判定为可疑的文章需要等待版主审核
+            if (response.data && response.data.status === 'pending') {
+                alert('文章已提交，审核通过后将公开显示');
+                window.location.href = '/index.html';
+                return;
+            }
+
             alert('文章发布成功！');
             window.location.href = `/pages/post-detail.html?id=${postId}`;
         }
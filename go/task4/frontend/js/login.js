@@ -2,16 +2,24 @@
 // 登录页面功能
 // ========================================
 
-document.addEventListener('DOMContentLoaded', () => {
+document.addEventListener('DOMContentLoaded', async () => {
     const loginForm = document.getElementById('loginForm');
     const errorMessage = document.getElementById('errorMessage');
     
+    // OIDC 登录回调：结果放在 URL 片段中（#token=... 或 #error=...）
+    if (await handleOIDCResult()) {
+        return;
+    }
+    
     // 如果已登录，重定向到首页
     if (TokenManager.isAuthenticated()) {
         window.location.href = '/';
         return;
     }
     
+    // 启用了 OIDC 登录时显示「使用 xxx 登录」按钮
+    showOIDCButton();
+    
     loginForm.addEventListener('submit', async (e) => {
         e.preventDefault();
         
@@ -33,45 +41,9 @@ document.addEventListener('DOMContentLoaded', () => {
             // 调用登录API
             const response = await authAPI.login(username, password);
             
-            // 保存token和用户信息
             // 后端返回格式：{code: 200, data: {token: "...", user: {...}}}
-            const responseData = response.data || response;
-            
-            // 保存token和用户信息
-            if (responseData.token) {
-                TokenManager.setToken(responseData.token);
-                console.log('Token已保存:', responseData.token.substring(0, 20) + '...');
-            } else {
-                console.error('登录响应中没有token:', responseData);
-            }
-            
-            if (responseData.user) {
-                UserManager.setUserInfo(responseData.user);
-                console.log('用户信息已保存:', responseData.user);
-            }
-            
-            // 验证保存是否成功
-            const savedToken = TokenManager.getToken();
-            if (!savedToken) {
-                console.error('Token保存失败！');
-                showError('登录状态保存失败，请重试');
-                return;
-            }
-            
-            // 登录成功，跳转到原页面或首页
-            // 使用 setTimeout 确保 localStorage 写入完成
-            setTimeout(() => {
-                const returnUrl = new URLSearchParams(window.location.search).get('return') || 
-                                  document.referrer || 
-                                  '/';
-                // 如果是文章详情页，返回文章详情页
-                if (returnUrl.includes('/pages/post-detail.html')) {
-                    window.location.href = returnUrl;
-                } else {
-                    window.location.href = '/';
-                }
-            }, 100);
-            
+            // 启用了两步验证时返回 {two_factor_required: true, challenge_token: "..."}
+            await completeLogin(response.data || response);
         } catch (error) {
             console.error('登录失败:', error);
             showError(error.message || '登录失败，请检查用户名和密码');
@@ -79,6 +51,101 @@ document.addEventListener('DOMContentLoaded', () => {
     });
 });
 
+// completeLogin 保存token和用户信息并跳转；需要两步验证时先提交验证码。返回是否登录成功
+async function completeLogin(responseData) {
+    if (responseData.two_factor_required) {
+        const code = window.prompt('请输入身份验证器中的 6 位验证码（或一个恢复码）');
+        if (!code) {
+            showError('已取消两步验证，请重新登录');
+            return false;
+        }
+        const response = await authAPI.loginTwoFactor(responseData.challenge_token, code.trim());
+        responseData = response.data || response;
+    }
+    
+    // 保存token和用户信息
+    if (responseData.token) {
+        TokenManager.setToken(responseData.token);
+        console.log('Token已保存:', responseData.token.substring(0, 20) + '...');
+    } else {
+        console.error('登录响应中没有token:', responseData);
+    }
+    
+    if (responseData.user) {
+        UserManager.setUserInfo(responseData.user);
+        console.log('用户信息已保存:', responseData.user);
+    }
+    
+    // 验证保存是否成功
+    const savedToken = TokenManager.getToken();
+    if (!savedToken) {
+        console.error('Token保存失败！');
+        showError('登录状态保存失败，请重试');
+        return false;
+    }
+    
+    // 登录成功，跳转到原页面或首页
+    // 使用 setTimeout 确保 localStorage 写入完成
+    setTimeout(() => {
+        const returnUrl = new URLSearchParams(window.location.search).get('return') ||
+                          document.referrer ||
+                          '/';
+        // 如果是文章详情页，返回文章详情页
+        if (returnUrl.includes('/pages/post-detail.html')) {
+            window.location.href = returnUrl;
+        } else {
+            window.location.href = '/';
+        }
+    }, 100);
+    return true;
+}
+
+// handleOIDCResult 处理 OIDC 登录回调跳转回来时 URL 片段中的结果，返回是否登录成功
+async function handleOIDCResult() {
+    const params = new URLSearchParams(window.location.hash.substring(1));
+    if (!params.has('token') && !params.has('error') && !params.has('two_factor_required')) {
+        return false;
+    }
+    // 立即清除 URL 片段，避免 Token 留在浏览器历史中
+    history.replaceState(null, '', window.location.pathname + window.location.search);
+    
+    if (params.has('error')) {
+        showError(params.get('error'));
+        return false;
+    }
+    try {
+        return await completeLogin({
+            token: params.get('token'),
+            user: params.has('user') ? JSON.parse(params.get('user')) : null,
+            two_factor_required: params.get('two_factor_required') === 'true',
+            challenge_token: params.get('challenge_token')
+        });
+    } catch (error) {
+        console.error('登录失败:', error);
+        showError(error.message || '登录失败，请重新登录');
+        return false;
+    }
+}
+
+// showOIDCButton 启用了 OIDC 登录时显示登录按钮
+async function showOIDCButton() {
+    const button = document.getElementById('oidcLoginButton');
+    if (!button) {
+        return;
+    }
+    try {
+        const response = await authAPI.getOIDC();
+        const data = response.data || response;
+        if (data.enabled) {
+            document.getElementById('oidcLoginName').textContent = `使用 ${data.name} 登录`;
+            button.href = `${API_BASE_URL}/auth/oidc/login`;
+            button.style.display = 'block';
+        }
+    } catch (error) {
+        console.error('获取 OIDC 配置失败:', error);
+    }
+}
+
 function showError(message) {
     const errorMessage = document.getElementById('errorMessage');
     if (errorMessage) {
@@ -86,4 +153,3 @@ function showError(message) {
         errorMessage.style.display = 'block';
     }
 }
-
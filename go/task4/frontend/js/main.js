@@ -112,6 +112,14 @@ const authAPI = {
         return api.post('/auth/login', { name: username, password });
     },
     
+    loginTwoFactor: (challengeToken, code) => {
+        return api.post('/auth/login/2fa', { challenge_token: challengeToken, code });
+    },
+    
+    getOIDC: () => {
+        return api.get('/auth/oidc');
+    },
+    
     logout: () => {
         TokenManager.removeToken();
         UserManager.removeUserInfo();
@@ -133,8 +141,9 @@ const postAPI = {
         return api.post('/posts', { title, content });
     },
     
-    update: (id, title, content) => {
-        return api.put(`/posts/${id}`, { title, content });
+    // version 为加载文章时的版本号，用于防止多个页面同时编辑时互相覆盖
+    update: (id, title, content, version) => {
+        return api.put(`/posts/${id}`, { title, content, version });
     },
     
     delete: (id) => {
@@ -150,6 +159,10 @@ const commentAPI = {
     
     create: (postId, content) => {
         return api.post('/comments', { post_id: postId, content });
+    },
+    
+    update: (id, content, version) => {
+        return api.put(`/comments/${id}`, { content, version });
     }
 };
 
@@ -544,7 +544,10 @@ async function handleCommentSubmit(e) {
     }
     
     try {
-        await commentAPI.create(currentPostId, content);
+        const response = await commentAPI.create(currentPostId, content);
+        if (response && response.data && response.data.status === 'pending') {
+            alert('评论已提交，审核通过后将公开显示');
+        }
         
         // 清空表单
         document.getElementById('commentContent').value = '';